@@ -0,0 +1,224 @@
+package crossrealm
+
+import (
+	_ "embed"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/types/typeutil"
+	"github.com/gfanton/gnopls/internal/analysisinternal"
+	"github.com/gfanton/gnopls/internal/cache/metadata"
+)
+
+//go:embed doc.go
+var doc string
+
+var Analyzer = &analysis.Analyzer{
+	Name:             "crossrealm",
+	Doc:              analysisinternal.MustExtractDoc(doc, "crossrealm"),
+	Run:              run,
+	RunDespiteErrors: true,
+	URL:              "https://pkg.go.dev/github.com/gfanton/gnopls/internal/analysis/crossrealm",
+}
+
+// callerChecks are the functions of package std that identify the
+// caller of a realm.
+var callerChecks = map[string]bool{
+	"PrevRealm":        true,
+	"GetOrigCaller":    true,
+	"AssertOriginCall": true,
+}
+
+// funcInfo summarizes the body of a function of the package.
+type funcInfo struct {
+	decl     *ast.FuncDecl
+	mutation ast.Node      // first mutation of package state, or nil
+	checks   bool          // whether it identifies its caller
+	callees  []*types.Func // functions of the package it calls
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	if !metadata.IsRealmPath(metadata.PackagePath(pass.Pkg.Path())) {
+		return nil, nil
+	}
+
+	funcs := make(map[*types.Func]*funcInfo)
+	var order []*types.Func
+	for _, f := range pass.Files {
+		for _, decl := range f.Decls {
+			fd, ok := decl.(*ast.FuncDecl)
+			if !ok || fd.Body == nil {
+				continue
+			}
+			fn, ok := pass.TypesInfo.Defs[fd.Name].(*types.Func)
+			if !ok {
+				continue
+			}
+			funcs[fn] = inspectFunc(pass, fd)
+			order = append(order, fn)
+		}
+	}
+
+	// Propagate the checks and mutations through the calls to
+	// functions of the package, until a fixed point is reached.
+	for changed := true; changed; {
+		changed = false
+		for _, fn := range order {
+			info := funcs[fn]
+			for _, callee := range info.callees {
+				ci, ok := funcs[callee]
+				if !ok {
+					continue
+				}
+				if ci.checks && !info.checks {
+					info.checks, changed = true, true
+				}
+				if ci.mutation != nil && info.mutation == nil {
+					info.mutation, changed = ci.mutation, true
+				}
+			}
+		}
+	}
+
+	for _, fn := range order {
+		info := funcs[fn]
+		if info.decl.Recv != nil || !fn.Exported() || info.mutation == nil || info.checks {
+			continue
+		}
+		pass.Report(analysis.Diagnostic{
+			Pos:     info.decl.Name.Pos(),
+			End:     info.decl.Name.End(),
+			Message: fmt.Sprintf("exported function %s mutates realm state without checking its caller with std.PrevRealm or std.GetOrigCaller", fn.Name()),
+			Related: []analysis.RelatedInformation{{
+				Pos:     info.mutation.Pos(),
+				End:     info.mutation.End(),
+				Message: "realm state mutated here",
+			}},
+		})
+	}
+	return nil, nil
+}
+
+// inspectFunc summarizes the body of fd, and reports the comparisons of
+// std.GetOrigCaller it contains.
+func inspectFunc(pass *analysis.Pass, fd *ast.FuncDecl) *funcInfo {
+	info := &funcInfo{decl: fd}
+	mutate := func(n ast.Node, x ast.Expr) {
+		if info.mutation == nil && isPackageState(pass, x) {
+			info.mutation = n
+		}
+	}
+
+	ast.Inspect(fd.Body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			// Closures may not be called by the function.
+			return false
+
+		case *ast.AssignStmt:
+			if n.Tok != token.DEFINE {
+				for _, lhs := range n.Lhs {
+					mutate(n, lhs)
+				}
+			}
+
+		case *ast.IncDecStmt:
+			mutate(n, n.X)
+
+		case *ast.BinaryExpr:
+			if n.Op == token.EQL || n.Op == token.NEQ {
+				for _, x := range []ast.Expr{n.X, n.Y} {
+					if call, ok := ast.Unparen(x).(*ast.CallExpr); ok && isStdFunc(pass, call, "GetOrigCaller") {
+						reportOrigCaller(pass, call)
+					}
+				}
+			}
+
+		case *ast.CallExpr:
+			switch fn := typeutil.Callee(pass.TypesInfo, n).(type) {
+			case *types.Builtin:
+				if (fn.Name() == "delete" || fn.Name() == "clear") && len(n.Args) > 0 {
+					mutate(n, n.Args[0])
+				}
+			case *types.Func:
+				if fn.Pkg() == nil {
+					break
+				}
+				if fn.Pkg().Path() == "std" && callerChecks[fn.Name()] {
+					info.checks = true
+				}
+				sig := fn.Type().(*types.Signature)
+				if recv := sig.Recv(); recv != nil {
+					// A pointer method called on package state may
+					// mutate it.
+					if _, ptr := recv.Type().(*types.Pointer); ptr {
+						if sel, ok := ast.Unparen(n.Fun).(*ast.SelectorExpr); ok {
+							mutate(n, sel.X)
+						}
+					}
+				} else if fn.Pkg() == pass.Pkg {
+					info.callees = append(info.callees, fn)
+				}
+			}
+		}
+		return true
+	})
+	return info
+}
+
+// isPackageState reports whether x denotes a package-level variable of
+// the package, or a part of one, such as a field or an element.
+func isPackageState(pass *analysis.Pass, x ast.Expr) bool {
+	for {
+		switch e := ast.Unparen(x).(type) {
+		case *ast.Ident:
+			v, ok := pass.TypesInfo.Uses[e].(*types.Var)
+			return ok && v.Pkg() == pass.Pkg && v.Parent() == pass.Pkg.Scope()
+		case *ast.SelectorExpr:
+			if _, ok := pass.TypesInfo.Selections[e]; !ok {
+				return false // qualified identifier
+			}
+			x = e.X
+		case *ast.IndexExpr:
+			x = e.X
+		case *ast.StarExpr:
+			x = e.X
+		default:
+			return false
+		}
+	}
+}
+
+// isStdFunc reports whether call is a call to the function name of
+// package std.
+func isStdFunc(pass *analysis.Pass, call *ast.CallExpr, name string) bool {
+	fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	return ok && fn.Pkg() != nil && fn.Pkg().Path() == "std" && fn.Name() == name
+}
+
+// reportOrigCaller reports the comparison of the origin caller returned
+// by call, and suggests to compare the previous realm instead.
+func reportOrigCaller(pass *analysis.Pass, call *ast.CallExpr) {
+	var fixes []analysis.SuggestedFix
+	if sel, ok := call.Fun.(*ast.SelectorExpr); ok {
+		if pkg, ok := sel.X.(*ast.Ident); ok {
+			fixes = append(fixes, analysis.SuggestedFix{
+				Message: "Use std.PrevRealm().Addr()",
+				TextEdits: []analysis.TextEdit{{
+					Pos:     call.Pos(),
+					End:     call.End(),
+					NewText: []byte(pkg.Name + ".PrevRealm().Addr()"),
+				}},
+			})
+		}
+	}
+	pass.Report(analysis.Diagnostic{
+		Pos:            call.Pos(),
+		End:            call.End(),
+		Message:        "std.GetOrigCaller is the signer of the transaction, not the caller of the realm: compare std.PrevRealm().Addr() instead",
+		SuggestedFixes: fixes,
+	})
+}
@@ -0,0 +1,12 @@
+package crossrealm
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func Test(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.RunWithSuggestedFixes(t, testdata, Analyzer, "gno.land/r/demo/counter", "gno.land/p/demo/counter")
+}
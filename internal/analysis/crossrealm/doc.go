@@ -0,0 +1,40 @@
+// Package crossrealm defines an Analyzer that checks how realm packages
+// authenticate their callers.
+//
+// # Analyzer crossrealm
+//
+// crossrealm: check caller authentication in realm packages
+//
+// The exported functions of a realm package (gno.land/r/...) may be
+// called by any user or realm, in a transaction or from another realm.
+// This analyzer reports:
+//
+//   - exported functions that mutate the state of the realm, that is,
+//     assign to a package-level variable or call a pointer method on one,
+//     without checking their caller with std.PrevRealm or
+//     std.GetOrigCaller, directly or through a function of the same
+//     package;
+//
+//   - comparisons of std.GetOrigCaller with an address. The origin
+//     caller is the signer of the transaction, which is not the caller
+//     of the realm when it is called through another realm: authorizing
+//     it lets any realm the user calls act on their behalf. The previous
+//     realm, std.PrevRealm().Addr(), is the safer check, and a suggested
+//     fix replaces the former with the latter.
+//
+// For example:
+//
+//	var owner = std.Address("g1...")
+//	var counter int
+//
+//	func Increment() {
+//		counter++ // reported: no caller check
+//	}
+//
+//	func Reset() {
+//		if std.GetOrigCaller() != owner { // reported: use std.PrevRealm().Addr()
+//			panic("unauthorized")
+//		}
+//		counter = 0
+//	}
+package crossrealm
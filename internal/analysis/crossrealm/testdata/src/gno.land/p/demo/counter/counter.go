@@ -0,0 +1,9 @@
+// Package counter is a pure package: its state is not persisted, and
+// it is not checked.
+package counter
+
+var counter int
+
+func Increment() {
+	counter++
+}
@@ -0,0 +1,73 @@
+package counter
+
+import "std"
+
+var (
+	owner   = std.Address("g1owner")
+	counter int
+	names   = map[string]bool{}
+	tree    = &Tree{}
+)
+
+type Tree struct{ size int }
+
+func (t *Tree) Set(key string) { t.size++ }
+
+func (t Tree) Size() int { return t.size }
+
+func Increment() { // want "exported function Increment mutates realm state without checking its caller"
+	counter++
+}
+
+func Register(name string) { // want "exported function Register mutates realm state"
+	names[name] = true
+}
+
+func Unregister(name string) { // want "exported function Unregister mutates realm state"
+	delete(names, name)
+}
+
+func Insert(key string) { // want "exported function Insert mutates realm state"
+	tree.Set(key)
+}
+
+func Indirect() { // want "exported function Indirect mutates realm state"
+	increment()
+}
+
+func increment() {
+	counter++
+}
+
+func Get() int {
+	return counter + tree.Size()
+}
+
+func Local() int {
+	counter := 0
+	counter++
+	return counter
+}
+
+func Reset() {
+	if std.GetOrigCaller() != owner { // want "std.GetOrigCaller is the signer of the transaction"
+		panic("unauthorized")
+	}
+	counter = 0
+}
+
+func Set(n int) {
+	assertOwner()
+	counter = n
+}
+
+func assertOwner() {
+	if std.PrevRealm().Addr() != owner {
+		panic("unauthorized")
+	}
+}
+
+func Origin() {
+	std.AssertOriginCall()
+	counter = 0
+}
@@ -0,0 +1,14 @@
+// Package std is a stub of the Gno standard package std.
+package std
+
+type Address string
+
+type Realm struct{}
+
+func (r Realm) Addr() Address { return "" }
+
+func PrevRealm() Realm { return Realm{} }
+
+func GetOrigCaller() Address { return "" }
+
+func AssertOriginCall() {}
@@ -0,0 +1,22 @@
+// Package filetestdirective defines an Analyzer that validates the
+// directives of Gno filetests.
+//
+// # Analyzer filetest
+//
+// filetest: check the directives of _filetest.gno files
+//
+// Filetests hold directives in their comments, such as
+// "// PKGPATH: gno.land/r/demo/foo" to configure the run, and blocks
+// such as "// Output:" or "// Realm:" holding the expected results.
+// The gnovm silently ignores a directive it does not recognize, so a
+// typo in a directive causes the filetest to check less than it seems.
+//
+// This analyzer reports unknown and malformed directives, such as
+//
+//	//Output:
+//	// Outptu:
+//	// Output: hello
+//
+// as well as directives that are not at the start of a comment group
+// and directives that appear more than once.
+package filetestdirective
@@ -0,0 +1,37 @@
+package filetestdirective
+
+import (
+	_ "embed"
+
+	"golang.org/x/tools/go/analysis"
+	"github.com/gfanton/gnopls/internal/analysisinternal"
+	"github.com/gfanton/gnopls/internal/filetest"
+)
+
+//go:embed doc.go
+var doc string
+
+var Analyzer = &analysis.Analyzer{
+	Name:             "filetest",
+	Doc:              analysisinternal.MustExtractDoc(doc, "filetest"),
+	Run:              run,
+	RunDespiteErrors: true,
+	URL:              "https://pkg.go.dev/github.com/gfanton/gnopls/internal/analysis/filetestdirective",
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	for _, f := range pass.Files {
+		if !filetest.IsFiletest(pass.Fset.File(f.Pos()).Name()) {
+			continue
+		}
+		_, errs := filetest.Parse(f)
+		for _, err := range errs {
+			pass.Report(analysis.Diagnostic{
+				Pos:     err.Pos,
+				End:     err.End,
+				Message: err.Msg,
+			})
+		}
+	}
+	return nil, nil
+}
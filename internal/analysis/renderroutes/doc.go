@@ -0,0 +1,32 @@
+// Package renderroutes defines an Analyzer that checks the routes of
+// the Render functions of realm packages.
+//
+// # Analyzer renderroutes
+//
+// renderroutes: check for unreachable routes of Render functions
+//
+// The Render(path string) function of a realm package (gno.land/r/...)
+// commonly dispatches on the path with a router of the mux package,
+// whose HandleFunc method registers the handler of a path pattern, or
+// with an if-else chain or a switch testing the path with
+// strings.HasPrefix or ==. The first route matching the path handles
+// it, so this analyzer reports the routes that are duplicated or
+// unreachable because an earlier route matches all their paths.
+//
+// For example:
+//
+//	func init() {
+//		router.HandleFunc("users/{name}", renderUser)
+//		router.HandleFunc("users/admin", renderAdmin) // reported: unreachable
+//	}
+//
+//	func Render(path string) string {
+//		switch {
+//		case strings.HasPrefix(path, "posts"):
+//			return renderPosts(path)
+//		case strings.HasPrefix(path, "posts/"): // reported: unreachable
+//			return renderPost(path)
+//		}
+//		return router.Render(path)
+//	}
+package renderroutes
@@ -0,0 +1,40 @@
+package renderroutes
+
+import (
+	_ "embed"
+
+	"github.com/gfanton/gnopls/internal/analysisinternal"
+	"github.com/gfanton/gnopls/internal/cache/metadata"
+	"golang.org/x/tools/go/analysis"
+)
+
+//go:embed doc.go
+var doc string
+
+var Analyzer = &analysis.Analyzer{
+	Name:             "renderroutes",
+	Doc:              analysisinternal.MustExtractDoc(doc, "renderroutes"),
+	Run:              run,
+	RunDespiteErrors: true,
+	URL:              "https://pkg.go.dev/github.com/gfanton/gnopls/internal/analysis/renderroutes",
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	if !metadata.IsRealmPath(metadata.PackagePath(pass.Pkg.Path())) {
+		return nil, nil
+	}
+	for _, f := range pass.Files {
+		for _, group := range Find(f, pass.TypesInfo) {
+			for _, c := range group.Conflicts() {
+				if c.Duplicate() {
+					pass.Reportf(c.Route.Lit.Pos(), "duplicate route %s: already handled at line %d",
+						c.Route.Lit.Value, pass.Fset.Position(c.Before.Lit.Pos()).Line)
+				} else {
+					pass.Reportf(c.Route.Lit.Pos(), "unreachable route %s: route %s, before it, matches all its paths",
+						c.Route.Lit.Value, c.Before.Lit.Value)
+				}
+			}
+		}
+	}
+	return nil, nil
+}
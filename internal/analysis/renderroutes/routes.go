@@ -0,0 +1,321 @@
+package renderroutes
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+)
+
+// A Kind is the kind of a Route.
+type Kind int
+
+const (
+	Pattern Kind = iota // a pattern registered with the HandleFunc method of a mux router
+	Prefix              // a prefix of the path tested with strings.HasPrefix
+	Exact               // a path compared to the path with ==
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Pattern:
+		return "route pattern"
+	case Prefix:
+		return "route prefix"
+	case Exact:
+		return "route"
+	}
+	return "invalid route"
+}
+
+// A Route is a route of a realm: a path pattern, prefix or exact path,
+// and the code that handles the paths it matches.
+type Route struct {
+	Kind Kind
+	Path string        // the pattern, prefix or exact path
+	Lit  *ast.BasicLit // the string literal of Path
+
+	// Handler is the handler function of a Pattern route, and the
+	// *ast.BlockStmt or *ast.CaseClause branch of the others.
+	Handler ast.Node
+}
+
+// A Group is a list of routes tried in order on a path, until one
+// matches it: those registered with a router, or those tested by the
+// branches of an if-else chain or a switch of a Render function.
+type Group []Route
+
+// Find returns the groups of routes of the file f, syntactically.
+//
+// The patterns of a router are the string literals passed to the
+// HandleFunc method of a variable, in a file importing a mux package.
+// The routers named by an identifier are told apart by the objects
+// info, if not nil, records for them, and the others by the top-level
+// declaration that registers the patterns and the expression of the
+// router.
+// The prefixes and exact paths are the string literals the path
+// parameter of the Render function is tested against, with
+// strings.HasPrefix or ==, in the conditions of an if-else chain or the
+// cases of a switch.
+func Find(f *ast.File, info *types.Info) []Group {
+	var groups []Group
+	var stringsName string
+	var hasMux bool
+	for _, spec := range f.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := path[strings.LastIndex(path, "/")+1:]
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		switch {
+		case path == "strings":
+			stringsName = name
+		case strings.HasSuffix(path, "/mux"):
+			hasMux = true
+		}
+	}
+
+	if hasMux {
+		// The routes of each router, by routerKey.
+		routers := make(map[any]int)
+		for _, decl := range f.Decls {
+			ast.Inspect(decl, func(n ast.Node) bool {
+				call, ok := n.(*ast.CallExpr)
+				if !ok || len(call.Args) != 2 {
+					return true
+				}
+				sel, ok := call.Fun.(*ast.SelectorExpr)
+				if !ok || sel.Sel.Name != "HandleFunc" {
+					return true
+				}
+				route, ok := newRoute(Pattern, call.Args[0], call.Args[1])
+				if !ok {
+					return true
+				}
+				if _, ok := sel.X.(*ast.CallExpr); ok {
+					// e.g. mux.NewRouter().HandleFunc(...)
+					groups = append(groups, Group{route})
+					return true
+				}
+				router := routerKey(info, decl, sel.X)
+				i, ok := routers[router]
+				if !ok {
+					i = len(groups)
+					routers[router] = i
+					groups = append(groups, nil)
+				}
+				groups[i] = append(groups[i], route)
+				return true
+			})
+		}
+	}
+
+	for _, decl := range f.Decls {
+		fd, ok := decl.(*ast.FuncDecl)
+		if !ok || fd.Recv != nil || fd.Name.Name != "Render" || fd.Body == nil {
+			continue
+		}
+		params := fd.Type.Params.List
+		if len(params) != 1 || len(params[0].Names) != 1 {
+			continue
+		}
+		b := &builder{path: params[0].Names[0].Name, stringsName: stringsName}
+		groups = append(groups, b.branches(fd.Body)...)
+	}
+	return groups
+}
+
+// routerKey returns the key of the router x whose HandleFunc method is
+// called in the top-level declaration decl: its object if x is an
+// identifier whose object info records, and decl and the expression of
+// x otherwise.
+func routerKey(info *types.Info, decl ast.Decl, x ast.Expr) any {
+	if id, ok := ast.Unparen(x).(*ast.Ident); ok && info != nil {
+		if obj := info.ObjectOf(id); obj != nil {
+			return obj
+		}
+	}
+	type exprKey struct {
+		decl ast.Decl
+		expr string
+	}
+	return exprKey{decl, types.ExprString(x)}
+}
+
+// newRoute returns the route of kind whose path is the string literal
+// lit, handled by handler.
+func newRoute(kind Kind, lit ast.Expr, handler ast.Node) (Route, bool) {
+	bl, ok := lit.(*ast.BasicLit)
+	if !ok || bl.Kind != token.STRING {
+		return Route{}, false
+	}
+	path, err := strconv.Unquote(bl.Value)
+	if err != nil {
+		return Route{}, false
+	}
+	return Route{Kind: kind, Path: path, Lit: bl, Handler: handler}, true
+}
+
+// A builder finds the routes of the branches of a Render function.
+type builder struct {
+	path        string // the name of the path parameter
+	stringsName string // the name of the strings package, or ""
+}
+
+// branches returns the groups of routes of the if-else chains and
+// switches of body.
+func (b *builder) branches(body *ast.BlockStmt) []Group {
+	var groups []Group
+	elses := make(map[*ast.IfStmt]bool) // the if statements of else branches
+	ast.Inspect(body, func(n ast.Node) bool {
+		var group Group
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false // the path may be another variable
+		case *ast.IfStmt:
+			if elses[n] {
+				return true
+			}
+			for stmt := n; stmt != nil; {
+				if route, ok := b.condRoute(stmt.Cond, stmt.Body); ok {
+					group = append(group, route)
+				}
+				next, _ := stmt.Else.(*ast.IfStmt)
+				if next != nil {
+					elses[next] = true
+				}
+				stmt = next
+			}
+		case *ast.SwitchStmt:
+			tag, _ := n.Tag.(*ast.Ident)
+			if n.Tag != nil && (tag == nil || tag.Name != b.path) {
+				return true
+			}
+			for _, stmt := range n.Body.List {
+				clause := stmt.(*ast.CaseClause)
+				for _, expr := range clause.List {
+					var route Route
+					var ok bool
+					if n.Tag == nil {
+						route, ok = b.condRoute(expr, clause)
+					} else {
+						route, ok = newRoute(Exact, expr, clause)
+					}
+					if ok {
+						group = append(group, route)
+					}
+				}
+			}
+		}
+		if len(group) > 0 {
+			groups = append(groups, group)
+		}
+		return true
+	})
+	return groups
+}
+
+// condRoute returns the route tested by the condition cond of the
+// branch handler, if any.
+func (b *builder) condRoute(cond ast.Expr, handler ast.Node) (Route, bool) {
+	switch cond := ast.Unparen(cond).(type) {
+	case *ast.CallExpr:
+		// strings.HasPrefix(path, "prefix")
+		sel, ok := cond.Fun.(*ast.SelectorExpr)
+		if !ok || sel.Sel.Name != "HasPrefix" || len(cond.Args) != 2 || !b.isPath(cond.Args[0]) {
+			break
+		}
+		if pkg, ok := sel.X.(*ast.Ident); !ok || b.stringsName == "" || pkg.Name != b.stringsName {
+			break
+		}
+		return newRoute(Prefix, cond.Args[1], handler)
+	case *ast.BinaryExpr:
+		// path == "path", or "path" == path
+		if cond.Op != token.EQL {
+			break
+		}
+		lit := cond.Y
+		if !b.isPath(cond.X) {
+			lit = cond.X
+			if !b.isPath(cond.Y) {
+				break
+			}
+		}
+		return newRoute(Exact, lit, handler)
+	}
+	return Route{}, false
+}
+
+// isPath reports whether e denotes the path parameter.
+func (b *builder) isPath(e ast.Expr) bool {
+	id, ok := ast.Unparen(e).(*ast.Ident)
+	return ok && id.Name == b.path
+}
+
+// A Conflict is a route of a group that can't match any path, as the
+// route Before, tried before it, matches all the paths it matches.
+type Conflict struct {
+	Route, Before Route
+}
+
+// Duplicate reports whether the routes of c are the same.
+func (c Conflict) Duplicate() bool {
+	return c.Route.Kind == c.Before.Kind && c.Route.Path == c.Before.Path
+}
+
+// Conflicts returns the unreachable routes of g.
+func (g Group) Conflicts() []Conflict {
+	var conflicts []Conflict
+	for i, route := range g {
+		for _, before := range g[:i] {
+			if shadows(before, route) {
+				conflicts = append(conflicts, Conflict{route, before})
+				break
+			}
+		}
+	}
+	return conflicts
+}
+
+// shadows reports whether the route r matches all the paths the route
+// s matches.
+func shadows(r, s Route) bool {
+	switch r.Kind {
+	case Pattern:
+		// Pattern segments match the path segments one to one: a
+		// "{name}" segment matches any segment, and a "*" segment all
+		// the remaining ones, of which there is at least one.
+		if s.Kind != Pattern {
+			return false
+		}
+		rs, ss := strings.Split(r.Path, "/"), strings.Split(s.Path, "/")
+		for i := range rs {
+			switch {
+			case rs[i] == "*":
+				return i < len(ss)
+			case i >= len(ss) || ss[i] == "*":
+				return false
+			case isParam(rs[i]):
+				continue
+			case isParam(ss[i]) || rs[i] != ss[i]:
+				return false
+			}
+		}
+		return len(rs) == len(ss)
+	case Prefix:
+		return s.Kind != Pattern && strings.HasPrefix(s.Path, r.Path)
+	case Exact:
+		return s.Kind == Exact && s.Path == r.Path
+	}
+	return false
+}
+
+// isParam reports whether the segment of a pattern is a "{name}"
+// parameter, which matches any segment of a path.
+func isParam(segment string) bool {
+	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
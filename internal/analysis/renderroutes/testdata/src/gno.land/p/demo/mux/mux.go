@@ -0,0 +1,16 @@
+// Package mux is a stub of the mux package of the Gno examples.
+package mux
+
+type ResponseWriter struct{}
+
+type Request struct{}
+
+type HandlerFunc func(*ResponseWriter, *Request)
+
+type Router struct{}
+
+func NewRouter() *Router { return &Router{} }
+
+func (r *Router) HandleFunc(pattern string, fn HandlerFunc) {}
+
+func (r *Router) Render(path string) string { return "" }
@@ -0,0 +1,87 @@
+package routes
+
+import (
+	"strings"
+
+	"gno.land/p/demo/mux"
+)
+
+var router = mux.NewRouter()
+
+func init() {
+	router.HandleFunc("", renderHome)
+	router.HandleFunc("users/{name}", renderUser)
+	router.HandleFunc("users/admin", renderUser) // want `unreachable route "users/admin": route "users/{name}", before it, matches all its paths`
+	router.HandleFunc("users/{name}/posts", renderUser)
+	router.HandleFunc("", renderHome) // want `duplicate route "": already handled at line 12`
+	router.HandleFunc("about", func(res *mux.ResponseWriter, req *mux.Request) {})
+}
+
+func renderHome(res *mux.ResponseWriter, req *mux.Request) {}
+
+func renderUser(res *mux.ResponseWriter, req *mux.Request) {}
+
+func Render(path string) string {
+	switch {
+	case strings.HasPrefix(path, "posts"):
+		return "posts"
+	case path == "posts/latest": // want `unreachable route "posts/latest": route "posts", before it, matches all its paths`
+		return "latest"
+	case strings.HasPrefix(path, "about"):
+		return "about"
+	}
+
+	if path == "help" {
+		return "help"
+	} else if path == "help" { // want `duplicate route "help": already handled at line 34`
+		return "help"
+	} else if strings.HasPrefix(path, "help/") {
+		return "help topic"
+	}
+
+	switch path {
+	case "a", "b":
+		return "a or b"
+	case "b": // want `duplicate route "b": already handled at line 43`
+		return "b"
+	}
+	return router.Render(path)
+}
+
+var files = mux.NewRouter()
+
+func init() {
+	files.HandleFunc("files", renderHome)
+	files.HandleFunc("files/*", renderHome)
+	files.HandleFunc("files/readme", renderHome)       // want `unreachable route "files/readme": route "files/\*", before it, matches all its paths`
+	files.HandleFunc("files/{dir}/{name}", renderHome) // want `unreachable route "files/{dir}/{name}": route "files/\*", before it, matches all its paths`
+	files.HandleFunc("files/*/raw", renderHome)        // want `unreachable route "files/\*/raw": route "files/\*", before it, matches all its paths`
+	files.HandleFunc("{dir}/*", renderHome)
+}
+
+// The local routers of different functions have routes of their own.
+
+func renderPosts(path string) string {
+	r := mux.NewRouter()
+	r.HandleFunc("{id}", renderHome)
+	return r.Render(path)
+}
+
+func renderComments(path string) string {
+	r := mux.NewRouter()
+	r.HandleFunc("{id}", renderHome)
+	r.HandleFunc("{id}", renderHome) // want `duplicate route "{id}": already handled at line 72`
+	return r.Render(path)
+}
+
+// The routers of a function are told apart by their objects.
+
+func renderArchive(path string) string {
+	{
+		r := mux.NewRouter()
+		r.HandleFunc("archive", renderHome)
+	}
+	r := mux.NewRouter()
+	r.HandleFunc("archive", renderHome)
+	return r.Render(path)
+}
@@ -978,20 +978,11 @@ func (an *analysisNode) typeCheck(parsed []*parsego.File) *analysisPackage {
 		}
 	}
 
+	var typeErrors []types.Error
 	cfg := &types.Config{
 		Sizes: mp.TypesSizes,
 		Error: func(e error) {
-			pkg.compiles = false // type error
-
-			// Suppress type errors in files with parse errors
-			// as parser recovery can be quite lossy (#59888).
-			typeError := e.(types.Error)
-			for _, p := range parsed {
-				if p.ParseErr != nil && astutil.NodeContains(p.File, typeError.Pos) {
-					return
-				}
-			}
-			pkg.typeErrors = append(pkg.typeErrors, typeError)
+			typeErrors = append(typeErrors, e.(types.Error))
 		},
 		Importer: importerFunc(func(importPath string) (*types.Package, error) {
 			// Beware that returning an error from this function
@@ -1047,8 +1038,25 @@ func (an *analysisNode) typeCheck(parsed []*parsego.File) *analysisPackage {
 	check := types.NewChecker(cfg, pkg.fset, pkg.types, pkg.typesInfo)
 
 	// Type checking errors are handled via the config, so ignore them here.
+	declareGnoUniverse(pkg.types, pkg.files)
 	_ = check.Files(pkg.files)
 
+	// The overflows of the Gno big number types are not errors.
+	for _, typeError := range typeErrors {
+		if isBigOverflow(pkg.types, pkg.typesInfo, pkg.files, typeError) {
+			continue
+		}
+		pkg.compiles = false // type error
+
+		// Suppress type errors in files with parse errors
+		// as parser recovery can be quite lossy (#59888).
+		if !slices.ContainsFunc(parsed, func(p *parsego.File) bool {
+			return p.ParseErr != nil && astutil.NodeContains(p.File, typeError.Pos)
+		}) {
+			pkg.typeErrors = append(pkg.typeErrors, typeError)
+		}
+	}
+
 	// debugging (type errors are quite normal)
 	if false {
 		if pkg.typeErrors != nil {
@@ -5,6 +5,7 @@
 package cache
 
 import (
+	"math/bits"
 	"reflect"
 	"strconv"
 	"sync/atomic"
@@ -82,6 +83,11 @@ func New(store *memoize.Store) *Cache {
 			timers: make(map[string]*refreshTimer),
 		},
 	}
+	if bits.UintSize > 32 {
+		// As parsed files (see parseFiles), the files of shared packages
+		// need room in the token.Pos space.
+		c.sharedPackages = newSharedPackages()
+	}
 	return c
 }
 
@@ -107,6 +113,10 @@ type Cache struct {
 
 	// modCache holds the
 	modCache *sharedModCache
+
+	// sharedPackages holds the packages of the Gno root directory imported
+	// by the views of all sessions, or is nil on 32-bit systems.
+	sharedPackages *sharedPackages
 }
 
 var cacheIndex, sessionIndex, viewIndex int64
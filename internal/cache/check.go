@@ -7,6 +7,7 @@ package cache
 import (
 	"context"
 	"crypto/sha256"
+	"errors"
 	"fmt"
 	"go/ast"
 	"go/build"
@@ -35,6 +36,7 @@ import (
 	"github.com/gfanton/gnopls/internal/tokeninternal"
 	"github.com/gfanton/gnopls/internal/typesinternal"
 	"github.com/gfanton/gnopls/internal/util/bug"
+	"github.com/gfanton/gnopls/internal/util/immutable"
 	"github.com/gfanton/gnopls/internal/util/safetoken"
 	"github.com/gfanton/gnopls/internal/versions"
 	"golang.org/x/mod/module"
@@ -63,6 +65,10 @@ type typeCheckBatch struct {
 	fset        *token.FileSet // describes all parsed or imported files
 	cpulimit    chan unit      // concurrency limiter for CPU-bound operations
 
+	shared      *sharedPackages                       // packages shared by all views, or nil
+	sharedRoots []string                              // directories of the shareable packages
+	workspace   immutable.Map[PackageID, PackagePath] // workspace packages, never shared
+
 	mu             sync.Mutex
 	syntaxPackages map[PackageID]*futurePackage // results of processing a requested package; may hold (nil, nil)
 	importPackages map[PackageID]*futurePackage // package results to use for importing
@@ -410,7 +416,12 @@ func (s *Snapshot) forEachPackageInternal(ctx context.Context, importGraph *impo
 		cpulimit:       make(chan unit, runtime.GOMAXPROCS(0)),
 		syntaxPackages: make(map[PackageID]*futurePackage),
 		importPackages: make(map[PackageID]*futurePackage),
+		shared:         s.view.sharedPackages,
+		sharedRoots:    s.view.sharedRoots(),
 	}
+	s.mu.Lock()
+	b.workspace = s.workspacePackages
+	s.mu.Unlock()
 
 	if importGraph != nil {
 		// Clone the file set every time, to ensure we do not leak files.
@@ -508,10 +519,28 @@ func (b *typeCheckBatch) getImportPackage(ctx context.Context, id PackageID) (pk
 		return types.Unsafe, nil
 	}
 
+	// The packages of the Gno root directory are shared by all views.
+	if b.shareable(ph) {
+		sp, err := b.shared.get(ctx, b, ph)
+		if err == nil {
+			tokeninternal.AddExistingFiles(b.fset, sp.files)
+			return sp.pkg, nil
+		}
+		if !errors.Is(err, errNotShared) {
+			return nil, err
+		}
+	}
+
 	data, err := filecache.Get(exportDataKind, ph.key)
 	if err == filecache.ErrNotFound {
 		// No cached export data: type-check as fast as possible.
-		return b.checkPackageForImport(ctx, ph)
+		pkg, err := b.checkPackageForImport(ctx, b.fset, ph)
+		if err != nil {
+			return nil, err
+		}
+		// Asynchronously record export data.
+		go exportPackage(ctx, b.fset, ph, pkg)
+		return pkg, nil
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to read cache data for %s: %v", ph.mp.ID, err)
@@ -602,6 +631,7 @@ func storePackageResults(ctx context.Context, ph *packageHandle, p *Package) {
 		xrefsKind:       p.pkg.xrefs(),
 		methodSetsKind:  p.pkg.methodsets().Encode(),
 		testsKind:       p.pkg.tests().Encode(),
+		eventsKind:      p.pkg.events().Encode(),
 		diagnosticsKind: encodeDiagnostics(p.pkg.diagnostics),
 	}
 
@@ -688,8 +718,8 @@ func (b *typeCheckBatch) importPackage(ctx context.Context, mp *metadata.Package
 }
 
 // checkPackageForImport type checks, but skips function bodies and does not
-// record syntax information.
-func (b *typeCheckBatch) checkPackageForImport(ctx context.Context, ph *packageHandle) (*types.Package, error) {
+// record syntax information. Files are parsed into fset.
+func (b *typeCheckBatch) checkPackageForImport(ctx context.Context, fset *token.FileSet, ph *packageHandle) (*types.Package, error) {
 	ctx, done := event.Start(ctx, "cache.typeCheckBatch.checkPackageForImport", label.Package.Of(string(ph.mp.ID)))
 	defer done()
 
@@ -716,7 +746,7 @@ func (b *typeCheckBatch) checkPackageForImport(ctx context.Context, ph *packageH
 		for i, fh := range ph.localInputs.compiledGoFiles {
 			i, fh := i, fh
 			group.Go(func() error {
-				pgf, err := parseGoImpl(ctx, b.fset, fh, parser.SkipObjectResolution, false)
+				pgf, err := parseGoImpl(ctx, fset, fh, parser.SkipObjectResolution, false)
 				pgfs[i] = pgf
 				return err
 			})
@@ -726,7 +756,7 @@ func (b *typeCheckBatch) checkPackageForImport(ctx context.Context, ph *packageH
 		}
 	}
 	pkg := types.NewPackage(string(ph.localInputs.pkgPath), string(ph.localInputs.name))
-	check := types.NewChecker(cfg, b.fset, pkg, nil)
+	check := types.NewChecker(cfg, fset, pkg, nil)
 
 	files := make([]*ast.File, len(pgfs))
 	for i, pgf := range pgfs {
@@ -739,6 +769,7 @@ func (b *typeCheckBatch) checkPackageForImport(ctx context.Context, ph *packageH
 		return nil, ctx.Err()
 	}
 
+	declareGnoUniverse(pkg, files)
 	_ = check.Files(files) // ignore errors
 
 	// If the context was cancelled, we may have returned a ton of transient
@@ -747,20 +778,22 @@ func (b *typeCheckBatch) checkPackageForImport(ctx context.Context, ph *packageH
 		return nil, ctx.Err()
 	}
 
-	// Asynchronously record export data.
-	go func() {
-		exportData, err := gcimporter.IExportShallow(b.fset, pkg, bug.Reportf)
-		if err != nil {
-			bug.Reportf("exporting package %v: %v", ph.mp.ID, err)
-			return
-		}
-		if err := filecache.Set(exportDataKind, ph.key, exportData); err != nil {
-			event.Error(ctx, fmt.Sprintf("storing export data for %s", ph.mp.ID), err)
-		}
-	}()
 	return pkg, nil
 }
 
+// exportPackage records the export data of pkg, type-checked for import
+// with fset, and returns it.
+func exportPackage(ctx context.Context, fset *token.FileSet, ph *packageHandle, pkg *types.Package) ([]byte, error) {
+	exportData, err := gcimporter.IExportShallow(fset, pkg, bug.Reportf)
+	if err != nil {
+		return nil, bug.Errorf("exporting package %v: %v", ph.mp.ID, err)
+	}
+	if err := filecache.Set(exportDataKind, ph.key, exportData); err != nil {
+		event.Error(ctx, fmt.Sprintf("storing export data for %s", ph.mp.ID), err)
+	}
+	return exportData, nil
+}
+
 // importLookup returns a function that may be used to look up a package ID for
 // a given package path, based on the forward transitive closure of the initial
 // package (id).
@@ -1402,6 +1435,7 @@ type typeCheckInputs struct {
 	// Then these fields need not be part of the type checking inputs.
 	supportsRelatedInformation bool
 	linkTarget                 string
+	gnowebLinks                bool
 	viewType                   ViewType
 }
 
@@ -1442,6 +1476,7 @@ func (s *Snapshot) typeCheckInputs(ctx context.Context, mp *metadata.Package) (t
 
 		supportsRelatedInformation: s.Options().RelatedInformationSupported,
 		linkTarget:                 s.Options().LinkTarget,
+		gnowebLinks:                s.Options().GnowebLinks,
 		viewType:                   s.view.typ,
 	}, nil
 }
@@ -1501,6 +1536,7 @@ func localPackageKey(inputs typeCheckInputs) file.Hash {
 
 	fmt.Fprintf(hasher, "relatedInformation: %t\n", inputs.supportsRelatedInformation)
 	fmt.Fprintf(hasher, "linkTarget: %s\n", inputs.linkTarget)
+	fmt.Fprintf(hasher, "gnowebLinks: %t\n", inputs.gnowebLinks)
 	fmt.Fprintf(hasher, "viewType: %d\n", inputs.viewType)
 
 	var hash [sha256.Size]byte
@@ -1584,7 +1620,11 @@ func (b *typeCheckBatch) checkPackage(ctx context.Context, ph *packageHandle) (*
 		}
 
 		// Type checking errors are handled via the config, so ignore them here.
+		declareGnoUniverse(pkg.types, files)
 		_ = check.Files(files) // 50us-15ms, depending on size of package
+		pkg.typeErrors = slices.DeleteFunc(pkg.typeErrors, func(e types.Error) bool {
+			return isBigOverflow(pkg.types, pkg.typesInfo, files, e)
+		})
 
 		// If the context was cancelled, we may have returned a ton of transient
 		// errors to the type checker. Swallow them.
@@ -1639,8 +1679,23 @@ func (b *typeCheckBatch) checkPackage(ctx context.Context, ph *packageHandle) (*
 		}
 	}
 
+	// The imports the resolver couldn't resolve are reported as load
+	// errors: don't report them again as type errors.
+	unresolved := make(map[protocol.DocumentURI]map[string]bool)
+	for _, diag := range ph.loadDiagnostics {
+		if match := importErrorRe.FindStringSubmatch(diag.Message); match != nil {
+			if unresolved[diag.URI] == nil {
+				unresolved[diag.URI] = make(map[string]bool)
+			}
+			unresolved[diag.URI][match[1]] = true
+		}
+	}
+
 	diags := typeErrorsToDiagnostics(pkg, inputs, pkg.typeErrors)
 	for _, diag := range diags {
+		if match := importErrorRe.FindStringSubmatch(diag.Message); match != nil && unresolved[diag.URI][match[1]] {
+			continue
+		}
 		// If the file didn't parse cleanly, it is highly likely that type
 		// checking errors will be confusing or redundant. But otherwise, type
 		// checking usually provides a good enough signal to include.
@@ -1649,6 +1704,19 @@ func (b *typeCheckBatch) checkPackage(ctx context.Context, ph *packageHandle) (*
 		}
 	}
 
+	// Report the violations of the rules Gno derives from package paths,
+	// which the type checker doesn't know about.
+	depPath := func(path ImportPath) PackagePath {
+		if depPH := b.handles[inputs.depsByImpPath[path]]; depPH != nil {
+			return depPH.mp.PkgPath
+		}
+		return PackagePath(path)
+	}
+	if ph.mp.ForTest == "" { // test variants would repeat them
+		pkg.diagnostics = append(pkg.diagnostics, packageRuleDiagnostics(ctx, pkg, inputs.pkgPath, depPath)...)
+	}
+	pkg.diagnostics = append(pkg.diagnostics, unsupportedFeatureDiagnostics(ctx, pkg)...)
+
 	return &Package{ph.mp, ph.loadDiagnostics, pkg}, nil
 }
 
@@ -1884,6 +1952,8 @@ func missingPkgError(from PackageID, pkgPath string, viewType ViewType) error {
 		return fmt.Errorf("go/packages driver could not load %q", pkgPath)
 	case GOPATHView:
 		return fmt.Errorf("cannot find package %q in GOROOT or GOPATH", pkgPath)
+	case GnoWorkView:
+		return fmt.Errorf("cannot find package %q in the gno.work packages or GNOROOT", pkgPath)
 	default:
 		return fmt.Errorf("unable to load package")
 	}
@@ -2005,7 +2075,7 @@ func typeErrorsToDiagnostics(pkg *syntaxPackage, inputs typeCheckInputs, errs []
 			}
 			if code != 0 {
 				diag.Code = code.String()
-				diag.CodeHref = typesCodeHref(inputs.linkTarget, code)
+				diag.CodeHref = typesCodeHref(inputs.gnowebLinks, inputs.linkTarget, code)
 			}
 			if code == typesinternal.UnusedVar || code == typesinternal.UnusedImport {
 				diag.Tags = append(diag.Tags, protocol.Unnecessary)
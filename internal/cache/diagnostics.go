@@ -83,7 +83,11 @@ const (
 	Govulncheck              DiagnosticSource = "govulncheck"
 	TemplateError            DiagnosticSource = "template"
 	WorkFileError            DiagnosticSource = "go.work file"
+	ModFileError             DiagnosticSource = "gno.mod file"
 	ConsistencyInfo          DiagnosticSource = "consistency"
+	TestFailure              DiagnosticSource = "gno test"
+	PackageRuleError         DiagnosticSource = "gno package rules"
+	UnsupportedFeatureError  DiagnosticSource = "gno unsupported feature"
 )
 
 // A SuggestedFix represents a suggested fix (for a diagnostic)
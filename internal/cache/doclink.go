@@ -0,0 +1,115 @@
+package cache
+
+import (
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/gfanton/gnopls/internal/cache/metadata"
+	"github.com/gfanton/gnopls/internal/protocol"
+)
+
+// StdlibDocs is the base URL of the documentation of the Gno standard
+// libraries, to which DocLink links them whatever the link target.
+const StdlibDocs = "https://docs.gno.land/reference/stdlibs"
+
+// A RealmView is a page that gnoweb serves for a realm in addition to
+// the output of its Render function.
+type RealmView string
+
+const (
+	RealmRender RealmView = ""       // the output of Render
+	RealmSource RealmView = "source" // the source files
+	RealmHelp   RealmView = "help"   // the exported functions, and how to call them
+)
+
+// DocLink returns the URL of the documentation of the package path,
+// at the optional symbol anchor (e.g. "Tree.Get"), on the documentation
+// host target (the linkTarget setting). view is the view of a realm that
+// documents the symbol: RealmHelp for a function, and RealmSource for
+// any other symbol, or for a symbol of unknown kind.
+//
+// Unless gnoweb (the gnowebLinks setting) is set, target is a host of Go
+// package documentation, and the URL is that of BuildLink. Otherwise
+// target is the address of a gnoweb instance, such as gno.land or
+// localhost:8888, optionally with a scheme, and:
+//
+//   - a pure package (gno.land/p/...) links to its gnoweb page;
+//   - a realm (gno.land/r/...) links to the view of its anchor, or to
+//     its rendered page without an anchor;
+//   - a standard library links to its page of StdlibDocs, at the
+//     heading of the anchor;
+//   - any other package, which gnoweb can't serve, links to pkg.go.dev.
+func DocLink(gnoweb bool, target, path, anchor string, view RealmView) protocol.URI {
+	if !gnoweb {
+		return BuildLink(target, path, anchor)
+	}
+	path, _, _ = strings.Cut(path, "@") // gnoweb has no versions
+
+	pkgPath := PackagePath(path)
+	switch {
+	case metadata.IsRealmPath(pkgPath):
+		switch {
+		case anchor == "":
+			return RealmLink(target, path, RealmRender)
+		case view == RealmHelp:
+			return RealmLink(target, path, RealmHelp) + "#func-" + anchor
+		default:
+			return RealmLink(target, path, RealmSource)
+		}
+	case metadata.IsPurePath(pkgPath):
+		return RealmLink(target, path, RealmRender)
+	case !strings.Contains(strings.Split(path, "/")[0], "."):
+		link := StdlibDocs + "/" + path
+		if anchor != "" {
+			// The headings of the symbols are their names, and those of
+			// the methods are nested in the headings of their types.
+			link += "#" + strings.ToLower(anchor[strings.LastIndex(anchor, ".")+1:])
+		}
+		return link
+	default:
+		return BuildLink("pkg.go.dev", path, anchor)
+	}
+}
+
+// RealmLink returns the URL of the given view of the package path, a
+// realm or a pure package, on the gnoweb instance target. A target
+// without a scheme is served over https, or over http for a loopback
+// host such as that of a local gnodev.
+func RealmLink(target, path string, view RealmView) protocol.URI {
+	base := target
+	if !strings.Contains(base, "://") {
+		if isLoopback(base) {
+			base = "http://" + base
+		} else {
+			base = "https://" + base
+		}
+	}
+	base = strings.TrimSuffix(base, "/")
+
+	// gnoweb serves the packages of its chain by path, without the
+	// domain of the chain.
+	if _, rest, ok := strings.Cut(path, "/"); ok {
+		path = rest
+	}
+	link := base + "/" + path
+	if view != RealmRender {
+		link += "$" + string(view)
+	}
+	return link
+}
+
+// isLoopback reports whether the host of the address addr, without a
+// scheme, is localhost or a loopback IP address.
+func isLoopback(addr string) bool {
+	u, err := url.Parse("//" + addr)
+	if err != nil {
+		return false
+	}
+	host := u.Hostname()
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,35 @@
+package cache
+
+import "testing"
+
+func TestDocLink(t *testing.T) {
+	tests := []struct {
+		gnoweb               bool
+		target, path, anchor string
+		view                 RealmView
+		want                 string
+	}{
+		{true, "gno.land", "gno.land/p/demo/avl", "", RealmRender, "https://gno.land/p/demo/avl"},
+		{true, "gno.land", "gno.land/p/demo/avl", "Tree.Get", RealmSource, "https://gno.land/p/demo/avl"},
+		{true, "gno.land", "gno.land/r/demo/boards", "", RealmRender, "https://gno.land/r/demo/boards"},
+		{true, "gno.land", "gno.land/r/demo/boards", "CreateBoard", RealmHelp, "https://gno.land/r/demo/boards$help#func-CreateBoard"},
+		{true, "gno.land", "gno.land/r/demo/boards", "Board.GetURL", RealmSource, "https://gno.land/r/demo/boards$source"},
+		{true, "gno.land", "gno.land/r/demo/boards", "Board", RealmSource, "https://gno.land/r/demo/boards$source"},
+		{true, "http://localhost:8888/", "gno.land/r/demo/boards", "", RealmRender, "http://localhost:8888/r/demo/boards"},
+		{true, "localhost:8888", "gno.land/r/demo/boards", "", RealmRender, "http://localhost:8888/r/demo/boards"},
+		{true, "127.0.0.1:8888", "gno.land/r/demo/boards", "", RealmRender, "http://127.0.0.1:8888/r/demo/boards"},
+		{true, "[::1]:8888", "gno.land/r/demo/boards", "", RealmRender, "http://[::1]:8888/r/demo/boards"},
+		{true, "test5.gno.land", "gno.land/r/demo/boards", "", RealmRender, "https://test5.gno.land/r/demo/boards"},
+		{true, "gno.land", "std", "Address", RealmSource, "https://docs.gno.land/reference/stdlibs/std#address"},
+		{true, "gno.land", "crypto/sha256", "", RealmRender, "https://docs.gno.land/reference/stdlibs/crypto/sha256"},
+		{true, "gno.land", "std", "Address.IsValid", RealmSource, "https://docs.gno.land/reference/stdlibs/std#isvalid"},
+		{true, "gno.land", "github.com/gfanton/gnopls/internal/typesinternal", "UndeclaredName", RealmSource, "https://pkg.go.dev/github.com/gfanton/gnopls/internal/typesinternal#UndeclaredName"},
+		{false, "pkg.go.dev", "gno.land/p/demo/avl", "Tree.Get", RealmSource, "https://pkg.go.dev/gno.land/p/demo/avl#Tree.Get"},
+		{false, "godocs.example.com", "gno.land/r/demo/boards", "", RealmRender, "https://godocs.example.com/gno.land/r/demo/boards"},
+	}
+	for _, test := range tests {
+		if got := DocLink(test.gnoweb, test.target, test.path, test.anchor, test.view); got != test.want {
+			t.Errorf("DocLink(%t, %q, %q, %q, %q) = %q, want %q", test.gnoweb, test.target, test.path, test.anchor, test.view, got, test.want)
+		}
+	}
+}
@@ -129,11 +129,11 @@ var importErrorRe = regexp.MustCompile(`could not import ([^\s]+)`)
 var unsupportedFeatureRe = regexp.MustCompile(`.*require.* go(\d+\.\d+) or later`)
 
 func goGetQuickFixes(haveModule bool, uri protocol.DocumentURI, pkg string) []SuggestedFix {
-	// Go get only supports module mode for now.
+	// The package is added to the requirements of a gno.mod file.
 	if !haveModule {
 		return nil
 	}
-	title := fmt.Sprintf("go get package %v", pkg)
+	title := fmt.Sprintf("Fetch %v and add it to gno.mod", pkg)
 	cmd := command.NewGoGetPackageCommand(title, command.GoGetPackageArgs{
 		URI:        uri,
 		AddRequire: true,
@@ -370,8 +370,8 @@ func onlyDeletions(fixes []SuggestedFix) bool {
 	return len(fixes) > 0
 }
 
-func typesCodeHref(linkTarget string, code typesinternal.ErrorCode) string {
-	return BuildLink(linkTarget, "github.com/gfanton/gnopls/internal/typesinternal", code.String())
+func typesCodeHref(gnoweb bool, linkTarget string, code typesinternal.ErrorCode) string {
+	return DocLink(gnoweb, linkTarget, "github.com/gfanton/gnopls/internal/typesinternal", code.String(), RealmSource)
 }
 
 // BuildLink constructs a URL with the given target, path, and anchor.
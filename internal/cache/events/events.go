@@ -0,0 +1,151 @@
+// Package events defines the serializable index of the events a
+// package emits with std.Emit, computed during type checking.
+//
+// Indexers and frontends depend on the types and attribute keys of the
+// events of realms, so the index records those that are constant, to
+// find them across the workspace.
+//
+// See ../../golang/events.go for the queries using it.
+package events
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/types"
+
+	"github.com/gfanton/gnopls/internal/cache/parsego"
+	"github.com/gfanton/gnopls/internal/protocol"
+	"github.com/gfanton/gnopls/internal/util/frob"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// An Index records the events emitted by a package.
+type Index struct {
+	pkg gobPackage
+}
+
+// Decode decodes the given gob-encoded data as an Index.
+func Decode(data []byte) *Index {
+	var pkg gobPackage
+	packageCodec.Decode(data, &pkg)
+	return &Index{pkg}
+}
+
+// Encode encodes the receiver as gob-encoded data.
+func (index *Index) Encode() []byte {
+	return packageCodec.Encode(index.pkg)
+}
+
+// All returns the entries of the index, in the order of the files of
+// the package, and of their positions in each file.
+func (index *Index) All() []Entry {
+	return index.pkg.Entries
+}
+
+// A Kind is the kind of an Entry.
+type Kind uint8
+
+const (
+	Type    Kind = iota // the type of an event, the first argument of std.Emit
+	AttrKey             // the key of an attribute of an event
+)
+
+// An Entry is a constant event type or attribute key passed to
+// std.Emit.
+type Entry struct {
+	Kind     Kind
+	Event    string            // the type of the event, or "" if not constant
+	Key      string            // the attribute key, for AttrKey entries
+	Func     string            // the name of the enclosing function, if any
+	Location protocol.Location // of the argument
+}
+
+// Name returns the name of the entry, such as "Transfer" for an event
+// type, and "Transfer.from" for one of its attribute keys.
+func (e Entry) Name() string {
+	if e.Kind == Type || e.Event == "" {
+		return e.Event + e.Key
+	}
+	return e.Event + "." + e.Key
+}
+
+// NewIndex returns a new index of the events emitted by the specified
+// package.
+func NewIndex(files []*parsego.File, info *types.Info) *Index {
+	var entries []Entry
+	for _, pgf := range files {
+		for _, decl := range pgf.File.Decls {
+			var fn string
+			if decl, ok := decl.(*ast.FuncDecl); ok {
+				fn = decl.Name.Name
+			}
+			ast.Inspect(decl, func(n ast.Node) bool {
+				if call, ok := n.(*ast.CallExpr); ok {
+					entries = append(entries, CallEntries(pgf, info, call, fn)...)
+				}
+				return true
+			})
+		}
+	}
+	return &Index{gobPackage{Entries: entries}}
+}
+
+// IsEmit reports whether call is a call of std.Emit.
+func IsEmit(info *types.Info, call *ast.CallExpr) bool {
+	fn, ok := typeutil.Callee(info, call).(*types.Func)
+	return ok && fn.Pkg() != nil && fn.Pkg().Path() == "std" && fn.Name() == "Emit"
+}
+
+// CallEntries returns the entries of call, in the function fn of the
+// file pgf, if it is a call of std.Emit.
+func CallEntries(pgf *parsego.File, info *types.Info, call *ast.CallExpr, fn string) []Entry {
+	if !IsEmit(info, call) || len(call.Args) == 0 {
+		return nil
+	}
+	stringVal := func(arg ast.Expr) (string, bool) {
+		val := info.Types[arg].Value
+		if val == nil || val.Kind() != constant.String {
+			return "", false
+		}
+		return constant.StringVal(val), true
+	}
+	var entries []Entry
+	add := func(kind Kind, event, key string, arg ast.Expr) {
+		loc, err := pgf.NodeLocation(arg)
+		if err != nil {
+			return
+		}
+		entries = append(entries, Entry{
+			Kind:     kind,
+			Event:    event,
+			Key:      key,
+			Func:     fn,
+			Location: loc,
+		})
+	}
+
+	event, ok := stringVal(call.Args[0])
+	if ok {
+		add(Type, event, "", call.Args[0])
+	}
+	if call.Ellipsis.IsValid() {
+		return entries // the attributes are not listed
+	}
+	// The attributes are pairs of a key and a value.
+	for i := 1; i < len(call.Args); i += 2 {
+		if key, ok := stringVal(call.Args[i]); ok {
+			add(AttrKey, event, key, call.Args[i])
+		}
+	}
+	return entries
+}
+
+// -- serialized representation --
+
+// (The name says gob but in fact we use frob.)
+var packageCodec = frob.CodecFor[gobPackage]()
+
+// A gobPackage records the events emitted by a single package.
+type gobPackage struct {
+	Entries []Entry
+}
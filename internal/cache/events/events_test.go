@@ -0,0 +1,82 @@
+package events_test
+
+import (
+	"context"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"reflect"
+	"testing"
+
+	"github.com/gfanton/gnopls/internal/cache/events"
+	"github.com/gfanton/gnopls/internal/cache/parsego"
+	"github.com/gfanton/gnopls/internal/protocol"
+)
+
+type importerFunc func(path string) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) { return f(path) }
+
+func TestIndex(t *testing.T) {
+	const stdSrc = `package std
+
+func Emit(typ string, attrs ...string) {}
+`
+	const src = `package foo
+
+import "std"
+
+const EventTransfer = "Transfer"
+
+func Transfer(to, memo string) {
+	std.Emit(EventTransfer, "to", to, "memo", memo)
+}
+
+func Burn(typ string, attrs []string) {
+	std.Emit("Burn", "amount", "1")
+	std.Emit(typ, "amount", "2")
+	std.Emit("Burn", attrs...)
+}
+`
+	fset := token.NewFileSet()
+	check := func(path, src string, imp types.Importer) (*parsego.File, *types.Info, *types.Package) {
+		pgf, _ := parsego.Parse(context.Background(), fset, protocol.DocumentURI("file:///"+path+".gno"), []byte(src), parsego.Full, false)
+		info := &types.Info{
+			Types: make(map[ast.Expr]types.TypeAndValue),
+			Uses:  make(map[*ast.Ident]types.Object),
+		}
+		conf := types.Config{Importer: imp}
+		pkg, err := conf.Check(path, fset, []*ast.File{pgf.File}, info)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return pgf, info, pkg
+	}
+	_, _, std := check("std", stdSrc, nil)
+	pgf, info, _ := check("gno.land/r/demo/foo", src, importerFunc(func(string) (*types.Package, error) {
+		return std, nil
+	}))
+
+	index := events.Decode(events.NewIndex([]*parsego.File{pgf}, info).Encode())
+	type entry struct {
+		Kind events.Kind
+		Name string
+		Func string
+	}
+	var got []entry
+	for _, e := range index.All() {
+		got = append(got, entry{e.Kind, e.Name(), e.Func})
+	}
+	want := []entry{
+		{events.Type, "Transfer", "Transfer"},
+		{events.AttrKey, "Transfer.to", "Transfer"},
+		{events.AttrKey, "Transfer.memo", "Transfer"},
+		{events.Type, "Burn", "Burn"},
+		{events.AttrKey, "Burn.amount", "Burn"},
+		{events.AttrKey, "amount", "Burn"}, // of an event of unknown type
+		{events.Type, "Burn", "Burn"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("index entries = %v, want %v", got, want)
+	}
+}
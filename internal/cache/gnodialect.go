@@ -0,0 +1,247 @@
+package cache
+
+// This file reconciles the Go type checker with the Gno dialect:
+// it declares the identifiers that Gno predeclares in addition to
+// Go's, and reports the Go features that Gno doesn't support.
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gfanton/gnopls/internal/event"
+	"github.com/gfanton/gnopls/internal/label"
+	"github.com/gfanton/gnopls/internal/protocol"
+	"github.com/gfanton/gnopls/internal/typesinternal"
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// declareGnoUniverse declares, in the scope of pkg, the identifiers that
+// Gno predeclares in addition to those of Go:
+//
+//   - bigint and bigdec, the arbitrary precision integer and decimal
+//     types;
+//   - cross(fn) and crossing(), the builtins of interrealm calls.
+//
+// go/types has no way to extend its universe scope, so the identifiers
+// are declared in the package scope before files are checked, where
+// they are shadowed by the top-level declarations and imports of the
+// files, as they would be by a universe object.
+//
+// bigint and bigdec are approximated by aliases of int and float64:
+// they convert and operate as numbers, and their values are exported
+// and imported identically, so that those of all packages are the same
+// types. The type checker applies the bounds of the approximations to
+// constant values, and reports overflows that isBigOverflow discards.
+func declareGnoUniverse(pkg *types.Package, files []*ast.File) {
+	if pkg == types.Unsafe {
+		return
+	}
+	declared := make(map[string]bool)
+	for _, f := range files {
+		for _, spec := range f.Imports {
+			if spec.Name != nil {
+				declared[spec.Name.Name] = true
+			} else if path, err := strconv.Unquote(spec.Path.Value); err == nil {
+				declared[importName(path)] = true
+			}
+		}
+		for _, decl := range f.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				if decl.Recv == nil {
+					declared[decl.Name.Name] = true
+				}
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					switch spec := spec.(type) {
+					case *ast.TypeSpec:
+						declared[spec.Name.Name] = true
+					case *ast.ValueSpec:
+						for _, name := range spec.Names {
+							declared[name.Name] = true
+						}
+					}
+				}
+			}
+		}
+	}
+
+	scope := pkg.Scope()
+	declare := func(obj types.Object) {
+		if !declared[obj.Name()] {
+			scope.Insert(obj)
+		}
+	}
+	alias := func(name string, rhs types.Type) types.Object {
+		tname := types.NewTypeName(token.NoPos, pkg, name, nil)
+		types.NewAlias(tname, rhs)
+		return tname
+	}
+	declare(alias("bigint", types.Typ[types.Int]))
+	declare(alias("bigdec", types.Typ[types.Float64]))
+
+	// func cross[F any](fn F) F
+	anyType := types.NewInterfaceType(nil, nil).Complete()
+	tparam := types.NewTypeParam(types.NewTypeName(token.NoPos, pkg, "F", nil), anyType)
+	cross := types.NewSignatureType(nil, nil, []*types.TypeParam{tparam},
+		types.NewTuple(types.NewParam(token.NoPos, pkg, "fn", tparam)),
+		types.NewTuple(types.NewParam(token.NoPos, pkg, "", tparam)),
+		false)
+	declare(types.NewFunc(token.NoPos, pkg, "cross", cross))
+
+	// func crossing()
+	declare(types.NewFunc(token.NoPos, pkg, "crossing", types.NewSignatureType(nil, nil, nil, nil, nil, false)))
+}
+
+// isBigOverflow reports whether the type error e of the package pkg,
+// checked with info, is the overflow of a constant of the bigint or
+// bigdec type of the Gno universe (see declareGnoUniverse): Gno doesn't
+// bound them, but the type checker applies the bounds of the int and
+// float64 types that approximate them.
+//
+// The error is that of a big type if its message names one as the type
+// of the constant, or if the expression it is reported for is an
+// operand of an expression of a big type, as in bigint(1) * (1 << 100),
+// which overflows int.
+func isBigOverflow(pkg *types.Package, info *types.Info, files []*ast.File, e types.Error) bool {
+	code, start, end, ok := typesinternal.ReadGo116ErrorData(e)
+	if !ok || !strings.Contains(e.Msg, "overflows") {
+		return false
+	}
+	if code != typesinternal.NumericOverflow && code != typesinternal.InvalidConversion {
+		return false
+	}
+	if !end.IsValid() {
+		end = start
+	}
+
+	big := make(map[types.Object]bool)
+	for _, name := range []string{"bigint", "bigdec"} {
+		// A declaration of the package shadows the universe type.
+		if obj := pkg.Scope().Lookup(name); obj != nil && obj.Pkg() == pkg && !obj.Pos().IsValid() {
+			big[obj] = true
+		}
+	}
+	if len(big) == 0 {
+		return false
+	}
+	if m := bigTypeRe.FindStringSubmatch(e.Msg); m != nil && big[pkg.Scope().Lookup(m[1])] {
+		return true
+	}
+
+	isBig := func(e ast.Expr) bool {
+		alias, ok := info.TypeOf(e).(*types.Alias)
+		return ok && big[alias.Obj()]
+	}
+	for _, f := range files {
+		if start < f.FileStart || start > f.FileEnd {
+			continue
+		}
+		path, _ := astutil.PathEnclosingInterval(f, start, end)
+		for _, n := range path {
+			expr, ok := n.(ast.Expr)
+			if !ok {
+				break
+			}
+			// The type of an operation that overflows is not recorded,
+			// but that of its other operand is.
+			if isBig(expr) {
+				return true
+			}
+			if bin, ok := expr.(*ast.BinaryExpr); ok {
+				// A shift has the type of its left operand.
+				shift := bin.Op == token.SHL || bin.Op == token.SHR
+				if isBig(bin.X) || !shift && isBig(bin.Y) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// bigTypeRe matches the name of a big type of the Gno universe as the
+// type of an overflowing constant in the message of a type error.
+var bigTypeRe = regexp.MustCompile(`(?:as|overflows|of type) (big(?:int|dec))\b`)
+
+// importName returns the default name of the package imported by path,
+// for Gno paths whose last element is the package name.
+func importName(path string) string {
+	for i := len(path) - 1; i >= 0; i-- {
+		if path[i] == '/' {
+			return path[i+1:]
+		}
+	}
+	return path
+}
+
+// unsupportedPackages are the Go standard packages that have no Gno
+// equivalent.
+var unsupportedPackages = map[string]bool{
+	"reflect": true,
+	"unsafe":  true,
+}
+
+// unsupportedFeatureDiagnostics reports the constructs of the files of
+// pkg that are valid Go but are rejected by the Gno VM: goroutines,
+// select statements, channels and their operations, and imports of
+// packages such as unsafe and reflect.
+//
+// Each construct is reported once, at its own range, so nested
+// constructs (e.g. the channel receive of a select case) are not
+// reported again.
+func unsupportedFeatureDiagnostics(ctx context.Context, pkg *syntaxPackage) []*Diagnostic {
+	var diags []*Diagnostic
+	for _, pgf := range pkg.compiledGoFiles {
+		report := func(start, end token.Pos, format string, args ...any) {
+			rng, err := pgf.PosRange(start, end)
+			if err != nil {
+				event.Error(ctx, "computing unsupported feature diagnostic", err, label.Package.Of(string(pkg.id)))
+				return
+			}
+			diags = append(diags, &Diagnostic{
+				URI:      pgf.URI,
+				Range:    rng,
+				Severity: protocol.SeverityError,
+				Source:   UnsupportedFeatureError,
+				Message:  fmt.Sprintf(format, args...),
+			})
+		}
+
+		for _, spec := range pgf.File.Imports {
+			path, err := strconv.Unquote(spec.Path.Value)
+			if err != nil || !unsupportedPackages[path] {
+				continue
+			}
+			report(spec.Path.Pos(), spec.Path.End(), "package %s is not supported by Gno", path)
+		}
+
+		ast.Inspect(pgf.File, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.GoStmt:
+				report(n.Pos(), n.End(), "goroutines are not supported by Gno")
+			case *ast.SelectStmt:
+				report(n.Select, n.Select+token.Pos(len("select")), "select statements are not supported by Gno")
+			case *ast.ChanType:
+				report(n.Pos(), n.End(), "channels are not supported by Gno")
+			case *ast.SendStmt:
+				report(n.Pos(), n.End(), "channel sends are not supported by Gno")
+			case *ast.UnaryExpr:
+				if n.Op != token.ARROW {
+					return true
+				}
+				report(n.Pos(), n.End(), "channel receives are not supported by Gno")
+			default:
+				return true
+			}
+			return false
+		})
+	}
+	return diags
+}
@@ -0,0 +1,181 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"reflect"
+	"testing"
+
+	"github.com/gfanton/gnopls/internal/cache/parsego"
+	"github.com/gfanton/gnopls/internal/protocol"
+)
+
+func TestDeclareGnoUniverse(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+	}{
+		{
+			name: "builtins",
+			src: `package foo
+
+var (
+	i bigint = 1
+	d bigdec = 1.5
+	n        = int(i) + 1
+)
+
+func Transfer() {
+	crossing()
+}
+
+func Call() {
+	cross(Transfer)()
+}
+`,
+		},
+		{
+			name: "shadowed",
+			src: `package foo
+
+type bigint struct{ x int }
+
+var b = bigint{x: 1}
+
+func cross(s string) string { return s }
+
+var s string = cross("s")
+`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fset := token.NewFileSet()
+			uri := protocol.URIFromPath("/src/foo.gno")
+			pgf, _ := parsego.Parse(context.Background(), fset, uri, []byte(test.src), parsego.Full, false)
+			files := []*ast.File{pgf.File}
+
+			var errs []error
+			cfg := &types.Config{Error: func(err error) { errs = append(errs, err) }}
+			pkg := types.NewPackage("gno.land/r/demo/foo", "foo")
+			declareGnoUniverse(pkg, files)
+			_ = types.NewChecker(cfg, fset, pkg, nil).Files(files)
+			if len(errs) > 0 {
+				t.Fatalf("type errors: %v", errs)
+			}
+		})
+	}
+}
+
+func TestBigOverflow(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []string // lines of the remaining errors
+	}{
+		{
+			name: "universe",
+			src: `package foo
+
+const big = 1 << 100
+
+var (
+	a bigint = 1 << 100
+	b        = bigint(1 << 100)
+	c bigint = big
+	d        = bigint(1) << 100
+	e bigdec = 1e400
+	f int    = 1 << 100
+	g        = bigint(1) * big
+	h        = -bigint(big)
+	i        = 1 << bigint(100)
+)
+
+func F(x bigint) {}
+
+func G() { F(1 << 100) }
+`,
+			want: []string{"11", "14"},
+		},
+		{
+			name: "shadowed",
+			src: `package foo
+
+type bigint int
+
+var a bigint = 1 << 100
+`,
+			want: []string{"5"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fset := token.NewFileSet()
+			uri := protocol.URIFromPath("/src/foo.gno")
+			pgf, _ := parsego.Parse(context.Background(), fset, uri, []byte(test.src), parsego.Full, false)
+			files := []*ast.File{pgf.File}
+
+			var errs []types.Error
+			cfg := &types.Config{Error: func(err error) { errs = append(errs, err.(types.Error)) }}
+			pkg := types.NewPackage("gno.land/r/demo/foo", "foo")
+			info := &types.Info{Types: make(map[ast.Expr]types.TypeAndValue)}
+			declareGnoUniverse(pkg, files)
+			_ = types.NewChecker(cfg, fset, pkg, info).Files(files)
+
+			var got []string
+			for _, e := range errs {
+				if !isBigOverflow(pkg, info, files, e) {
+					got = append(got, fmt.Sprint(fset.Position(e.Pos).Line))
+				}
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("lines of the errors other than big overflows = %v, want %v (errors: %v)", got, test.want, errs)
+			}
+		})
+	}
+}
+
+func TestUnsupportedFeatureDiagnostics(t *testing.T) {
+	src := `package foo
+
+import (
+	"reflect"
+	"strings"
+)
+
+func f(ch chan int) {
+	go println(1)
+	select {
+	case v := <-ch:
+		println(v)
+	}
+	ch <- 1
+	_ = reflect.TypeOf(strings.ToUpper)
+}
+`
+	want := []string{
+		"4:package reflect is not supported by Gno",
+		"8:channels are not supported by Gno",
+		"9:goroutines are not supported by Gno",
+		"10:select statements are not supported by Gno",
+		"14:channel sends are not supported by Gno",
+	}
+
+	uri := protocol.URIFromPath("/src/foo.gno")
+	pgf, _ := parsego.Parse(context.Background(), token.NewFileSet(), uri, []byte(src), parsego.Full, false)
+	pkg := &syntaxPackage{compiledGoFiles: []*parsego.File{pgf}}
+	diags := unsupportedFeatureDiagnostics(context.Background(), pkg)
+
+	var got []string
+	for _, d := range diags {
+		got = append(got, fmt.Sprintf("%d:%s", d.Range.Start.Line+1, d.Message))
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unsupportedFeatureDiagnostics() = %q, want %q", got, want)
+	}
+}
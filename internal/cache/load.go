@@ -37,10 +37,13 @@ var loadID uint64 // atomic identifier for loads
 // errNoPackages indicates that a load query matched no packages.
 var errNoPackages = errors.New("no packages returned")
 
-func packagesResolver(req *packages.DriverRequest, patterns ...string) (
-	*packages.DriverResponse, error) {
-	// XXX: add recover ?
-	return resolver.Resolve(req, patterns...)
+// packagesResolver returns the packages driver resolving the packages
+// of the Gno roots of rc.
+func packagesResolver(rc resolver.Config) func(req *packages.DriverRequest, patterns ...string) (*packages.DriverResponse, error) {
+	return func(req *packages.DriverRequest, patterns ...string) (*packages.DriverResponse, error) {
+		// XXX: add recover ?
+		return rc.Resolve(req, patterns...)
+	}
 }
 
 // load calls packages.Load for the given scopes, updating package metadata,
@@ -144,7 +147,7 @@ func (s *Snapshot) load(ctx context.Context, allowNetwork bool, scopes ...loadSc
 
 	cfg := s.config(ctx, inv)
 	if bindriver := os.Getenv("GOPACKAGESDRIVER"); bindriver == "" || bindriver != "off" {
-		cfg.PackagesDriver = packagesResolver
+		cfg.PackagesDriver = packagesResolver(s.view.ResolverConfig())
 	}
 
 	pkgs, err := packages.Load(cfg, query...)
@@ -573,6 +576,19 @@ func computeLoadDiagnostics(ctx context.Context, snapshot *Snapshot, mp *metadat
 			event.Error(ctx, "unable to compute positions for list errors", err, label.Package.Of(string(mp.ID)))
 			continue
 		}
+		// The resolver reports the imports it can't resolve in the
+		// manner of the type checker: offer to fetch the package, and to
+		// require it in the gno.mod file of the importing package, in
+		// any type of view.
+		for _, diag := range pkgDiags {
+			if match := importErrorRe.FindStringSubmatch(diag.Message); match != nil {
+				modURI, _ := snapshot.GnoModForFile(ctx, diag.URI)
+				diag.SuggestedFixes = append(diag.SuggestedFixes, goGetQuickFixes(modURI != "", diag.URI, match[1])...)
+				if !bundleLazyFixes(diag) {
+					bug.Reportf("failed to bundle fixes for diagnostic %q", diag.Message)
+				}
+			}
+		}
 		diags = append(diags, pkgDiags...)
 	}
 
@@ -674,6 +690,20 @@ func isWorkspacePackageLocked(ctx context.Context, s *Snapshot, meta *metadata.G
 		return false // no non-filtered files
 	}
 
+	// In a gno.work view, workspace packages must be contained in one of the
+	// package trees used by the gno.work file, which may be outside of the
+	// workspace folder.
+	if s.view.typ == GnoWorkView {
+		for uri := range uris {
+			for _, dir := range s.view.gnoworkDirs {
+				if dir.Encloses(uri) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
 	// For non-module views (of type GOPATH or AdHoc), or if
 	// expandWorkspaceToModule is unset, workspace packages must be contained in
 	// the workspace folder.
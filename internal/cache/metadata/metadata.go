@@ -237,6 +237,28 @@ func RemoveIntermediateTestVariants(pmetas *[]*Package) {
 	*pmetas = res
 }
 
+// IsRealmPath reports whether pkgPath is the path of a Gno realm,
+// such as gno.land/r/demo/boards, whose state is persisted on chain.
+func IsRealmPath(pkgPath PackagePath) bool {
+	return pathKind(pkgPath) == "r"
+}
+
+// IsPurePath reports whether pkgPath is the path of a Gno pure
+// package, such as gno.land/p/demo/avl, which has no persistent state.
+func IsPurePath(pkgPath PackagePath) bool {
+	return pathKind(pkgPath) == "p"
+}
+
+// pathKind returns the second element of pkgPath, which distinguishes
+// realms ("r") from pure packages ("p") under a domain such as gno.land.
+func pathKind(pkgPath PackagePath) string {
+	parts := strings.SplitN(string(pkgPath), "/", 4)
+	if len(parts) < 3 || !strings.Contains(parts[0], ".") {
+		return ""
+	}
+	return parts[1]
+}
+
 // IsValidImport returns whether from may import to.
 func IsValidImport(from, to PackagePath, goList bool) bool {
 	// If the metadata came from a build system other than go list
@@ -0,0 +1,25 @@
+package metadata
+
+import "testing"
+
+func TestPathKind(t *testing.T) {
+	for _, test := range []struct {
+		path        PackagePath
+		realm, pure bool
+	}{
+		{"gno.land/r/demo/boards", true, false},
+		{"gno.land/r/gnoland", true, false},
+		{"example.com/r/foo", true, false},
+		{"gno.land/p/demo/avl", false, true},
+		{"gno.land/r", false, false},
+		{"local/r/foo", false, false}, // not under a domain
+		{"std", false, false},
+	} {
+		if got := IsRealmPath(test.path); got != test.realm {
+			t.Errorf("IsRealmPath(%q) = %t, want %t", test.path, got, test.realm)
+		}
+		if got := IsPurePath(test.path); got != test.pure {
+			t.Errorf("IsPurePath(%q) = %t, want %t", test.path, got, test.pure)
+		}
+	}
+}
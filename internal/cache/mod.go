@@ -8,6 +8,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
@@ -19,16 +20,22 @@ import (
 	"github.com/gfanton/gnopls/internal/memoize"
 	"github.com/gfanton/gnopls/internal/protocol"
 	"github.com/gfanton/gnopls/internal/protocol/command"
+	"github.com/gnolang/gno/gnovm/pkg/gnomod"
 	"golang.org/x/mod/modfile"
 	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
 )
 
-// A ParsedModule contains the results of parsing a go.mod file.
+// A ParsedModule contains the results of parsing a gno.mod file.
 type ParsedModule struct {
 	URI         protocol.DocumentURI
-	File        *modfile.File
+	File        *gnomod.File
 	Mapper      *protocol.Mapper
 	ParseErrors []*Diagnostic
+
+	// ValidationErrors holds the diagnostics of a gno.mod file that
+	// parses but is not valid, such as one without a module statement.
+	ValidationErrors []*Diagnostic
 }
 
 // ParseMod parses a go.mod file, using a cache. It may return partial results and an error.
@@ -67,7 +74,7 @@ func (s *Snapshot) ParseMod(ctx context.Context, fh file.Handle) (*ParsedModule,
 	return res.parsed, res.err
 }
 
-// parseModImpl parses the go.mod file whose name and contents are in fh.
+// parseModImpl parses the gno.mod file whose name and contents are in fh.
 // It may return partial results and an error.
 func parseModImpl(ctx context.Context, fh file.Handle) (*ParsedModule, error) {
 	_, done := event.Start(ctx, "cache.ParseMod", label.URI.Of(fh.URI()))
@@ -78,13 +85,14 @@ func parseModImpl(ctx context.Context, fh file.Handle) (*ParsedModule, error) {
 		return nil, err
 	}
 	m := protocol.NewMapper(fh.URI(), contents)
-	file, parseErr := modfile.Parse(fh.URI().Path(), contents, nil)
+	file, parseErr := gnomod.Parse(fh.URI().Path(), contents)
 	// Attempt to convert the error to a standardized parse error.
 	var parseErrors []*Diagnostic
 	if parseErr != nil {
 		mfErrList, ok := parseErr.(modfile.ErrorList)
 		if !ok {
-			return nil, fmt.Errorf("unexpected parse error type %v", parseErr)
+			// gnomod reports some errors without a position.
+			mfErrList = modfile.ErrorList{{Err: parseErr}}
 		}
 		for _, mfErr := range mfErrList {
 			rng, err := m.OffsetRange(mfErr.Pos.Byte, mfErr.Pos.Byte)
@@ -100,12 +108,109 @@ func parseModImpl(ctx context.Context, fh file.Handle) (*ParsedModule, error) {
 			})
 		}
 	}
-	return &ParsedModule{
+	pm := &ParsedModule{
 		URI:         fh.URI(),
 		Mapper:      m,
 		File:        file,
 		ParseErrors: parseErrors,
-	}, parseErr
+	}
+	if parseErr == nil {
+		pm.ValidationErrors, err = validateMod(pm)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return pm, parseErr
+}
+
+// validateMod returns the diagnostics of the validation of the parsed
+// gno.mod file pm, positioned on the offending statements.
+//
+// gnomod.File.Validate reports its errors without a position, so the
+// checks it performs are repeated here, along with the checks of the
+// require and replace statements that "gno mod" performs lazily.
+func validateMod(pm *ParsedModule) ([]*Diagnostic, error) {
+	var diags []*Diagnostic
+	report := func(line *modfile.Line, format string, args ...interface{}) error {
+		var start, end int
+		if line != nil {
+			start, end = line.Start.Byte, line.End.Byte
+		}
+		rng, err := pm.Mapper.OffsetRange(start, end)
+		if err != nil {
+			return err
+		}
+		diags = append(diags, &Diagnostic{
+			URI:      pm.URI,
+			Range:    rng,
+			Severity: protocol.SeverityError,
+			Source:   ModFileError,
+			Message:  fmt.Sprintf(format, args...),
+		})
+		return nil
+	}
+
+	mf := pm.File
+	if mf.Module == nil {
+		if err := report(nil, "gno.mod requires a module statement"); err != nil {
+			return nil, err
+		}
+	} else if err := module.CheckImportPath(mf.Module.Mod.Path); err != nil {
+		if err := report(mf.Module.Syntax, "invalid module path: %v", err); err != nil {
+			return nil, err
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, req := range mf.Require {
+		var err error
+		switch path := req.Mod.Path; {
+		case seen[path]:
+			err = report(req.Syntax, "duplicate requirement of %s", path)
+		case module.CheckImportPath(path) != nil:
+			err = report(req.Syntax, "invalid requirement: %v", module.CheckImportPath(path))
+		case !semver.IsValid(req.Mod.Version):
+			err = report(req.Syntax, "invalid version %q of %s", req.Mod.Version, path)
+		}
+		if err != nil {
+			return nil, err
+		}
+		seen[req.Mod.Path] = true
+	}
+
+	for _, rep := range mf.Replace {
+		if !modfile.IsDirectoryPath(rep.New.Path) {
+			continue
+		}
+		dir := rep.New.Path
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(filepath.Dir(pm.URI.Path()), dir)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "gno.mod")); err != nil {
+			if err := report(rep.Syntax, "replacement directory %s has no gno.mod file", rep.New.Path); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Report any other error of gnomod itself on the module statement.
+	if err := mf.Validate(); err != nil && len(diags) == 0 {
+		var line *modfile.Line
+		if mf.Module != nil {
+			line = mf.Module.Syntax
+		}
+		if err := report(line, "%v", err); err != nil {
+			return nil, err
+		}
+	}
+	return diags, nil
+}
+
+// GnoModForFile returns the URI of the gno.mod file of the package
+// containing the file uri: the nearest gno.mod file in the directory of
+// uri or one of its parents. It returns "" if there is none.
+func (s *Snapshot) GnoModForFile(ctx context.Context, uri protocol.DocumentURI) (protocol.DocumentURI, error) {
+	return findRootPattern(ctx, uri.Dir(), "gno.mod", s)
 }
 
 // A ParsedWorkFile contains the results of parsing a go.work file.
@@ -475,17 +580,12 @@ See https://github.com/golang/go/issues/39164 for more detail on this issue.`,
 	}
 }
 
-func findModuleReference(mf *modfile.File, ver module.Version) *modfile.Line {
+func findModuleReference(mf *gnomod.File, ver module.Version) *modfile.Line {
 	for _, req := range mf.Require {
 		if req.Mod == ver {
 			return req.Syntax
 		}
 	}
-	for _, ex := range mf.Exclude {
-		if ex.Mod == ver {
-			return ex.Syntax
-		}
-	}
 	for _, rep := range mf.Replace {
 		if rep.New == ver || rep.Old == ver {
 			return rep.Syntax
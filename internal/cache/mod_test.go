@@ -0,0 +1,83 @@
+package cache
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gfanton/gnopls/internal/protocol"
+	"github.com/gnolang/gno/gnovm/pkg/gnomod"
+)
+
+func TestValidateMod(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		draft   bool
+		want    []string // "line:message" of each diagnostic
+	}{
+		{
+			name:    "valid",
+			content: "module gno.land/p/demo/foo\n\nrequire gno.land/p/demo/avl v0.0.0-latest\n",
+		},
+		{
+			name:    "draft",
+			content: "// Draft\n\nmodule gno.land/r/demo/foo\n",
+			draft:   true,
+		},
+		{
+			name:    "missing module",
+			content: "require gno.land/p/demo/avl v0.0.0-latest\n",
+			want:    []string{"0:gno.mod requires a module statement"},
+		},
+		{
+			name: "duplicate require",
+			content: `module gno.land/p/demo/foo
+
+require (
+	gno.land/p/demo/avl v0.0.0-latest
+	gno.land/p/demo/avl v0.0.0-latest
+)
+`,
+			want: []string{"4:duplicate requirement of gno.land/p/demo/avl"},
+		},
+		{
+			name:    "missing replacement",
+			content: "module gno.land/p/demo/foo\n\nreplace gno.land/p/demo/avl => ./does/not/exist\n",
+			want:    []string{"2:replacement directory ./does/not/exist has no gno.mod file"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			uri := protocol.URIFromPath(t.TempDir() + "/gno.mod")
+			mf, err := gnomod.Parse(uri.Path(), []byte(test.content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if mf.Draft != test.draft {
+				t.Errorf("Draft = %t, want %t", mf.Draft, test.draft)
+			}
+			pm := &ParsedModule{
+				URI:    uri,
+				Mapper: protocol.NewMapper(uri, []byte(test.content)),
+				File:   mf,
+			}
+			diags, err := validateMod(pm)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, d := range diags {
+				got = append(got, fmt.Sprintf("%d:%s", d.Range.Start.Line, d.Message))
+			}
+			if len(got) != len(test.want) {
+				t.Fatalf("validateMod() = %q, want %q", got, test.want)
+			}
+			for i := range got {
+				if got[i] != test.want[i] {
+					t.Errorf("diagnostic %d = %q, want %q", i, got[i], test.want[i])
+				}
+			}
+		})
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"go/types"
 	"sync"
 
+	"github.com/gfanton/gnopls/internal/cache/events"
 	"github.com/gfanton/gnopls/internal/cache/metadata"
 	"github.com/gfanton/gnopls/internal/cache/methodsets"
 	"github.com/gfanton/gnopls/internal/cache/parsego"
@@ -64,6 +65,9 @@ type syntaxPackage struct {
 
 	testsOnce sync.Once
 	_tests    *testfuncs.Index // only used by the tests method
+
+	eventsOnce sync.Once
+	_events    *events.Index // only used by the events method
 }
 
 func (p *syntaxPackage) xrefs() []byte {
@@ -87,6 +91,13 @@ func (p *syntaxPackage) tests() *testfuncs.Index {
 	return p._tests
 }
 
+func (p *syntaxPackage) events() *events.Index {
+	p.eventsOnce.Do(func() {
+		p._events = events.NewIndex(p.compiledGoFiles, p.typesInfo)
+	})
+	return p._events
+}
+
 func (p *Package) String() string { return string(p.metadata.ID) }
 
 func (p *Package) Metadata() *metadata.Package { return p.metadata }
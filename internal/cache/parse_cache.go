@@ -47,6 +47,12 @@ import (
 // On 32-bit systems we don't cache parse results (see parseFiles).
 const reservedForParsing = 1 << (bits.UintSize - 4)
 
+// reservedForSharing is the start of the room, at the end of that reserved
+// for parsing, for the files of the packages shared by the views of a Cache
+// (see sharedPackages). Like cached parsed files, they are added to the
+// FileSet of each type-checking batch as they are.
+const reservedForSharing = reservedForParsing / 2
+
 // fileSetWithBase returns a new token.FileSet with Base() equal to the
 // requested base.
 //
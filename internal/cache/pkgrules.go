@@ -0,0 +1,162 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strconv"
+	"strings"
+
+	"github.com/gfanton/gnopls/internal/cache/metadata"
+	"github.com/gfanton/gnopls/internal/cache/parsego"
+	"github.com/gfanton/gnopls/internal/event"
+	"github.com/gfanton/gnopls/internal/label"
+	"github.com/gfanton/gnopls/internal/protocol"
+)
+
+// packageRuleDiagnostics reports the violations, in the files of pkg, of
+// the rules that Gno derives from package paths, and that are otherwise
+// only enforced when the package is added on chain:
+//
+//   - a pure package (gno.land/p/...) may not import a realm
+//     (gno.land/r/...);
+//   - a pure package has no persistent state: the values of its
+//     package-level variables are not saved, so it may not render them
+//     with a Render function, which is only served for realms.
+//
+// depPath returns the package path of an import of pkg.
+//
+// Test files are not subject to these rules, as they are never part of
+// the deployed package. The caller must not report the diagnostics of
+// the test variants of a package, which repeat those of the package.
+func packageRuleDiagnostics(ctx context.Context, pkg *syntaxPackage, pkgPath PackagePath, depPath func(ImportPath) PackagePath) []*Diagnostic {
+	if !metadata.IsPurePath(pkgPath) {
+		return nil
+	}
+	var (
+		files    []*parsego.File
+		hasState bool // whether the package declares package-level variables
+	)
+	for _, pgf := range pkg.compiledGoFiles {
+		name := pgf.URI.Path()
+		if strings.HasSuffix(name, "_test.gno") || strings.HasSuffix(name, "_filetest.gno") {
+			continue
+		}
+		files = append(files, pgf)
+		for _, decl := range pgf.File.Decls {
+			if gd, ok := decl.(*ast.GenDecl); ok && gd.Tok == token.VAR {
+				hasState = true
+			}
+		}
+	}
+
+	var diags []*Diagnostic
+	for _, pgf := range files {
+		for _, spec := range pgf.File.Imports {
+			path, err := strconv.Unquote(spec.Path.Value)
+			if err != nil {
+				continue // bad syntax, reported by the parser
+			}
+			if !metadata.IsRealmPath(depPath(ImportPath(path))) {
+				continue
+			}
+			diag, err := realmImportDiagnostic(pgf, spec, pkgPath, path)
+			if err != nil {
+				event.Error(ctx, "computing realm import diagnostic", err, label.Package.Of(string(pkgPath)))
+				continue
+			}
+			diags = append(diags, diag)
+		}
+		for _, decl := range pgf.File.Decls {
+			fd, ok := decl.(*ast.FuncDecl)
+			if !ok || !hasState || !isRenderDecl(fd) {
+				continue
+			}
+			rng, err := pgf.NodeRange(fd.Name)
+			if err != nil {
+				event.Error(ctx, "computing Render diagnostic", err, label.Package.Of(string(pkgPath)))
+				continue
+			}
+			diags = append(diags, &Diagnostic{
+				URI:      pgf.URI,
+				Range:    rng,
+				Severity: protocol.SeverityError,
+				Source:   PackageRuleError,
+				Message:  fmt.Sprintf("pure package %s has no persistent state to render: Render is only served for realms (gno.land/r/...)", pkgPath),
+			})
+		}
+	}
+	return diags
+}
+
+// isRenderDecl reports whether fd declares the function gnoweb renders,
+// func Render(string) string.
+func isRenderDecl(fd *ast.FuncDecl) bool {
+	if fd.Recv != nil || fd.Name.Name != "Render" || fd.Type.TypeParams != nil {
+		return false
+	}
+	isString := func(fields *ast.FieldList) bool {
+		if fields == nil || len(fields.List) != 1 || len(fields.List[0].Names) > 1 {
+			return false
+		}
+		id, ok := fields.List[0].Type.(*ast.Ident)
+		return ok && id.Name == "string"
+	}
+	return isString(fd.Type.Params) && isString(fd.Type.Results)
+}
+
+// realmImportDiagnostic returns the diagnostic for the import of the
+// realm path by spec, in the pure package pkgPath, with a quick fix that
+// removes the import.
+func realmImportDiagnostic(pgf *parsego.File, spec *ast.ImportSpec, pkgPath PackagePath, path string) (*Diagnostic, error) {
+	rng, err := pgf.NodeRange(spec.Path)
+	if err != nil {
+		return nil, err
+	}
+	start, end := importDeletionRange(pgf, spec)
+	delRng, err := pgf.PosRange(start, end)
+	if err != nil {
+		return nil, err
+	}
+	return &Diagnostic{
+		URI:      pgf.URI,
+		Range:    rng,
+		Severity: protocol.SeverityError,
+		Source:   PackageRuleError,
+		Message:  fmt.Sprintf("pure package %s cannot import realm %s", pkgPath, path),
+		SuggestedFixes: []SuggestedFix{{
+			Title: fmt.Sprintf("Remove import %q", path),
+			Edits: map[protocol.DocumentURI][]protocol.TextEdit{
+				pgf.URI: {{Range: delRng}},
+			},
+			ActionKind: protocol.QuickFix,
+		}},
+	}, nil
+}
+
+// importDeletionRange returns the range to delete to remove spec from
+// its file: the lines of the spec within a parenthesized declaration,
+// or the lines of the whole declaration if spec is its only spec.
+func importDeletionRange(pgf *parsego.File, spec *ast.ImportSpec) (token.Pos, token.Pos) {
+	start, end, doc := spec.Pos(), spec.End(), spec.Doc
+	for _, decl := range pgf.File.Decls {
+		if gd, ok := decl.(*ast.GenDecl); ok && gd.Tok == token.IMPORT && len(gd.Specs) == 1 && gd.Specs[0] == spec {
+			start, end, doc = gd.Pos(), gd.End(), gd.Doc
+			break
+		}
+	}
+	if doc != nil {
+		start = doc.Pos()
+	}
+
+	// Extend the range to whole lines, including any trailing comment.
+	tok := pgf.Tok
+	start = tok.LineStart(tok.Line(start))
+	if line := tok.Line(end); line < tok.LineCount() {
+		end = tok.LineStart(line + 1)
+	} else {
+		end = token.Pos(tok.Base() + tok.Size())
+	}
+	return start, end
+}
@@ -0,0 +1,194 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/gfanton/gnopls/internal/cache/metadata"
+	"github.com/gfanton/gnopls/internal/cache/parsego"
+	"github.com/gfanton/gnopls/internal/diff"
+	"github.com/gfanton/gnopls/internal/file"
+	"github.com/gfanton/gnopls/internal/protocol"
+	"github.com/gfanton/gnopls/internal/settings"
+)
+
+func TestPackageRuleDiagnostics(t *testing.T) {
+	tests := []struct {
+		name     string
+		pkgPath  PackagePath
+		filename string
+		src      string
+		want     []string // "line:message" of each diagnostic
+		fixed    string   // content after applying the fix of the first diagnostic
+	}{
+		{
+			name:     "realm import in group",
+			pkgPath:  "gno.land/p/demo/foo",
+			filename: "foo.gno",
+			src: `package foo
+
+import (
+	"strings"
+
+	// boards is a realm.
+	"gno.land/r/demo/boards" // bad
+)
+`,
+			want: []string{"6:pure package gno.land/p/demo/foo cannot import realm gno.land/r/demo/boards"},
+			fixed: `package foo
+
+import (
+	"strings"
+
+)
+`,
+		},
+		{
+			name:     "single realm import",
+			pkgPath:  "gno.land/p/demo/foo",
+			filename: "foo.gno",
+			src:      "package foo\n\nimport \"gno.land/r/demo/boards\"\n\nvar x = 1\n",
+			want:     []string{"2:pure package gno.land/p/demo/foo cannot import realm gno.land/r/demo/boards"},
+			fixed:    "package foo\n\n\nvar x = 1\n",
+		},
+		{
+			name:     "render",
+			pkgPath:  "gno.land/p/demo/foo",
+			filename: "foo.gno",
+			src:      "package foo\n\nvar count int\n\nfunc Render(path string) string { return \"\" }\n",
+			want:     []string{"4:pure package gno.land/p/demo/foo has no persistent state to render: Render is only served for realms (gno.land/r/...)"},
+		},
+		{
+			name:     "render without state",
+			pkgPath:  "gno.land/p/demo/foo",
+			filename: "foo.gno",
+			src:      "package foo\n\nconst title = \"foo\"\n\nfunc Render(path string) string { return title }\n",
+		},
+		{
+			name:     "other render",
+			pkgPath:  "gno.land/p/demo/foo",
+			filename: "foo.gno",
+			src:      "package foo\n\nvar count int\n\nfunc Render(w io.Writer, path string) error { return nil }\n",
+		},
+		{
+			name:     "realm",
+			pkgPath:  "gno.land/r/demo/foo",
+			filename: "foo.gno",
+			src:      "package foo\n\nimport \"gno.land/r/demo/boards\"\n\nfunc Render(path string) string { return \"\" }\n",
+		},
+		{
+			name:     "test file",
+			pkgPath:  "gno.land/p/demo/foo",
+			filename: "foo_test.gno",
+			src:      "package foo\n\nimport \"gno.land/r/demo/boards\"\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			uri := protocol.URIFromPath("/src/" + test.filename)
+			pgf, _ := parsego.Parse(context.Background(), token.NewFileSet(), uri, []byte(test.src), parsego.Full, false)
+			pkg := &syntaxPackage{compiledGoFiles: []*parsego.File{pgf}}
+			diags := packageRuleDiagnostics(context.Background(), pkg, test.pkgPath, func(path ImportPath) PackagePath {
+				return PackagePath(path)
+			})
+
+			var got []string
+			for _, d := range diags {
+				got = append(got, fmt.Sprintf("%d:%s", d.Range.Start.Line, d.Message))
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Fatalf("packageRuleDiagnostics() = %q, want %q", got, test.want)
+			}
+
+			if test.fixed == "" {
+				return
+			}
+			fixes := diags[0].SuggestedFixes
+			if len(fixes) != 1 {
+				t.Fatalf("got %d fixes, want 1", len(fixes))
+			}
+			edits, err := protocol.EditsToDiffEdits(pgf.Mapper, fixes[0].Edits[uri])
+			if err != nil {
+				t.Fatal(err)
+			}
+			fixed, err := diff.Apply(test.src, edits)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fixed != test.fixed {
+				t.Errorf("fixed content = %q, want %q", fixed, test.fixed)
+			}
+		})
+	}
+}
+
+func TestPackageRuleDiagnosticsOfTestVariants(t *testing.T) {
+	ctx := context.Background()
+	const src = "package foo\n\nvar count int\n\nfunc Render(path string) string { return \"\" }\n"
+	dir := writeFiles(t, map[string]string{
+		"gno.mod":      "module gno.land/p/demo/foo\n",
+		"foo.gno":      src,
+		"foo_test.gno": "package foo\n",
+		"gno/README":   "an empty Gno root directory",
+	})
+	opts := settings.DefaultOptions()
+	opts.GnoRoot = filepath.Join(dir, "gno")
+	opts.GnoModCache = filepath.Join(dir, "modcache")
+
+	session := NewSession(ctx, New(nil))
+	defer session.Shutdown(ctx)
+	_, _, release, err := session.NewView(ctx, &Folder{
+		Dir:     protocol.URIFromPath(dir),
+		Name:    "pkgrules",
+		Options: opts,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+
+	uri := protocol.URIFromPath(filepath.Join(dir, "foo.gno"))
+	if _, err := session.DidModifyFiles(ctx, []file.Modification{{
+		URI:        uri,
+		Action:     file.Open,
+		Version:    1,
+		Text:       []byte(src),
+		LanguageID: "gno",
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	snapshot, release, err := session.SnapshotOf(ctx, uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+	mps, err := snapshot.MetadataForFile(ctx, uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []metadata.PackageID
+	for _, mp := range mps {
+		ids = append(ids, mp.ID)
+	}
+	if len(ids) < 2 {
+		t.Fatalf("packages of %s = %v, want the package and its test variant", uri, ids)
+	}
+	diags, err := snapshot.PackageDiagnostics(ctx, ids...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, diag := range diags[uri] {
+		if diag.Source == PackageRuleError {
+			got = append(got, diag.Message)
+		}
+	}
+	if len(got) != 1 {
+		t.Errorf("package rule diagnostics of %s = %q, want one", uri, got)
+	}
+}
@@ -0,0 +1,69 @@
+package cache
+
+// This file reads the values of the package-level variables of realms,
+// as stored on chain, for hovers.
+//
+// See also:
+// - ../gnostate - reads the values from a node or a state file.
+// - ../golang/hover.go - shows the stored value of a variable.
+
+import (
+	"context"
+	"time"
+
+	"github.com/gfanton/gnopls/internal/gnostate"
+)
+
+// realmStateTimeout bounds the reading of a stored value, which must not
+// wait for an unresponsive node.
+const realmStateTimeout = 2 * time.Second
+
+// A futureStoredValue is the future result of reading a stored value.
+type futureStoredValue struct {
+	done  chan unit
+	value gnostate.Value
+	ok    bool
+	err   error
+}
+
+// StoredValue returns the value of the package-level variable name of
+// the realm pkgPath, as read from the RealmState source of the options,
+// and whether the source has it. It reports false if no source is
+// configured.
+//
+// The value is read once per snapshot, in the background: a canceled
+// request doesn't cancel the reading, whose result, including its
+// error, is kept for the next requests of the snapshot.
+func (s *Snapshot) StoredValue(ctx context.Context, pkgPath, name string) (gnostate.Value, bool, error) {
+	src := gnostate.Source(s.Options().RealmState)
+	if src == "" {
+		return gnostate.Value{}, false, nil
+	}
+
+	key := pkgPath + "." + name
+	s.realmStateMu.Lock()
+	f, ok := s.realmState[key]
+	if !ok {
+		if s.realmState == nil {
+			s.realmState = make(map[string]*futureStoredValue)
+		}
+		f = &futureStoredValue{done: make(chan unit)}
+		s.realmState[key] = f
+		go func() {
+			defer close(f.done)
+			ctx, cancel := context.WithTimeout(s.backgroundCtx, realmStateTimeout)
+			defer cancel()
+			values, err := gnostate.Values(ctx, src, pkgPath, []string{name})
+			f.value, f.ok = values[name]
+			f.err = err
+		}()
+	}
+	s.realmStateMu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return gnostate.Value{}, false, ctx.Err()
+	case <-f.done:
+		return f.value, f.ok, f.err
+	}
+}
@@ -32,6 +32,7 @@ import (
 	"github.com/gfanton/gnopls/internal/util/persistent"
 	"github.com/gfanton/gnopls/internal/vulncheck"
 	"github.com/gfanton/gnopls/internal/xcontext"
+	"github.com/gfanton/gnopls/pkg/resolver"
 )
 
 // NewSession creates a new gopls session with the given cache.
@@ -231,6 +232,7 @@ func (s *Session) createView(ctx context.Context, def *viewDefinition) (*View, *
 		initializationSema:   make(chan struct{}, 1),
 		baseCtx:              baseCtx,
 		parseCache:           s.parseCache,
+		sharedPackages:       s.cache.sharedPackages,
 		ignoreFilter:         ignoreFilter,
 		fs:                   s.overlayFS,
 		viewDefinition:       def,
@@ -633,6 +635,13 @@ func RelevantViews[V viewDefiner](ctx context.Context, fs file.Source, uri proto
 			if _, ok := def.workspaceModFiles[modURI]; ok || uri == def.gowork {
 				pushView(&workViews, view)
 			}
+		case GnoWorkView:
+			used := slices.ContainsFunc(def.gnoworkDirs, func(used protocol.DocumentURI) bool {
+				return used.Encloses(dir)
+			})
+			if used || uri == def.gnowork {
+				pushView(&workViews, view)
+			}
 		case GoModView:
 			if _, ok := def.workspaceModFiles[modURI]; ok {
 				modViews = append(modViews, view)
@@ -778,17 +787,24 @@ func (s *Session) DidModifyFiles(ctx context.Context, modifications []file.Modif
 		fh := mustReadFile(ctx, s, c.URI)
 		changed[c.URI] = fh
 
+		// The resolver reads the package directories on disk once, until
+		// they change.
+		if c.Action == file.Save || c.OnDisk {
+			resolver.Invalidate(c.URI.Path())
+		}
+
 		// Any change to the set of open files causes views to be recomputed.
 		if c.Action == file.Open || c.Action == file.Close {
 			checkViews = true
 		}
 
-		// Any on-disk change to a go.work or go.mod file causes recomputing views.
+		// Any on-disk change to a go.work, gno.work or go.mod file causes
+		// recomputing views.
 		//
 		// TODO(rfindley): go.work files need not be named "go.work" -- we need to
 		// check each view's source to handle the case of an explicit GOWORK value.
 		// Write a test that fails, and fix this.
-		if (isGoWork(c.URI) || isGoMod(c.URI)) && (c.Action == file.Save || c.OnDisk) {
+		if (isGoWork(c.URI) || IsGnoWork(c.URI) || isGoMod(c.URI)) && (c.Action == file.Save || c.OnDisk) {
 			checkViews = true
 		}
 
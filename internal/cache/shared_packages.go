@@ -0,0 +1,250 @@
+package cache
+
+// This file shares the packages of the Gno root directory, its standard
+// libraries and examples, between the views of all the sessions of a
+// Cache: a daemon serving several editors (see lsprpc.NewForwarder)
+// imports each of them once, and keeps a single copy of them in memory.
+// The packages of the workspace of each view are type-checked on top of
+// them.
+//
+// See also:
+// - ./check.go - getImportPackage imports the shared packages.
+// - ./parse_cache.go - the room in the token.Pos space of their files.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go/token"
+	"go/types"
+	"maps"
+	"sync"
+
+	"github.com/gfanton/gnopls/internal/file"
+	"github.com/gfanton/gnopls/internal/filecache"
+	"github.com/gfanton/gnopls/internal/gcimporter"
+	"github.com/gfanton/gnopls/internal/tokeninternal"
+	"github.com/gfanton/gnopls/internal/util/bug"
+	"github.com/gfanton/gnopls/internal/util/pathutil"
+)
+
+// errNotShared is returned for a package the views of a batch can't
+// share, as it or one of its dependencies isn't shareable.
+var errNotShared = errors.New("package is not shared")
+
+// sharedPackages holds the packages of the Gno root directory imported
+// by the type-checking batches of all views, by package ID. They are
+// immutable, and imported from their export data, which is type-checked
+// and recorded in the filecache if needed.
+//
+// The files of the shared packages are at bases from reservedForSharing
+// in the token.Pos space, so that each batch adds them to its FileSet as
+// they are. A package holds a single entry: when its key changes, e.g.
+// after an update of the Gno root directory, the package of the new key
+// replaces that of the old one, which is only kept by the batches that
+// imported it.
+type sharedPackages struct {
+	mu       sync.Mutex
+	packages map[PackageID]*futureShared
+
+	importMu sync.Mutex // guards base, the base of the next imported file
+	base     int
+}
+
+// A futureShared is the future result of importing a shared package.
+type futureShared struct {
+	key  file.Hash // key of the package handle
+	done chan unit
+	pkg  *sharedPackage
+	err  error
+}
+
+// A sharedPackage is a package shared by the views of a cache.
+type sharedPackage struct {
+	pkg     *types.Package
+	deps    map[PackageID]bool             // transitive dependencies
+	imports map[PackagePath]*types.Package // packages of deps
+	files   []*token.File                  // files of pkg and of its deps
+}
+
+func newSharedPackages() *sharedPackages {
+	return &sharedPackages{
+		packages: make(map[PackageID]*futureShared),
+		base:     reservedForSharing,
+	}
+}
+
+// shareable reports whether the package of ph is shared by the views of
+// the cache: a package of the standard libraries or the examples of the
+// Gno root directory, as on disk, that is not a workspace package of the
+// view of b.
+func (b *typeCheckBatch) shareable(ph *packageHandle) bool {
+	if b.shared == nil || ph.mp.ForTest != "" || ph.mp.Standalone {
+		return false
+	}
+	if _, ok := b.workspace.Value(ph.mp.ID); ok {
+		return false
+	}
+	fhs := ph.localInputs.compiledGoFiles
+	if len(fhs) == 0 {
+		return false
+	}
+	for _, fh := range fhs {
+		if _, open := fh.(*overlay); open {
+			return false
+		}
+		inRoot := false
+		for _, dir := range b.sharedRoots {
+			if pathutil.InDir(dir, fh.URI().Path()) {
+				inRoot = true
+				break
+			}
+		}
+		if !inRoot {
+			return false
+		}
+	}
+	return true
+}
+
+// get returns the shared package of ph, which must be shareable by b,
+// importing it if needed, or errNotShared if one of its dependencies is
+// not shareable by b.
+func (s *sharedPackages) get(ctx context.Context, b *typeCheckBatch, ph *packageHandle) (*sharedPackage, error) {
+	for {
+		s.mu.Lock()
+		f, ok := s.packages[ph.mp.ID]
+		if ok && f.key != ph.key {
+			ok = false // stale: replace it
+		}
+		if !ok {
+			f = &futureShared{key: ph.key, done: make(chan unit)}
+			s.packages[ph.mp.ID] = f
+		}
+		s.mu.Unlock()
+
+		if !ok {
+			f.pkg, f.err = s.importShared(ctx, b, ph)
+			if f.err != nil {
+				// The error is that of the batch, which may be canceled,
+				// or of its view: don't keep it for the others.
+				s.mu.Lock()
+				if s.packages[ph.mp.ID] == f {
+					delete(s.packages, ph.mp.ID)
+				}
+				s.mu.Unlock()
+			}
+			close(f.done)
+			return f.pkg, f.err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-f.done:
+		}
+		if f.err == nil {
+			// A package imported by another batch depends on packages
+			// this one may not share, e.g. those of its workspace.
+			for id := range f.pkg.deps {
+				if dph := b.handles[id]; dph == nil || !b.shareable(dph) {
+					return nil, errNotShared
+				}
+			}
+			return f.pkg, nil
+		}
+		// The import failed in another batch: try again in this one.
+	}
+}
+
+// importShared imports the package of ph, and the shared packages of its
+// dependencies, from its export data.
+func (s *sharedPackages) importShared(ctx context.Context, b *typeCheckBatch, ph *packageHandle) (*sharedPackage, error) {
+	sp := &sharedPackage{
+		deps:    make(map[PackageID]bool),
+		imports: make(map[PackagePath]*types.Package),
+	}
+	seen := make(map[*token.File]bool)
+	addFiles := func(files ...*token.File) {
+		for _, f := range files {
+			if !seen[f] {
+				seen[f] = true
+				sp.files = append(sp.files, f)
+			}
+		}
+	}
+	for _, id := range ph.mp.DepsByPkgPath {
+		dph := b.handles[id]
+		if dph == nil || !b.shareable(dph) {
+			return nil, errNotShared
+		}
+		dep, err := s.get(ctx, b, dph)
+		if err != nil {
+			return nil, err
+		}
+		sp.deps[id] = true
+		maps.Copy(sp.deps, dep.deps)
+		sp.imports[dph.mp.PkgPath] = dep.pkg
+		maps.Copy(sp.imports, dep.imports)
+		addFiles(dep.files...)
+	}
+
+	data, err := filecache.Get(exportDataKind, ph.key)
+	if err == filecache.ErrNotFound {
+		// Type-check the package for import in a FileSet of its own, as
+		// its files are only needed to record its export data.
+		fset := fileSetWithBase(reservedForParsing)
+		tokeninternal.AddExistingFiles(fset, sp.files)
+		var pkg *types.Package
+		pkg, err = b.checkPackageForImport(ctx, fset, ph)
+		if err != nil {
+			return nil, err
+		}
+		data, err = exportPackage(ctx, fset, ph, pkg)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache data for %s: %v", ph.mp.ID, err)
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	thisPackage := types.NewPackage(string(ph.mp.PkgPath), string(ph.mp.Name))
+	getPackages := func(items []gcimporter.GetPackagesItem) error {
+		for i, item := range items {
+			pkg := sp.imports[PackagePath(item.Path)]
+			if item.Path == string(ph.mp.PkgPath) {
+				pkg = thisPackage
+			}
+			if pkg == nil {
+				return fmt.Errorf("no shared package %q imported by %s", item.Path, ph.mp.ID)
+			}
+			if pkg.Name() != item.Name {
+				return bug.Errorf("internal error: package name is %q, want %q (id=%q, path=%q)",
+					pkg.Name(), item.Name, ph.mp.ID, item.Path)
+			}
+			items[i].Pkg = pkg
+		}
+		return nil
+	}
+
+	s.importMu.Lock()
+	defer s.importMu.Unlock()
+	fset := fileSetWithBase(s.base)
+	pkg, err := gcimporter.IImportShallow(fset, getPackages, data, string(ph.mp.PkgPath), bug.Reportf)
+	if err != nil {
+		return nil, fmt.Errorf("import failed for %q: %v", ph.mp.ID, err)
+	}
+	if fset.Base() > reservedForParsing {
+		return nil, errNotShared // out of room
+	}
+	fset.Iterate(func(f *token.File) bool {
+		if f.Base() >= s.base {
+			addFiles(f)
+		}
+		return true
+	})
+	s.base = fset.Base()
+	sp.pkg = pkg
+	return sp, nil
+}
@@ -0,0 +1,176 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"go/types"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/gfanton/gnopls/internal/cache/metadata"
+	"github.com/gfanton/gnopls/internal/file"
+	"github.com/gfanton/gnopls/internal/protocol"
+	"github.com/gfanton/gnopls/internal/util/immutable"
+)
+
+func TestSharedPackages(t *testing.T) {
+	if reservedForSharing <= 1 {
+		t.Skip("no room for shared packages")
+	}
+	ctx := context.Background()
+	dir := t.TempDir()
+	stdlibs := filepath.Join(dir, "gnovm", "stdlibs")
+	fs := newMemoizedFS()
+	salt := time.Now().String() // fresh keys, without recorded export data
+
+	// The packages: strs and fmtx in the standard libraries, the latter
+	// importing the former, and ws in the workspace, importing fmtx.
+	handles := make(map[PackageID]*packageHandle)
+	addPackage := func(root, path, src string, deps ...PackageID) {
+		fpath := filepath.Join(root, path, filepath.Base(path)+".gno")
+		if err := os.MkdirAll(filepath.Dir(fpath), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fpath, []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		uri := protocol.URIFromPath(fpath)
+		fh, err := fs.ReadFile(ctx, uri)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mp := &metadata.Package{
+			ID:              PackageID(path),
+			PkgPath:         PackagePath(path),
+			Name:            PackageName(filepath.Base(path)),
+			CompiledGoFiles: []protocol.DocumentURI{uri},
+			TypesSizes:      types.SizesFor("gc", runtime.GOARCH),
+			DepsByImpPath:   make(map[ImportPath]PackageID),
+			DepsByPkgPath:   make(map[PackagePath]PackageID),
+		}
+		for _, dep := range deps {
+			mp.DepsByImpPath[ImportPath(dep)] = dep
+			mp.DepsByPkgPath[PackagePath(dep)] = dep
+		}
+		handles[mp.ID] = &packageHandle{
+			mp: mp,
+			localInputs: typeCheckInputs{
+				id:              mp.ID,
+				pkgPath:         mp.PkgPath,
+				name:            mp.Name,
+				compiledGoFiles: []file.Handle{fh},
+				sizes:           mp.TypesSizes,
+				depsByImpPath:   mp.DepsByImpPath,
+			},
+			key: sha256.Sum256([]byte(salt + path + src)),
+		}
+	}
+	addPackage(stdlibs, "strs", `package strs
+
+type Builder struct{ s string }
+`)
+	addPackage(stdlibs, "fmtx", `package fmtx
+
+import "strs"
+
+func Sprint(b strs.Builder) string { return "" }
+`, "strs")
+	addPackage(filepath.Join(dir, "work"), "gno.land/r/demo/ws", `package ws
+
+import "fmtx"
+
+var S = fmtx.Sprint
+`, "fmtx")
+
+	shared := newSharedPackages()
+	newBatch := func(workspace ...PackageID) *typeCheckBatch {
+		ws := make(map[PackageID]PackagePath)
+		for _, id := range workspace {
+			ws[id] = PackagePath(id)
+		}
+		return &typeCheckBatch{
+			handles:        handles,
+			fset:           fileSetWithBase(reservedForParsing),
+			syntaxIndex:    make(map[PackageID]int),
+			cpulimit:       make(chan unit, 1),
+			shared:         shared,
+			sharedRoots:    []string{stdlibs},
+			workspace:      immutable.MapOf(ws),
+			syntaxPackages: make(map[PackageID]*futurePackage),
+			importPackages: make(map[PackageID]*futurePackage),
+		}
+	}
+	// imports returns the packages b imports for ws, fmtx and strs, and
+	// the position of fmtx.Sprint in its FileSet.
+	imports := func(b *typeCheckBatch) (ws, fmtx, strs *types.Package, pos string) {
+		var pkgs []*types.Package
+		for _, id := range []PackageID{"gno.land/r/demo/ws", "fmtx", "strs"} {
+			pkg, err := b.getImportPackage(ctx, id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			pkgs = append(pkgs, pkg)
+		}
+		sprint := pkgs[1].Scope().Lookup("Sprint")
+		posn := b.fset.Position(sprint.Pos())
+		return pkgs[0], pkgs[1], pkgs[2], fmt.Sprintf("%s:%d", filepath.Base(posn.Filename), posn.Line)
+	}
+
+	ws1, fmtx1, strs1, pos1 := imports(newBatch("gno.land/r/demo/ws"))
+	ws2, fmtx2, strs2, pos2 := imports(newBatch("gno.land/r/demo/ws"))
+	if ws1 == ws2 {
+		t.Errorf("workspace package is shared")
+	}
+	if fmtx1 != fmtx2 || strs1 != strs2 {
+		t.Errorf("standard libraries are not shared")
+	}
+	for _, pos := range []string{pos1, pos2} {
+		if pos != "fmtx.gno:5" {
+			t.Errorf("position of fmtx.Sprint = %s, want fmtx.gno:5", pos)
+		}
+	}
+	param := fmtx1.Scope().Lookup("Sprint").Type().(*types.Signature).Params().At(0)
+	if got := param.Type().(*types.Named).Obj().Pkg(); got != strs1 {
+		t.Errorf("fmtx imports strs %p, want the shared %p", got, strs1)
+	}
+
+	// A package of the Gno root directory in the workspace, as when
+	// working on the Gno repository, is not shared, unlike those it
+	// imports.
+	_, fmtx3, strs3, pos3 := imports(newBatch("gno.land/r/demo/ws", "fmtx"))
+	if fmtx3 == fmtx1 {
+		t.Errorf("workspace package fmtx is shared")
+	}
+	if strs3 != strs1 {
+		t.Errorf("standard library strs is not shared")
+	}
+	if pos3 != "fmtx.gno:5" {
+		t.Errorf("position of fmtx.Sprint = %s, want fmtx.gno:5", pos3)
+	}
+
+	// A change of strs replaces its shared package, and that of fmtx,
+	// whose key depends on it.
+	addPackage(stdlibs, "strs", `package strs
+
+type Builder struct{ s, sep string }
+`)
+	handles["fmtx"].key = sha256.Sum256([]byte(salt + "fmtx" + "strs changed"))
+	_, fmtx4, strs4, _ := imports(newBatch("gno.land/r/demo/ws"))
+	if strs4 == strs1 || fmtx4 == fmtx1 {
+		t.Errorf("changed standard libraries are not imported again")
+	}
+	if got := strs4.Scope().Lookup("Builder").Type().Underlying().(*types.Struct).NumFields(); got != 2 {
+		t.Errorf("changed strs.Builder has %d fields, want 2", got)
+	}
+	_, fmtx5, strs5, _ := imports(newBatch("gno.land/r/demo/ws"))
+	if fmtx5 != fmtx4 || strs5 != strs4 {
+		t.Errorf("changed standard libraries are not shared")
+	}
+	if got := len(shared.packages); got != 2 {
+		t.Errorf("%d shared packages, want 2: the old ones are not evicted", got)
+	}
+}
@@ -25,6 +25,7 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/gfanton/gnopls/internal/cache/events"
 	"github.com/gfanton/gnopls/internal/cache/metadata"
 	"github.com/gfanton/gnopls/internal/cache/methodsets"
 	"github.com/gfanton/gnopls/internal/cache/parsego"
@@ -204,6 +205,12 @@ type Snapshot struct {
 	// gcOptimizationDetails describes the packages for which we want
 	// optimization details to be included in the diagnostics.
 	gcOptimizationDetails map[metadata.PackageID]unit
+
+	// realmStateMu guards realmState, which holds the stored values of
+	// the package-level variables of realms read by StoredValue, by
+	// "pkgPath.name". They are read again by the next snapshot.
+	realmStateMu sync.Mutex
+	realmState   map[string]*futureStoredValue
 }
 
 var _ memoize.RefCounted = (*Snapshot)(nil) // snapshots are reference-counted
@@ -574,6 +581,7 @@ const (
 	xrefsKind       = "xrefs"
 	methodSetsKind  = "methodsets"
 	testsKind       = "tests"
+	eventsKind      = "events"
 	exportDataKind  = "export"
 	diagnosticsKind = "diagnostics"
 	typerefsKind    = "typerefs"
@@ -701,6 +709,70 @@ func (s *Snapshot) Tests(ctx context.Context, ids ...PackageID) ([]*testfuncs.In
 	return indexes, s.forEachPackage(ctx, ids, pre, post)
 }
 
+// Events returns the indexes of the events emitted by the specified
+// packages. There is a one-to-one correspondence between ID and Index.
+//
+// If these indexes cannot be loaded from cache, the requested packages may be
+// type-checked.
+func (s *Snapshot) Events(ctx context.Context, ids ...PackageID) ([]*events.Index, error) {
+	ctx, done := event.Start(ctx, "cache.snapshot.Events")
+	defer done()
+
+	indexes := make([]*events.Index, len(ids))
+	pre := func(i int, ph *packageHandle) bool {
+		data, err := filecache.Get(eventsKind, ph.key)
+		if err == nil { // hit
+			indexes[i] = events.Decode(data)
+			return false
+		} else if err != filecache.ErrNotFound {
+			event.Error(ctx, "reading events from filecache", err)
+		}
+		return true
+	}
+	post := func(i int, pkg *Package) {
+		indexes[i] = pkg.pkg.events()
+	}
+	return indexes, s.forEachPackage(ctx, ids, pre, post)
+}
+
+// CheckedEvents is like Events, but it doesn't type-check packages:
+// the index of a package that is neither type-checked in s nor recorded
+// in the filecache with the results of an earlier check is nil.
+func (s *Snapshot) CheckedEvents(ctx context.Context, ids ...PackageID) ([]*events.Index, error) {
+	ctx, done := event.Start(ctx, "cache.snapshot.CheckedEvents")
+	defer done()
+
+	indexes := make([]*events.Index, len(ids))
+	var needIDs []PackageID
+	for i, id := range ids {
+		if pkg := s.getActivePackage(id); pkg != nil {
+			indexes[i] = pkg.pkg.events()
+		} else {
+			needIDs = append(needIDs, id)
+		}
+	}
+	if len(needIDs) == 0 {
+		return indexes, nil
+	}
+	handles, err := s.getPackageHandles(ctx, needIDs)
+	if err != nil {
+		return nil, err
+	}
+	for i, id := range ids {
+		ph := handles[id]
+		if indexes[i] != nil || ph == nil {
+			continue
+		}
+		data, err := filecache.Get(eventsKind, ph.key)
+		if err == nil { // hit
+			indexes[i] = events.Decode(data)
+		} else if err != filecache.ErrNotFound {
+			event.Error(ctx, "reading events from filecache", err)
+		}
+	}
+	return indexes, nil
+}
+
 // MetadataForFile returns a new slice containing metadata for each
 // package containing the Go file identified by uri, ordered by the
 // number of CompiledGoFiles (i.e. "narrowest" to "widest" package),
@@ -899,6 +971,15 @@ func (s *Snapshot) fileWatchingGlobPatterns() map[protocol.RelativePattern]unit
 		patterns[workPattern] = unit{}
 	}
 
+	// Likewise for a gno.work file, which may be in a parent of the folder.
+	if s.view.gnowork != "" && !s.view.folder.Dir.Encloses(s.view.gnowork) {
+		workPattern := protocol.RelativePattern{
+			BaseURI: s.view.gnowork.Dir(),
+			Pattern: path.Base(string(s.view.gnowork)),
+		}
+		patterns[workPattern] = unit{}
+	}
+
 	extensions := "gno,mod,sum,work"
 	for _, ext := range s.Options().TemplateExtensions {
 		extensions += "," + ext
@@ -929,6 +1010,13 @@ func (s *Snapshot) fileWatchingGlobPatterns() map[protocol.RelativePattern]unit
 			// https://code.visualstudio.com/docs/editor/glob-patterns
 			patterns[protocol.RelativePattern{BaseURI: modFile.Dir(), Pattern: watchGoFiles}] = unit{}
 		}
+	} else if s.view.typ == GnoWorkView {
+		// Watch the package trees used by the gno.work file, which need not
+		// be in the workspace folder.
+		for _, dir := range s.view.gnoworkDirs {
+			dirs = append(dirs, dir.Path())
+			patterns[protocol.RelativePattern{BaseURI: dir, Pattern: watchGoFiles}] = unit{}
+		}
 	} else {
 		// In non-module modes (GOPATH or AdHoc), we just watch the workspace root.
 		dirs = []string{s.view.root.Path()}
@@ -1812,12 +1900,12 @@ func (s *Snapshot) clone(ctx, bgCtx context.Context, changed StateChange, done f
 		anyFileOpenedOrClosed = anyFileOpenedOrClosed || (oldOpen != newOpen)
 		anyPkgFileChanged = anyPkgFileChanged || (oldFH == nil || !fileExists(oldFH)) && fileExists(newFH)
 
-		// If uri is a Go file, check if it has changed in a way that would
-		// invalidate metadata. Note that we can't use s.view.FileKind here,
-		// because the file type that matters is not what the *client* tells us,
-		// but what the Go command sees.
+		// If uri is a Gno or Go file, check if it has changed in a way that
+		// would invalidate metadata. Note that we can't use s.view.FileKind
+		// here, because the file type that matters is not what the *client*
+		// tells us, but what the resolver sees.
 		var invalidateMetadata, pkgFileChanged, importDeleted bool
-		if strings.HasSuffix(uri.Path(), ".go") {
+		if strings.HasSuffix(uri.Path(), ".gno") || strings.HasSuffix(uri.Path(), ".go") {
 			invalidateMetadata, pkgFileChanged, importDeleted = metadataChanges(ctx, s, oldFH, newFH)
 		}
 		if invalidateMetadata {
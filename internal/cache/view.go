@@ -37,6 +37,7 @@ import (
 	"github.com/gfanton/gnopls/internal/util/pathutil"
 	"github.com/gfanton/gnopls/internal/vulncheck"
 	"github.com/gfanton/gnopls/internal/xcontext"
+	"github.com/gfanton/gnopls/pkg/resolver"
 )
 
 // A Folder represents an LSP workspace folder, together with its per-folder
@@ -109,6 +110,13 @@ type View struct {
 	// parseCache holds an LRU cache of recently parsed files.
 	parseCache *parseCache
 
+	// sharedPackages holds the packages of the Gno root directory shared
+	// by the views of all sessions, or is nil.
+	sharedPackages *sharedPackages
+
+	sharedRootsOnce sync.Once
+	sharedRootDirs  []string // see sharedRoots
+
 	// fs is the file source used to populate this view.
 	fs *overlayFS
 
@@ -164,6 +172,12 @@ type viewDefinition struct {
 	gomod  protocol.DocumentURI // the nearest go.mod file, or ""
 	gowork protocol.DocumentURI // the nearest go.work file, or ""
 
+	// gnowork is the nearest gno.work file, or "". For a gno.work
+	// workspace, gnoworkDirs holds the package trees named by its use
+	// directives.
+	gnowork     protocol.DocumentURI
+	gnoworkDirs []protocol.DocumentURI
+
 	// workspaceModFiles holds the set of mod files active in this snapshot.
 	//
 	// For a go.work workspace, this is the set of workspace modfiles. For a
@@ -173,7 +187,7 @@ type viewDefinition struct {
 	//
 	// TODO(rfindley): should we just run `go list -m` to compute this set?
 	workspaceModFiles    map[protocol.DocumentURI]struct{}
-	workspaceModFilesErr error // error encountered computing workspaceModFiles
+	workspaceModFilesErr error // error encountered computing workspaceModFiles or gnoworkDirs
 
 	// envOverlay holds additional environment to apply to this viewDefinition.
 	envOverlay map[string]string
@@ -195,6 +209,9 @@ func (d *viewDefinition) GoMod() protocol.DocumentURI { return d.gomod }
 // GoWork returns the nearest go.work file for this view's root, or "".
 func (d *viewDefinition) GoWork() protocol.DocumentURI { return d.gowork }
 
+// GnoWork returns the nearest gno.work file for this view's root, or "".
+func (d *viewDefinition) GnoWork() protocol.DocumentURI { return d.gnowork }
+
 // EnvOverlay returns a new sorted slice of environment variables (in the form
 // "k=v") for this view definition's env overlay.
 func (d *viewDefinition) EnvOverlay() []string {
@@ -268,7 +285,9 @@ func viewDefinitionsEqual(x, y *viewDefinition) bool {
 		x.typ == y.typ &&
 		x.root == y.root &&
 		x.gomod == y.gomod &&
-		x.gowork == y.gowork
+		x.gowork == y.gowork &&
+		x.gnowork == y.gnowork &&
+		slices.Equal(x.gnoworkDirs, y.gnoworkDirs)
 }
 
 // A ViewType describes how we load package information for a view.
@@ -309,6 +328,12 @@ const (
 	//
 	// Load: . from the workspace folder.
 	AdHocView
+
+	// GnoWorkView is a view with a gno.work file, whose use directives
+	// name several local package trees that resolve against each other.
+	//
+	// Load: <dir>/... for each used directory.
+	GnoWorkView
 )
 
 func (t ViewType) String() string {
@@ -323,6 +348,8 @@ func (t ViewType) String() string {
 		return "GoWork"
 	case AdHocView:
 		return "AdHoc"
+	case GnoWorkView:
+		return "GnoWork"
 	default:
 		return "Unknown"
 	}
@@ -351,6 +378,40 @@ func (v *View) Folder() *Folder {
 	return v.folder
 }
 
+// ResolverConfig returns the package roots the view resolves imports
+// against, from the options of its folder.
+//
+// The package trees used by the gno.work file of a GnoWorkView come
+// before the configured package roots: a load of some packages of the
+// workspace, such as a reload of a changed file, resolves their imports
+// of the others.
+func (v *View) ResolverConfig() resolver.Config {
+	opts := v.folder.Options
+	var roots []string
+	for _, dir := range v.gnoworkDirs {
+		roots = append(roots, dir.Path())
+	}
+	return resolver.Config{
+		GnoRoot:      opts.GnoRoot,
+		PackageRoots: append(roots, opts.PackageRoots...),
+		ModCache:     opts.GnoModCache,
+	}
+}
+
+// sharedRoots returns the directories of the packages the view shares
+// with the other views of the cache: the standard libraries and the
+// examples of the Gno root directory.
+func (v *View) sharedRoots() []string {
+	v.sharedRootsOnce.Do(func() {
+		for _, root := range v.ResolverConfig().Roots() {
+			if root.Kind == resolver.StdlibsRoot || root.Kind == resolver.ExamplesRoot {
+				v.sharedRootDirs = append(v.sharedRootDirs, root.Dir)
+			}
+		}
+	})
+	return v.sharedRootDirs
+}
+
 // UpdateFolders updates the set of views for the new folders.
 //
 // Calling this causes each view to be reinitialized.
@@ -628,7 +689,11 @@ func (s *Snapshot) initialize(ctx context.Context, firstAttempt bool) {
 		})
 	}
 
-	if len(s.view.workspaceModFiles) > 0 {
+	if s.view.typ == GnoWorkView {
+		for _, dir := range s.view.gnoworkDirs {
+			scopes = append(scopes, moduleLoadScope{dir: dir.Path()})
+		}
+	} else if len(s.view.workspaceModFiles) > 0 {
 		for modURI := range s.view.workspaceModFiles {
 			// Verify that the modfile is valid before trying to load it.
 			//
@@ -883,6 +948,19 @@ func defineView(ctx context.Context, fs file.Source, folder *Folder, forFile fil
 		return def, nil
 	}
 
+	// A gno.work file defines the workspace, whatever the Go modules and
+	// workspaces around it.
+	def.gnowork, err = findRootPattern(ctx, dirURI, "gno.work", fs)
+	if err != nil {
+		return nil, err
+	}
+	if def.gnowork != "" {
+		def.typ = GnoWorkView
+		def.root = def.gnowork.Dir()
+		def.gnoworkDirs, def.workspaceModFilesErr = gnoWorkDirs(ctx, def.gnowork, fs)
+		return def, nil
+	}
+
 	// From go.dev/ref/mod, module mode is active if GO111MODULE=on, or
 	// GO111MODULE=auto or "" and we are inside a module or have a GOWORK value.
 	// But gopls is less strict, allowing GOPATH mode if GO111MODULE="", and
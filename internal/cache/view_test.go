@@ -4,11 +4,16 @@
 package cache
 
 import (
+	"context"
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
 
+	"github.com/gfanton/gnopls/internal/file"
 	"github.com/gfanton/gnopls/internal/protocol"
+	"github.com/gfanton/gnopls/internal/settings"
+	"github.com/gfanton/gnopls/pkg/resolver"
 )
 
 func TestCaseInsensitiveFilesystem(t *testing.T) {
@@ -173,3 +178,170 @@ func TestIgnoreFilter(t *testing.T) {
 		}
 	}
 }
+
+func TestGnoWorkDirs(t *testing.T) {
+	dir := t.TempDir()
+	gnowork := filepath.Join(dir, "gno.work")
+	content := `go 1.22
+
+use (
+	./r/foo
+	./p/bar
+	./r/foo
+	` + filepath.ToSlash(filepath.Join(dir, "abs")) + `
+)
+`
+	if err := os.WriteFile(gnowork, []byte(content), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := gnoWorkDirs(context.Background(), protocol.URIFromPath(gnowork), newMemoizedFS())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []protocol.DocumentURI{
+		protocol.URIFromPath(filepath.Join(dir, "abs")),
+		protocol.URIFromPath(filepath.Join(dir, "p/bar")),
+		protocol.URIFromPath(filepath.Join(dir, "r/foo")),
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("gnoWorkDirs() = %v, want %v", got, want)
+	}
+}
+
+func TestGnoWorkResolverConfig(t *testing.T) {
+	ctx := context.Background()
+	dir := writeFiles(t, map[string]string{
+		"gno.work":   "go 1.22\n\nuse (\n\t./a\n\t./b\n)\n",
+		"a/gno.mod":  "module gno.land/p/demo/a\n",
+		"a/a.gno":    "package a\n\nconst A = 1\n",
+		"b/gno.mod":  "module gno.land/r/demo/b\n",
+		"b/b.gno":    "package b\n",
+		"gno/README": "an empty Gno root directory",
+	})
+	opts := settings.DefaultOptions()
+	opts.GnoRoot = filepath.Join(dir, "gno")
+	opts.GnoModCache = filepath.Join(dir, "modcache")
+	folder := &Folder{
+		Dir:     protocol.URIFromPath(dir),
+		Name:    "gnowork",
+		Options: opts,
+	}
+
+	session := NewSession(ctx, New(nil))
+	defer session.Shutdown(ctx)
+	view, snapshot, release, err := session.NewView(ctx, folder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+	if view.Type() != GnoWorkView {
+		t.Fatalf("view type = %v, want GnoWork", view.Type())
+	}
+
+	// The trees of the gno.work file come before the other package roots.
+	var roots []string
+	for _, root := range view.ResolverConfig().Roots() {
+		if root.Kind == resolver.PackageRoot {
+			roots = append(roots, root.Dir)
+		}
+	}
+	if want := []string{filepath.Join(dir, "a"), filepath.Join(dir, "b")}; !slices.Equal(roots, want) {
+		t.Errorf("package roots = %v, want %v", roots, want)
+	}
+
+	// A change of b.gno reloads its package alone, which resolves its new
+	// import of the package of another tree.
+	if _, err := snapshot.AllMetadata(ctx); err != nil {
+		t.Fatal(err)
+	}
+	uri := protocol.URIFromPath(filepath.Join(dir, "b", "b.gno"))
+	if _, err := session.DidModifyFiles(ctx, []file.Modification{{
+		URI:        uri,
+		Action:     file.Open,
+		Version:    1,
+		Text:       []byte("package b\n\nimport \"gno.land/p/demo/a\"\n\nvar B = a.A\n"),
+		LanguageID: "gno",
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	snapshot, release, err = session.SnapshotOf(ctx, uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+	mps, err := snapshot.MetadataForFile(ctx, uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mps) == 0 {
+		t.Fatalf("no package for %s", uri)
+	}
+	if _, ok := mps[0].DepsByImpPath["gno.land/p/demo/a"]; !ok {
+		t.Errorf("the import of gno.land/p/demo/a is not resolved: %v", mps[0].DepsByImpPath)
+	}
+}
+
+func TestGnoGetQuickFix(t *testing.T) {
+	ctx := context.Background()
+	dir := writeFiles(t, map[string]string{
+		"gno.mod":    "module gno.land/r/demo/foo\n",
+		"foo.gno":    "package foo\n",
+		"gno/README": "an empty Gno root directory",
+	})
+	opts := settings.DefaultOptions()
+	opts.GnoRoot = filepath.Join(dir, "gno")
+	opts.GnoModCache = filepath.Join(dir, "modcache")
+	folder := &Folder{
+		Dir:     protocol.URIFromPath(dir),
+		Name:    "gnomod",
+		Options: opts,
+	}
+
+	session := NewSession(ctx, New(nil))
+	defer session.Shutdown(ctx)
+	_, _, release, err := session.NewView(ctx, folder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+
+	// The unresolved import of a package of a workspace with a gno.mod
+	// file alone is reported with a fix to fetch the package.
+	uri := protocol.URIFromPath(filepath.Join(dir, "foo.gno"))
+	if _, err := session.DidModifyFiles(ctx, []file.Modification{{
+		URI:        uri,
+		Action:     file.Open,
+		Version:    1,
+		Text:       []byte("package foo\n\nimport \"gno.land/p/demo/missing\"\n\nvar _ = missing.X\n"),
+		LanguageID: "gno",
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	snapshot, release, err := session.SnapshotOf(ctx, uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+	mps, err := snapshot.MetadataForFile(ctx, uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mps) == 0 {
+		t.Fatalf("no package for %s", uri)
+	}
+	diags, err := snapshot.PackageDiagnostics(ctx, mps[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const want = "Fetch gno.land/p/demo/missing and add it to gno.mod"
+	var titles []string
+	for _, diag := range diags[uri] {
+		for _, fix := range diag.SuggestedFixes {
+			titles = append(titles, fix.Title)
+		}
+	}
+	if !slices.Contains(titles, want) {
+		t.Errorf("fixes of %s = %q, want %q", uri, titles, want)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"errors"
 	"fmt"
 	"path/filepath"
+	"slices"
 
 	"golang.org/x/mod/modfile"
 	"github.com/gfanton/gnopls/internal/file"
@@ -43,6 +44,41 @@ func goWorkModules(ctx context.Context, gowork protocol.DocumentURI, fs file.Sou
 	return localModFiles(dir, usedDirs), nil
 }
 
+// IsGnoWork reports if uri is a gno.work file, rather than, say, a
+// go.work file.
+func IsGnoWork(uri protocol.DocumentURI) bool {
+	return filepath.Base(uri.Path()) == "gno.work"
+}
+
+// gnoWorkDirs returns the sorted directories of the package trees named by
+// the use directives of the gno.work file. The file has the syntax of a
+// go.work file, but its directories need not contain a module.
+func gnoWorkDirs(ctx context.Context, gnowork protocol.DocumentURI, fs file.Source) ([]protocol.DocumentURI, error) {
+	fh, err := fs.ReadFile(ctx, gnowork)
+	if err != nil {
+		return nil, err // canceled
+	}
+	content, err := fh.Content()
+	if err != nil {
+		return nil, err
+	}
+	filename := gnowork.Path()
+	workFile, err := modfile.ParseWork(filename, content, nil)
+	if err != nil {
+		return nil, fmt.Errorf("parsing gno.work: %w", err)
+	}
+	var dirs []protocol.DocumentURI
+	for _, use := range workFile.Use {
+		dir := filepath.FromSlash(use.Path)
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(filepath.Dir(filename), dir)
+		}
+		dirs = append(dirs, protocol.URIFromPath(dir))
+	}
+	slices.Sort(dirs)
+	return slices.Compact(dirs), nil
+}
+
 // localModFiles builds a set of local go.mod files referenced by
 // goWorkOrModPaths, which is a slice of paths as contained in a go.work 'use'
 // directive or go.mod 'replace' directive (and which therefore may use either
@@ -68,6 +68,18 @@ func (c *callHierarchy) Run(ctx context.Context, args ...string) error {
 		return fmt.Errorf("function declaration identifier not found at %v", args[0])
 	}
 
+	if c.app.JSON {
+		var hierarchies []CallHierarchy
+		for _, item := range callItems {
+			h, err := callHierarchyOf(ctx, conn, item)
+			if err != nil {
+				return err
+			}
+			hierarchies = append(hierarchies, h)
+		}
+		return printJSON(hierarchies)
+	}
+
 	for _, item := range callItems {
 		incomingCalls, err := conn.IncomingCalls(ctx, &protocol.CallHierarchyIncomingCallsParams{Item: item})
 		if err != nil {
@@ -107,6 +119,48 @@ func (c *callHierarchy) Run(ctx context.Context, args ...string) error {
 	return nil
 }
 
+// callHierarchyOf returns the callers and callees of the function item.
+func callHierarchyOf(ctx context.Context, conn *connection, item protocol.CallHierarchyItem) (CallHierarchy, error) {
+	// calls returns the locations of the ranges of the calls in uri.
+	calls := func(uri protocol.DocumentURI, ranges []protocol.Range) []protocol.Location {
+		locs := []protocol.Location{} // an empty array, not null
+		for _, rng := range ranges {
+			locs = append(locs, protocol.Location{URI: uri, Range: rng})
+		}
+		return locs
+	}
+
+	h := CallHierarchy{
+		Name:     item.Name,
+		Location: protocol.Location{URI: item.URI, Range: item.Range},
+		Callers:  []Call{},
+		Callees:  []Call{},
+	}
+	incomingCalls, err := conn.IncomingCalls(ctx, &protocol.CallHierarchyIncomingCallsParams{Item: item})
+	if err != nil {
+		return CallHierarchy{}, err
+	}
+	for _, call := range incomingCalls {
+		h.Callers = append(h.Callers, Call{
+			Caller: call.From.Name,
+			Item:   protocol.Location{URI: call.From.URI, Range: call.From.Range},
+			Calls:  calls(call.From.URI, call.FromRanges),
+		})
+	}
+	outgoingCalls, err := conn.OutgoingCalls(ctx, &protocol.CallHierarchyOutgoingCallsParams{Item: item})
+	if err != nil {
+		return CallHierarchy{}, err
+	}
+	for _, call := range outgoingCalls {
+		h.Callees = append(h.Callees, Call{
+			Callee: call.To.Name,
+			Item:   protocol.Location{URI: call.To.URI, Range: call.To.Range},
+			Calls:  calls(item.URI, call.FromRanges),
+		})
+	}
+	return h, nil
+}
+
 // callItemPrintString returns a protocol.CallHierarchyItem object represented as a string.
 // item and call ranges (protocol.Range) are converted to user friendly spans (1-indexed).
 func callItemPrintString(ctx context.Context, conn *connection, item protocol.CallHierarchyItem, callsURI protocol.DocumentURI, calls []protocol.Range) (string, error) {
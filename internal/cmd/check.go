@@ -8,7 +8,12 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
 	"slices"
+	"sort"
+	"strings"
 
 	"github.com/gfanton/gnopls/internal/protocol"
 	"github.com/gfanton/gnopls/internal/settings"
@@ -21,13 +26,22 @@ type check struct {
 
 func (c *check) Name() string      { return "check" }
 func (c *check) Parent() string    { return c.app.Name() }
-func (c *check) Usage() string     { return "<filename>" }
+func (c *check) Usage() string     { return "<filename|dir|dir/...>..." }
 func (c *check) ShortHelp() string { return "show diagnostic results for the specified file" }
 func (c *check) DetailedHelp(f *flag.FlagSet) {
 	fmt.Fprint(f.Output(), `
 Example: show the diagnostic results of this file:
 
 	$ gopls check internal/cmd/check.go
+
+A directory argument stands for the Gno files of the directory, and
+an argument of the form dir/... for those of its whole tree, except
+the testdata directories and those whose name begins with "." or "_".
+
+With -json, the diagnostics of all files are printed as a single JSON
+array, with their related information and quick fixes:
+
+	$ gopls check -json ./...
 `)
 	printFlagDefaults(f)
 }
@@ -57,13 +71,18 @@ func (c *check) Run(ctx context.Context, args ...string) error {
 	}
 	defer conn.terminate(ctx)
 
+	filenames, err := expandFiles(args)
+	if err != nil {
+		return err
+	}
+
 	// Open and diagnose the requested files.
 	var (
 		uris     []protocol.DocumentURI
 		checking = make(map[protocol.DocumentURI]*cmdFile)
 	)
-	for _, arg := range args {
-		uri := protocol.URIFromPath(arg)
+	for _, filename := range filenames {
+		uri := protocol.URIFromPath(filename)
 		uris = append(uris, uri)
 		file, err := conn.openFile(ctx, uri)
 		if err != nil {
@@ -75,6 +94,29 @@ func (c *check) Run(ctx context.Context, args ...string) error {
 		return err
 	}
 
+	if c.app.JSON {
+		diags := []Diagnostic{} // an empty array, not null
+		for _, uri := range uris {
+			file := checking[uri]
+			file.diagnosticsMu.Lock()
+			fileDiags := slices.Clone(file.diagnostics)
+			file.diagnosticsMu.Unlock()
+
+			for _, diag := range fileDiags {
+				d := toDiagnostic(uri, diag)
+				d.Fixes, err = quickFixes(ctx, conn, uri, diag)
+				if err != nil {
+					return err
+				}
+				diags = append(diags, d)
+			}
+		}
+		sort.SliceStable(diags, func(i, j int) bool {
+			return protocol.CompareLocation(diags[i].Location, diags[j].Location) < 0
+		})
+		return printJSON(diags)
+	}
+
 	// print prints a single element of a diagnostic.
 	print := func(uri protocol.DocumentURI, rng protocol.Range, message string) error {
 		file, err := conn.openFile(ctx, uri)
@@ -108,3 +150,80 @@ func (c *check) Run(ctx context.Context, args ...string) error {
 	}
 	return nil
 }
+
+// quickFixes returns the quick fixes of the diagnostic diag of the file
+// uri that consist of edits.
+func quickFixes(ctx context.Context, conn *connection, uri protocol.DocumentURI, diag protocol.Diagnostic) ([]Fix, error) {
+	actions, err := conn.CodeAction(ctx, &protocol.CodeActionParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+		Range:        diag.Range,
+		Context: protocol.CodeActionContext{
+			Only:        []protocol.CodeActionKind{protocol.QuickFix},
+			Diagnostics: []protocol.Diagnostic{diag},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var fixes []Fix
+	for _, act := range actions {
+		if act.Disabled != nil || act.Edit == nil {
+			continue
+		}
+		// Other diagnostics in the range may have fixes too.
+		if len(act.Diagnostics) > 0 && !slices.ContainsFunc(act.Diagnostics, func(d protocol.Diagnostic) bool {
+			return d.Range == diag.Range && d.Message == diag.Message
+		}) {
+			continue
+		}
+		fixes = append(fixes, Fix{Title: act.Title, Edits: workspaceEdits(act.Edit)})
+	}
+	return fixes, nil
+}
+
+// expandFiles returns the files denoted by the arguments of the check
+// command: a file stands for itself, a directory for its Gno files, and
+// dir/... for the Gno files of the tree of dir, in the manner of the
+// package patterns of the go command.
+func expandFiles(args []string) ([]string, error) {
+	var files []string
+	for _, arg := range args {
+		if arg == "..." || strings.HasSuffix(arg, "/...") {
+			dir := filepath.Clean(strings.TrimSuffix(arg, "..."))
+			err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if d.IsDir() {
+					name := d.Name()
+					if path != dir && (name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+						return filepath.SkipDir
+					}
+					return nil
+				}
+				if strings.HasSuffix(path, ".gno") {
+					files = append(files, path)
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if info, err := os.Stat(arg); err == nil && info.IsDir() {
+			entries, err := os.ReadDir(arg)
+			if err != nil {
+				return nil, err
+			}
+			for _, e := range entries {
+				if !e.IsDir() && strings.HasSuffix(e.Name(), ".gno") {
+					files = append(files, filepath.Join(arg, e.Name()))
+				}
+			}
+			continue
+		}
+		files = append(files, arg)
+	}
+	return files, nil
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestExpandFiles(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{
+		"a/a.gno",
+		"a/a_test.gno",
+		"a/README.md",
+		"a/b/b.gno",
+		"a/testdata/t.gno",
+		"a/.hidden/h.gno",
+		"a/_skip/s.gno",
+	} {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("package a\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a := filepath.Join(root, "a")
+
+	for _, test := range []struct {
+		args []string
+		want []string
+	}{
+		{[]string{filepath.Join(a, "a.gno")}, []string{"a.gno"}},
+		{[]string{a}, []string{"a.gno", "a_test.gno"}},
+		{[]string{a + "/..."}, []string{"a.gno", "a_test.gno", "b/b.gno"}},
+	} {
+		got, err := expandFiles(test.args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var want []string
+		for _, name := range test.want {
+			want = append(want, filepath.Join(a, name))
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("expandFiles(%q) = %q, want %q", test.args, got, want)
+		}
+	}
+}
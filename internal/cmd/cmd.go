@@ -65,6 +65,9 @@ type Application struct {
 	// VeryVerbose enables a higher level of verbosity in logging output.
 	VeryVerbose bool `flag:"vv,veryverbose" help:"very verbose output"`
 
+	// JSON makes the feature commands print JSON documents.
+	JSON bool `flag:"json" help:"emit the output of feature commands as JSON documents"`
+
 	// Control ocagent export of telemetry
 	OCAgent string `flag:"ocagent" help:"the address of the ocagent (e.g. http://localhost:55678), or off"`
 
@@ -91,12 +94,31 @@ type EditFlags struct {
 	Preserve bool `flag:"preserve" help:"with -write, make copies of original files"`
 	Diff     bool `flag:"d,diff" help:"display diffs instead of edited file content"`
 	List     bool `flag:"l,list" help:"display names of edited files"`
+
+	json      bool       // print FileEdit documents instead of edited file content
+	fileEdits []FileEdit // with json, the edits of the command, printed by printEdits
 }
 
 func (app *Application) verbose() bool {
 	return app.Verbose || app.VeryVerbose
 }
 
+// setEditFlags sets the flags that control how the edits of the
+// current command are applied.
+func (app *Application) setEditFlags(flags *EditFlags) {
+	flags.json = app.JSON
+	app.editFlags = flags
+}
+
+// printEdits prints the FileEdits of the current command, if it made
+// any, as a single JSON array.
+func (app *Application) printEdits() error {
+	if app.editFlags == nil || len(app.editFlags.fileEdits) == 0 {
+		return nil
+	}
+	return printJSON(app.editFlags.fileEdits)
+}
+
 // New returns a new Application ready to run.
 func New() *Application {
 	app := &Application{
@@ -252,7 +274,15 @@ func (app *Application) Run(ctx context.Context, args ...string) error {
 	for _, c := range app.Commands() {
 		if c.Name() == command {
 			s := flag.NewFlagSet(app.Name(), flag.ExitOnError)
-			return tool.Run(ctx, s, c, args)
+			if app.isFeatureCommand(c) {
+				// Accept -json after the command name too,
+				// as in "gnopls check -json ./...".
+				s.BoolVar(&app.JSON, "json", app.JSON, "emit output as JSON documents")
+			}
+			if err := tool.Run(ctx, s, c, args); err != nil {
+				return err
+			}
+			return app.printEdits()
 		}
 	}
 	return tool.CommandLineErrorf("Unknown command %v", command)
@@ -319,6 +349,16 @@ func (app *Application) featureCommands() []tool.Application {
 	}
 }
 
+// isFeatureCommand reports whether c is one of the feature commands.
+func (app *Application) isFeatureCommand(c tool.Application) bool {
+	for _, f := range app.featureCommands() {
+		if f.Name() == c.Name() {
+			return true
+		}
+	}
+	return false
+}
+
 var (
 	internalMu          sync.Mutex
 	internalConnections = make(map[string]*connection)
@@ -544,11 +584,18 @@ func (c *cmdClient) ApplyEdit(ctx context.Context, p *protocol.ApplyWorkspaceEdi
 func (cli *cmdClient) applyWorkspaceEdit(wsedit *protocol.WorkspaceEdit) error {
 
 	create := func(uri protocol.DocumentURI, content []byte) error {
+		if flags := cli.app.editFlags; flags.json {
+			edit := Edit{Location: protocol.Location{URI: uri}, NewText: string(content)}
+			flags.fileEdits = append(flags.fileEdits, FileEdit{URI: uri, Create: true, Edits: []Edit{edit}})
+		}
 		edits := []diff.Edit{{Start: 0, End: 0, New: string(content)}}
 		return updateFile(uri.Path(), nil, content, edits, cli.app.editFlags)
 	}
 
 	delete := func(uri protocol.DocumentURI, content []byte) error {
+		if flags := cli.app.editFlags; flags.json {
+			flags.fileEdits = append(flags.fileEdits, FileEdit{URI: uri, Delete: true})
+		}
 		edits := []diff.Edit{{Start: 0, End: len(content), New: ""}}
 		return updateFile(uri.Path(), content, nil, edits, cli.app.editFlags)
 	}
@@ -610,6 +657,9 @@ func applyTextEdits(mapper *protocol.Mapper, edits []protocol.TextEdit, flags *E
 	if err != nil {
 		return err
 	}
+	if flags.json {
+		flags.fileEdits = append(flags.fileEdits, FileEdit{URI: mapper.URI, Edits: toEdits(mapper.URI, edits)})
+	}
 	return updateFile(mapper.URI.Path(), mapper.Content, newContent, diffEdits, flags)
 }
 
@@ -656,7 +706,8 @@ func updateFile(filename string, old, new []byte, edits []diff.Edit, flags *Edit
 	//
 	// This makes no sense for multiple files.
 	// (We should probably change the default to -diff.)
-	if !(flags.List || flags.Write || flags.Diff) {
+	// With -json, the edits were printed instead.
+	if !(flags.List || flags.Write || flags.Diff || flags.json) {
 		os.Stdout.Write(new)
 	}
 
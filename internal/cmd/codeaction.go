@@ -106,7 +106,7 @@ func (cmd *codeaction) Run(ctx context.Context, args ...string) error {
 	if len(args) < 1 {
 		return tool.CommandLineErrorf("codeaction expects at least 1 argument")
 	}
-	cmd.app.editFlags = &cmd.EditFlags
+	cmd.app.setEditFlags(&cmd.EditFlags)
 	conn, err := cmd.app.connect(ctx)
 	if err != nil {
 		return err
@@ -159,6 +159,7 @@ func (cmd *codeaction) Run(ctx context.Context, args ...string) error {
 
 	// Gather edits from matching code actions.
 	var edits []protocol.TextEdit
+	matches := []CodeAction{} // with -json; an empty array, not null
 	for _, act := range actions {
 		if act.Disabled != nil {
 			continue
@@ -204,6 +205,18 @@ func (cmd *codeaction) Run(ctx context.Context, args ...string) error {
 				return applyTextEdits(file.mapper, edits, cmd.app.editFlags)
 			}
 			return nil
+		} else if cmd.app.JSON {
+			// No -exec: list matching code actions.
+			match := CodeAction{
+				Title: act.Title,
+				Kind:  string(act.Kind),
+				Edits: workspaceEdits(act.Edit),
+			}
+			if act.Command != nil {
+				match.Command = act.Command.Command
+				match.Arguments = act.Command.Arguments
+			}
+			matches = append(matches, match)
 		} else {
 			// No -exec: list matching code actions.
 			action := "edit"
@@ -220,5 +233,8 @@ func (cmd *codeaction) Run(ctx context.Context, args ...string) error {
 	if cmd.Exec {
 		return fmt.Errorf("no matching code action at %s", from)
 	}
+	if cmd.app.JSON {
+		return printJSON(matches)
+	}
 	return nil
 }
@@ -66,7 +66,7 @@ func (r *codelens) Run(ctx context.Context, args ...string) error {
 		return tool.CommandLineErrorf("codelens expects at most two arguments")
 	}
 
-	r.app.editFlags = &r.EditFlags // in case a codelens perform an edit
+	r.app.setEditFlags(&r.EditFlags) // in case a codelens perform an edit
 
 	// Override the default setting for codelenses["test"], which is
 	// off by default because VS Code has a superior client-side
@@ -107,6 +107,7 @@ func (r *codelens) Run(ctx context.Context, args ...string) error {
 		return err
 	}
 
+	matches := []CodeLens{} // with -json; an empty array, not null
 	for _, lens := range lenses {
 		sp, err := file.rangeSpan(lens.Range)
 		if err != nil {
@@ -127,11 +128,23 @@ func (r *codelens) Run(ctx context.Context, args ...string) error {
 		}
 
 		// No -exec: list matching code lenses.
+		if r.app.JSON {
+			matches = append(matches, CodeLens{
+				Location:  protocol.Location{URI: loc.URI, Range: lens.Range},
+				Title:     lens.Command.Title,
+				Command:   lens.Command.Command,
+				Arguments: lens.Command.Arguments,
+			})
+			continue
+		}
 		fmt.Printf("%v: %q [%s]\n", sp, lens.Command.Title, lens.Command.Command)
 	}
 
 	if r.Exec {
 		return fmt.Errorf("no code lens at %s with title %q", filespan, title)
 	}
+	if r.app.JSON {
+		return printJSON(matches)
+	}
 	return nil
 }
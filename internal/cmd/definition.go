@@ -6,10 +6,8 @@ package cmd
 
 import (
 	"context"
-	"encoding/json"
 	"flag"
 	"fmt"
-	"os"
 	"strings"
 
 	"github.com/gfanton/gnopls/internal/protocol"
@@ -19,8 +17,9 @@ import (
 
 // A Definition is the result of a 'definition' query.
 type Definition struct {
-	Span        span   `json:"span"`        // span of the definition
-	Description string `json:"description"` // description of the denoted object
+	Span        span              `json:"span"`        // span of the definition
+	Location    protocol.Location `json:"location"`    // location of the definition
+	Description string            `json:"description"` // description of the denoted object
 }
 
 // These constant is printed in the help, and then used in a test to verify the
@@ -36,7 +35,6 @@ const (
 type definition struct {
 	app *Application
 
-	JSON              bool `flag:"json" help:"emit output in JSON format"`
 	MarkdownSupported bool `flag:"markdown" help:"support markdown in responses"`
 }
 
@@ -121,12 +119,11 @@ func (d *definition) Run(ctx context.Context, args ...string) error {
 
 	result := &Definition{
 		Span:        definition,
+		Location:    locs[0],
 		Description: description,
 	}
-	if d.JSON {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "\t")
-		return enc.Encode(result)
+	if d.app.JSON {
+		return printJSON(result)
 	}
 	fmt.Printf("%v", result.Span)
 	if len(result.Description) > 0 {
@@ -58,6 +58,9 @@ func (r *foldingRanges) Run(ctx context.Context, args ...string) error {
 		return err
 	}
 
+	if r.app.JSON {
+		return printJSON(append([]protocol.FoldingRange{}, ranges...)) // an empty array, not null
+	}
 	for _, r := range ranges {
 		fmt.Printf("%v:%v-%v:%v\n",
 			r.StartLine+1,
@@ -41,7 +41,7 @@ func (c *format) Run(ctx context.Context, args ...string) error {
 	if len(args) == 0 {
 		return nil
 	}
-	c.app.editFlags = &c.EditFlags
+	c.app.setEditFlags(&c.EditFlags)
 	conn, err := c.app.connect(ctx)
 	if err != nil {
 		return err
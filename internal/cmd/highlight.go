@@ -63,6 +63,14 @@ func (r *highlight) Run(ctx context.Context, args ...string) error {
 		return err
 	}
 
+	if r.app.JSON {
+		locs := []protocol.Location{} // an empty array, not null
+		for _, h := range highlights {
+			locs = append(locs, protocol.Location{URI: from.URI(), Range: h.Range})
+		}
+		sortLocations(locs)
+		return printJSON(locs)
+	}
 	var results []span
 	for _, h := range highlights {
 		s, err := file.rangeSpan(h.Range)
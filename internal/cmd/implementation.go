@@ -64,6 +64,11 @@ func (i *implementation) Run(ctx context.Context, args ...string) error {
 		return err
 	}
 
+	if i.app.JSON {
+		implementations = append([]protocol.Location{}, implementations...) // an empty array, not null
+		sortLocations(implementations)
+		return printJSON(implementations)
+	}
 	var spans []string
 	for _, impl := range implementations {
 		f, err := conn.openFile(ctx, impl.URI)
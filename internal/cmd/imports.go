@@ -42,7 +42,7 @@ func (t *imports) Run(ctx context.Context, args ...string) error {
 	if len(args) != 1 {
 		return tool.CommandLineErrorf("imports expects 1 argument")
 	}
-	t.app.editFlags = &t.EditFlags
+	t.app.setEditFlags(&t.EditFlags)
 	conn, err := t.app.connect(ctx)
 	if err != nil {
 		return err
@@ -0,0 +1,254 @@
+package cmd
+
+// This file defines the JSON documents printed by the feature commands
+// when the -json flag is set. Unlike their text output, these documents
+// are meant for scripts: their fields are stable, positions are those of
+// the protocol (0-based lines and UTF-16 columns), and lists are sorted.
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/gfanton/gnopls/internal/protocol"
+)
+
+// printJSON prints v to stdout as an indented JSON document.
+func printJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "\t")
+	return enc.Encode(v)
+}
+
+// A Diagnostic is a diagnostic reported by the check command.
+type Diagnostic struct {
+	Location protocol.Location    `json:"location"`
+	Severity string               `json:"severity"` // "error", "warning", "information" or "hint"
+	Source   string               `json:"source,omitempty"`
+	Code     string               `json:"code,omitempty"`
+	Message  string               `json:"message"`
+	Related  []RelatedInformation `json:"related,omitempty"`
+	Fixes    []Fix                `json:"fixes,omitempty"` // the quick fixes of the diagnostic
+}
+
+// RelatedInformation is a location related to a diagnostic.
+type RelatedInformation struct {
+	Location protocol.Location `json:"location"`
+	Message  string            `json:"message"`
+}
+
+// A Fix is a titled set of edits, such as a quick fix of a diagnostic.
+type Fix struct {
+	Title string `json:"title"`
+	Edits []Edit `json:"edits"`
+}
+
+// An Edit replaces the text at a location with NewText.
+type Edit struct {
+	Location protocol.Location `json:"location"`
+	NewText  string            `json:"newText"`
+}
+
+// A FileEdit is a change to a file, printed by the commands that edit
+// files, such as format and rename, instead of the new content of the
+// file. They print a single array of the FileEdits of the files they
+// change, if any.
+type FileEdit struct {
+	URI    protocol.DocumentURI `json:"uri"`
+	Create bool                 `json:"create,omitempty"` // the file is created, with the content of Edits
+	Delete bool                 `json:"delete,omitempty"` // the file is deleted
+	Edits  []Edit               `json:"edits,omitempty"`
+}
+
+// A Symbol is a symbol reported by the symbols and workspace_symbol
+// commands.
+type Symbol struct {
+	Name     string            `json:"name"`
+	Kind     string            `json:"kind"` // e.g. "Function"
+	Detail   string            `json:"detail,omitempty"`
+	Location protocol.Location `json:"location"` // of the name of the symbol
+	Children []Symbol          `json:"children,omitempty"`
+}
+
+// A CodeLens is a code lens reported by the codelens command.
+type CodeLens struct {
+	Location  protocol.Location `json:"location"`
+	Title     string            `json:"title"`
+	Command   string            `json:"command"`
+	Arguments []json.RawMessage `json:"arguments,omitempty"`
+}
+
+// A CodeAction is a code action reported by the codeaction command.
+type CodeAction struct {
+	Title     string            `json:"title"`
+	Kind      string            `json:"kind"`
+	Command   string            `json:"command,omitempty"`
+	Arguments []json.RawMessage `json:"arguments,omitempty"`
+	Edits     []Edit            `json:"edits,omitempty"`
+}
+
+// A Call is a call reported by the call_hierarchy command: a call of the
+// selected function by Caller, or of Callee by the selected function.
+type Call struct {
+	Caller string              `json:"caller,omitempty"`
+	Callee string              `json:"callee,omitempty"`
+	Item   protocol.Location   `json:"item"`  // of the declaration of the caller or callee
+	Calls  []protocol.Location `json:"calls"` // of the calls
+}
+
+// A CallHierarchy is the call hierarchy of a function, reported by the
+// call_hierarchy command.
+type CallHierarchy struct {
+	Name     string            `json:"name"`
+	Location protocol.Location `json:"location"`
+	Callers  []Call            `json:"callers"`
+	Callees  []Call            `json:"callees"`
+}
+
+// A Signature is the signature of the function called at a position,
+// reported by the signature command.
+type Signature struct {
+	Label         string `json:"label"`
+	Documentation string `json:"documentation,omitempty"`
+}
+
+// A SemanticToken is a token reported by the semtok command.
+type SemanticToken struct {
+	Location  protocol.Location `json:"location"`
+	Type      string            `json:"type"`
+	Modifiers []string          `json:"modifiers,omitempty"`
+}
+
+// severityNames are the names of the diagnostic severities.
+var severityNames = map[protocol.DiagnosticSeverity]string{
+	protocol.SeverityError:       "error",
+	protocol.SeverityWarning:     "warning",
+	protocol.SeverityInformation: "information",
+	protocol.SeverityHint:        "hint",
+}
+
+// severityString returns the name of a diagnostic severity, such as
+// "error". The severity of a diagnostic that has none is "error", as
+// for clients.
+func severityString(severity protocol.DiagnosticSeverity) string {
+	if name, ok := severityNames[severity]; ok {
+		return name
+	}
+	return "error"
+}
+
+// symbolKindNames are the names of the symbol kinds, as in the protocol.
+var symbolKindNames = map[protocol.SymbolKind]string{
+	protocol.File:          "File",
+	protocol.Module:        "Module",
+	protocol.Namespace:     "Namespace",
+	protocol.Package:       "Package",
+	protocol.Class:         "Class",
+	protocol.Method:        "Method",
+	protocol.Property:      "Property",
+	protocol.Field:         "Field",
+	protocol.Constructor:   "Constructor",
+	protocol.Enum:          "Enum",
+	protocol.Interface:     "Interface",
+	protocol.Function:      "Function",
+	protocol.Variable:      "Variable",
+	protocol.Constant:      "Constant",
+	protocol.String:        "String",
+	protocol.Number:        "Number",
+	protocol.Boolean:       "Boolean",
+	protocol.Array:         "Array",
+	protocol.Object:        "Object",
+	protocol.Key:           "Key",
+	protocol.Null:          "Null",
+	protocol.EnumMember:    "EnumMember",
+	protocol.Struct:        "Struct",
+	protocol.Event:         "Event",
+	protocol.Operator:      "Operator",
+	protocol.TypeParameter: "TypeParameter",
+}
+
+// symbolKindString returns the name of a symbol kind, such as
+// "Function", or its number if it is unknown.
+func symbolKindString(kind protocol.SymbolKind) string {
+	if name, ok := symbolKindNames[kind]; ok {
+		return name
+	}
+	return fmt.Sprint(uint32(kind))
+}
+
+// toDiagnostic converts a protocol diagnostic of the file uri.
+func toDiagnostic(uri protocol.DocumentURI, diag protocol.Diagnostic) Diagnostic {
+	d := Diagnostic{
+		Location: protocol.Location{URI: uri, Range: diag.Range},
+		Severity: severityString(diag.Severity),
+		Source:   diag.Source,
+		Message:  diag.Message,
+	}
+	if diag.Code != nil {
+		d.Code = fmt.Sprint(diag.Code)
+	}
+	for _, rel := range diag.RelatedInformation {
+		d.Related = append(d.Related, RelatedInformation{Location: rel.Location, Message: rel.Message})
+	}
+	return d
+}
+
+// toEdits converts the edits of the file uri.
+func toEdits(uri protocol.DocumentURI, edits []protocol.TextEdit) []Edit {
+	var res []Edit
+	for _, edit := range edits {
+		res = append(res, Edit{
+			Location: protocol.Location{URI: uri, Range: edit.Range},
+			NewText:  edit.NewText,
+		})
+	}
+	return res
+}
+
+// workspaceEdits returns the text edits of a workspace edit. Other
+// changes, such as file renamings, are ignored.
+func workspaceEdits(wsedit *protocol.WorkspaceEdit) []Edit {
+	if wsedit == nil {
+		return nil
+	}
+	var res []Edit
+	for _, c := range wsedit.DocumentChanges {
+		if tde := c.TextDocumentEdit; tde != nil {
+			res = append(res, toEdits(tde.TextDocument.URI, protocol.AsTextEdits(tde.Edits))...)
+		}
+	}
+	return res
+}
+
+// toSymbol converts a document symbol of the file uri.
+func toSymbol(uri protocol.DocumentURI, s protocol.DocumentSymbol) Symbol {
+	sym := Symbol{
+		Name:     s.Name,
+		Kind:     symbolKindString(s.Kind),
+		Detail:   s.Detail,
+		Location: protocol.Location{URI: uri, Range: s.SelectionRange},
+	}
+	for _, c := range s.Children {
+		sym.Children = append(sym.Children, toSymbol(uri, c))
+	}
+	sortSymbols(sym.Children)
+	return sym
+}
+
+// sortLocations sorts locs by file and position.
+func sortLocations(locs []protocol.Location) {
+	sort.Slice(locs, func(i, j int) bool {
+		return protocol.CompareLocation(locs[i], locs[j]) < 0
+	})
+}
+
+// sortSymbols sorts syms by position, and then by name.
+func sortSymbols(syms []Symbol) {
+	sort.SliceStable(syms, func(i, j int) bool {
+		if c := protocol.CompareLocation(syms[i].Location, syms[j].Location); c != 0 {
+			return c < 0
+		}
+		return syms[i].Name < syms[j].Name
+	})
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/gfanton/gnopls/internal/protocol"
+)
+
+func TestSeverityString(t *testing.T) {
+	for _, test := range []struct {
+		severity protocol.DiagnosticSeverity
+		want     string
+	}{
+		{protocol.SeverityError, "error"},
+		{protocol.SeverityWarning, "warning"},
+		{protocol.SeverityInformation, "information"},
+		{protocol.SeverityHint, "hint"},
+		{0, "error"}, // no severity
+	} {
+		if got := severityString(test.severity); got != test.want {
+			t.Errorf("severityString(%d) = %q, want %q", test.severity, got, test.want)
+		}
+	}
+}
+
+func TestSymbolKindString(t *testing.T) {
+	for _, test := range []struct {
+		kind protocol.SymbolKind
+		want string
+	}{
+		{protocol.File, "File"},
+		{protocol.Function, "Function"},
+		{protocol.Struct, "Struct"},
+		{protocol.TypeParameter, "TypeParameter"},
+		{99, "99"}, // unknown
+	} {
+		if got := symbolKindString(test.kind); got != test.want {
+			t.Errorf("symbolKindString(%d) = %q, want %q", test.kind, got, test.want)
+		}
+	}
+	for kind := protocol.File; kind <= protocol.TypeParameter; kind++ {
+		if _, ok := symbolKindNames[kind]; !ok {
+			t.Errorf("symbol kind %d has no name", kind)
+		}
+	}
+}
+
+func TestPrintEdits(t *testing.T) {
+	app := New()
+	app.JSON = true
+	app.setEditFlags(new(EditFlags))
+	for _, name := range []string{"/a.gno", "/b.gno"} {
+		uri := protocol.URIFromPath(name)
+		mapper := protocol.NewMapper(uri, []byte("package a\n"))
+		edits := []protocol.TextEdit{{Range: protocol.Range{End: protocol.Position{Character: 7}}, NewText: "// a\npackage"}}
+		if err := applyTextEdits(mapper, edits, app.editFlags); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The edits of both files are printed as a single array.
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = w
+	err = app.printEdits()
+	os.Stdout = stdout
+	w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []FileEdit
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("invalid output %q: %v", data, err)
+	}
+	if len(got) != 2 || got[0].URI != protocol.URIFromPath("/a.gno") || got[1].URI != protocol.URIFromPath("/b.gno") {
+		t.Errorf("printEdits() printed %s, want the edits of a.gno and b.gno", data)
+	}
+}
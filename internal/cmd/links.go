@@ -6,10 +6,8 @@ package cmd
 
 import (
 	"context"
-	"encoding/json"
 	"flag"
 	"fmt"
-	"os"
 
 	"github.com/gfanton/gnopls/internal/protocol"
 	"github.com/gfanton/gnopls/internal/tool"
@@ -17,8 +15,6 @@ import (
 
 // links implements the links verb for gopls.
 type links struct {
-	JSON bool `flag:"json" help:"emit document links in JSON format"`
-
 	app *Application
 }
 
@@ -64,10 +60,8 @@ func (l *links) Run(ctx context.Context, args ...string) error {
 	if err != nil {
 		return fmt.Errorf("%v: %v", from, err)
 	}
-	if l.JSON {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "\t")
-		return enc.Encode(results)
+	if l.app.JSON {
+		return printJSON(results)
 	}
 	for _, v := range results {
 		fmt.Println(*v.Target)
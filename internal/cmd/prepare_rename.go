@@ -69,6 +69,9 @@ func (r *prepareRename) Run(ctx context.Context, args ...string) error {
 		return ErrInvalidRenamePosition
 	}
 
+	if r.app.JSON {
+		return printJSON(protocol.Location{URI: from.URI(), Range: result.Range})
+	}
 	s, err := file.rangeSpan(result.Range)
 	if err != nil {
 		return err
@@ -68,6 +68,11 @@ func (r *references) Run(ctx context.Context, args ...string) error {
 	if err != nil {
 		return err
 	}
+	if r.app.JSON {
+		locations = append([]protocol.Location{}, locations...) // an empty array, not null
+		sortLocations(locations)
+		return printJSON(locations)
+	}
 	var spans []string
 	for _, l := range locations {
 		f, err := conn.openFile(ctx, l.URI)
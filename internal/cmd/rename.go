@@ -44,7 +44,7 @@ func (r *rename) Run(ctx context.Context, args ...string) error {
 	if len(args) != 2 {
 		return tool.CommandLineErrorf("rename expects 2 arguments (position, new name)")
 	}
-	r.app.editFlags = &r.EditFlags
+	r.app.setEditFlags(&r.EditFlags)
 	conn, err := r.app.connect(ctx)
 	if err != nil {
 		return err
@@ -97,9 +97,37 @@ func (c *semtok) Run(ctx context.Context, args ...string) error {
 	if err != nil {
 		return err
 	}
+	if c.app.JSON {
+		return printJSON(semanticTokens(uri, resp.Data))
+	}
 	return decorate(file, resp.Data)
 }
 
+// semanticTokens decodes the semantic tokens d of the file uri, encoded
+// as in the LSP: five integers per token, the first two of which are
+// relative to the previous token.
+func semanticTokens(uri protocol.DocumentURI, d []uint32) []SemanticToken {
+	tokens := []SemanticToken{} // an empty array, not null
+	var line, char uint32
+	for i := 0; 5*i+4 < len(d); i++ {
+		if d[5*i+0] > 0 {
+			char = 0
+		}
+		line += d[5*i+0]
+		char += d[5*i+1]
+		rng := protocol.Range{
+			Start: protocol.Position{Line: line, Character: char},
+			End:   protocol.Position{Line: line, Character: char + d[5*i+2]},
+		}
+		tokens = append(tokens, SemanticToken{
+			Location:  protocol.Location{URI: uri, Range: rng},
+			Type:      protocol.SemType(int(d[5*i+3])),
+			Modifiers: protocol.SemMods(int(d[5*i+4])),
+		})
+	}
+	return tokens
+}
+
 type mark struct {
 	line, offset int // 1-based, from RangeSpan
 	len          int // bytes, not runes
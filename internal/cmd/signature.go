@@ -71,16 +71,19 @@ func (r *signature) Run(ctx context.Context, args ...string) error {
 	// there is only ever one possible signature,
 	// see toProtocolSignatureHelp in lsp/signature_help.go
 	signature := s.Signatures[0]
-	fmt.Printf("%s\n", signature.Label)
+	var doc string
 	switch x := signature.Documentation.Value.(type) {
 	case string:
-		if x != "" {
-			fmt.Printf("\n%s\n", x)
-		}
+		doc = x
 	case protocol.MarkupContent:
-		if x.Value != "" {
-			fmt.Printf("\n%s\n", x.Value)
-		}
+		doc = x.Value
+	}
+	if r.app.JSON {
+		return printJSON(Signature{Label: signature.Label, Documentation: doc})
+	}
+	fmt.Printf("%s\n", signature.Label)
+	if doc != "" {
+		fmt.Printf("\n%s\n", doc)
 	}
 
 	return nil
@@ -52,6 +52,7 @@ func (r *symbols) Run(ctx context.Context, args ...string) error {
 	if err != nil {
 		return err
 	}
+	syms := []Symbol{} // an empty array, not null
 	for _, s := range symbols {
 		if m, ok := s.(map[string]interface{}); ok {
 			s, err = mapToSymbol(m)
@@ -61,11 +62,23 @@ func (r *symbols) Run(ctx context.Context, args ...string) error {
 		}
 		switch t := s.(type) {
 		case protocol.DocumentSymbol:
-			printDocumentSymbol(t)
+			if r.app.JSON {
+				syms = append(syms, toSymbol(from.URI(), t))
+			} else {
+				printDocumentSymbol(t)
+			}
 		case protocol.SymbolInformation:
-			printSymbolInformation(t)
+			if r.app.JSON {
+				syms = append(syms, Symbol{Name: t.Name, Kind: symbolKindString(t.Kind), Location: t.Location})
+			} else {
+				printSymbolInformation(t)
+			}
 		}
 	}
+	if r.app.JSON {
+		sortSymbols(syms)
+		return printJSON(syms)
+	}
 	return nil
 }
 
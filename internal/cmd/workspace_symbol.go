@@ -73,6 +73,14 @@ func (r *workspaceSymbol) Run(ctx context.Context, args ...string) error {
 	if err != nil {
 		return err
 	}
+	if r.app.JSON {
+		syms := []Symbol{} // an empty array, not null
+		for _, s := range symbols {
+			syms = append(syms, Symbol{Name: s.Name, Kind: symbolKindString(s.Kind), Location: s.Location})
+		}
+		sortSymbols(syms)
+		return printJSON(syms)
+	}
 	for _, s := range symbols {
 		f, err := conn.openFile(ctx, s.Location.URI)
 		if err != nil {
@@ -60,7 +60,7 @@ func KindForLang(langID protocol.LanguageKind) Kind {
 		return Sum
 	case "tmpl", "gotmpl":
 		return Tmpl
-	case "go.work":
+	case "gno.work", "go.work":
 		return Work
 	default:
 		return UnknownKind
@@ -0,0 +1,230 @@
+// Package filetest parses the directives of Gno filetests.
+//
+// A filetest is a _filetest.gno file: a main package run by "gno test",
+// whose comments hold directives configuring the run, such as
+//
+//	// PKGPATH: gno.land/r/demo/foo_test
+//
+// and the expected results of the run, as blocks such as
+//
+//	// Output:
+//	// hello world
+//
+// As in the gnovm, a directive is recognized at the start of a comment
+// group, and the content of a block runs until the end of the group.
+package filetest
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// A Kind is the kind of a directive.
+type Kind int
+
+const (
+	Line  Kind = iota // a single-line directive, such as "// PKGPATH: p"
+	Block             // a block directive, such as "// Output:"
+)
+
+// Directives maps the name of each directive known to the gnovm to its
+// kind.
+var Directives = map[string]Kind{
+	"PKGPATH":      Line,
+	"MAXALLOC":     Line,
+	"SEND":         Line,
+	"Output":       Block,
+	"Error":        Block,
+	"Realm":        Block,
+	"Events":       Block,
+	"Preprocessed": Block,
+	"Stacktrace":   Block,
+}
+
+// commentMarkers are the names of common comment markers, such as
+// "// TODO: ...", which are not reported as unknown directives.
+var commentMarkers = map[string]bool{
+	"BUG":   true,
+	"FIXME": true,
+	"HACK":  true,
+	"NOTE":  true,
+	"TODO":  true,
+	"XXX":   true,
+}
+
+// IsFiletest reports whether filename is the name of a filetest.
+func IsFiletest(filename string) bool {
+	return strings.HasSuffix(filename, "_filetest.gno")
+}
+
+// A Directive is a directive of a filetest.
+type Directive struct {
+	Name    string         // name of the directive, such as "Output"
+	Kind    Kind           // kind of the directive
+	Value   string         // value of a Line directive
+	Comment *ast.Comment   // comment holding the directive
+	Content []*ast.Comment // content of a Block directive
+}
+
+// NamePos returns the position of the name of the directive.
+func (d *Directive) NamePos() token.Pos {
+	return d.Comment.Pos() + token.Pos(len("// "))
+}
+
+// End returns the end of the directive, including its content.
+func (d *Directive) End() token.Pos {
+	if n := len(d.Content); n > 0 {
+		return d.Content[n-1].End()
+	}
+	return d.Comment.End()
+}
+
+// An Error describes a malformed or unknown directive.
+type Error struct {
+	Pos, End token.Pos
+	Msg      string
+}
+
+// directiveRe matches comments that look like a directive, in any
+// spacing: "//", leading space, name, space, ":", rest of the line.
+var directiveRe = regexp.MustCompile(`^//(\s*)([A-Za-z]+)(\s*):(.*)$`)
+
+// sendRe matches the coins of a SEND directive, such as "200000000ugnot".
+var sendRe = regexp.MustCompile(`^\d+[a-z][a-z0-9/]*(,\d+[a-z][a-z0-9/]*)*$`)
+
+// Parse returns the directives of the filetest f, in order, along with
+// the errors found in directive-like comments.
+func Parse(f *ast.File) (directives []*Directive, errs []*Error) {
+	// Errors are only reported for the comments written before the
+	// package clause or after the code, where directives belong: a
+	// comment within the declarations, such as a doc comment, is more
+	// likely prose than a malformed directive.
+	var declsEnd token.Pos
+	if n := len(f.Decls); n > 0 {
+		declsEnd = f.Decls[n-1].End()
+	}
+	var free bool
+	errorf := func(pos, end token.Pos, format string, args ...any) {
+		if free {
+			errs = append(errs, &Error{Pos: pos, End: end, Msg: fmt.Sprintf(format, args...)})
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, g := range f.Comments {
+		free = g.End() < f.Package || g.Pos() > declsEnd
+		c := g.List[0]
+		m := directiveRe.FindStringSubmatch(c.Text)
+		if m == nil {
+			continue
+		}
+		lead, name, space, rest := m[1], m[2], m[3], strings.TrimSpace(m[4])
+		pos := c.Pos() + token.Pos(len("//")+len(lead))
+		end := pos + token.Pos(len(name))
+
+		kind, known := Directives[name]
+		if !known {
+			if canon := lookupFold(name); canon != "" {
+				errorf(pos, end, "unknown directive %q, did you mean %q?", name, canon)
+			} else if looksLikeDirective(name, lead, rest) {
+				errorf(pos, end, "unknown directive %q", name)
+			}
+			continue
+		}
+		if lead != " " || space != "" {
+			errorf(c.Pos(), c.End(), "malformed %s directive: want %q", name, "// "+name+":")
+			continue
+		}
+
+		d := &Directive{Name: name, Kind: kind, Comment: c}
+		switch kind {
+		case Line:
+			if err := checkValue(name, rest); err != "" {
+				errorf(pos, c.End(), "%s", err)
+				continue
+			}
+			d.Value = rest
+		case Block:
+			if rest != "" {
+				errorf(pos, c.End(), "%s directive must be alone on its line, move %q to the next line", name, rest)
+				continue
+			}
+			d.Content = g.List[1:]
+		}
+		// The gnovm only looks for a directive at the start of a
+		// comment group.
+		for _, cc := range g.List[1:] {
+			if other, ok := header(cc); ok {
+				errorf(cc.Pos(), cc.End(), "%s directive is in the same comment group as the %s directive, separate them with a blank line", other, name)
+			}
+		}
+
+		if seen[name] {
+			errorf(pos, end, "duplicate %s directive", name)
+			continue
+		}
+		seen[name] = true
+		directives = append(directives, d)
+	}
+	return directives, errs
+}
+
+// checkValue returns an error message if value is not a valid value of
+// the Line directive name, or "".
+func checkValue(name, value string) string {
+	if value == "" {
+		return fmt.Sprintf("%s directive requires a value", name)
+	}
+	switch name {
+	case "MAXALLOC":
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			return fmt.Sprintf("invalid MAXALLOC value %q: want an integer", value)
+		}
+	case "SEND":
+		if !sendRe.MatchString(value) {
+			return fmt.Sprintf("invalid SEND value %q: want coins, such as 1000000ugnot", value)
+		}
+	}
+	return ""
+}
+
+// header reports whether c starts with a well-formed directive, and
+// returns its name.
+func header(c *ast.Comment) (string, bool) {
+	name, _, ok := strings.Cut(c.Text, ":")
+	if !ok {
+		return "", false
+	}
+	name, ok = strings.CutPrefix(name, "// ")
+	_, known := Directives[name]
+	return name, ok && known
+}
+
+// lookupFold returns the name of the known directive that matches name
+// under case folding, or "".
+func lookupFold(name string) string {
+	for known := range Directives {
+		if strings.EqualFold(known, name) {
+			return known
+		}
+	}
+	return ""
+}
+
+// looksLikeDirective reports whether a comment "//<lead><name>: <rest>"
+// is likely to be a misspelled directive rather than prose: a
+// capitalized block header such as "// Outptu:", or an upper-case line
+// directive such as "// PKGPAHT: p".
+func looksLikeDirective(name, lead, rest string) bool {
+	if lead != " " || commentMarkers[name] {
+		return false
+	}
+	if rest == "" {
+		return 'A' <= name[0] && name[0] <= 'Z'
+	}
+	return len(name) > 1 && strings.ToUpper(name) == name
+}
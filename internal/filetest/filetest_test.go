@@ -0,0 +1,161 @@
+package filetest
+
+import (
+	"go/parser"
+	"go/token"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name       string
+		src        string
+		directives []string // name=value, or name+content lines for blocks
+		errs       []string
+	}{
+		{
+			name: "valid",
+			src: `// PKGPATH: gno.land/r/demo/foo_test
+
+// MAXALLOC: 100000000
+
+// SEND: 1000000ugnot
+package foo_test
+
+func main() {}
+
+// Output:
+// hello
+// world
+
+// Realm:
+// switchrealm["gno.land/r/demo/foo_test"]
+`,
+			directives: []string{"PKGPATH=gno.land/r/demo/foo_test", "MAXALLOC=100000000", "SEND=1000000ugnot", "Output+2", "Realm+1"},
+		},
+		{
+			name:       "empty block",
+			src:        "package main\n\nfunc main() {}\n\n// Output:\n",
+			directives: []string{"Output+0"},
+		},
+		{
+			name: "malformed",
+			src: `package main
+
+func main() {}
+
+//Output:
+// hello
+
+// Error : boom
+
+// Realm: switchrealm
+
+// PKGPATH:
+
+// MAXALLOC: lots
+
+// SEND: 100 ugnot
+`,
+			errs: []string{
+				`malformed Output directive: want "// Output:"`,
+				`malformed Error directive: want "// Error:"`,
+				`Realm directive must be alone on its line, move "switchrealm" to the next line`,
+				`PKGPATH directive requires a value`,
+				`invalid MAXALLOC value "lots": want an integer`,
+				`invalid SEND value "100 ugnot": want coins, such as 1000000ugnot`,
+			},
+		},
+		{
+			name: "unknown",
+			src: `package main
+
+// Example: not a directive, in a doc comment.
+func main() {}
+
+// TODO: not a directive either.
+
+// output:
+// hello
+
+// Outptu:
+// hello
+
+// PKGPAHT: gno.land/r/demo/foo
+`,
+			errs: []string{
+				`unknown directive "output", did you mean "Output"?`,
+				`unknown directive "Outptu"`,
+				`unknown directive "PKGPAHT"`,
+			},
+		},
+		{
+			name: "nested and duplicate",
+			src: `// PKGPATH: gno.land/r/demo/foo
+// SEND: 1000000ugnot
+package main
+
+func main() {}
+
+// Output:
+// hello
+// Error:
+// boom
+
+// Output:
+// again
+`,
+			directives: []string{"PKGPATH=gno.land/r/demo/foo", "Output+3"},
+			errs: []string{
+				`SEND directive is in the same comment group as the PKGPATH directive, separate them with a blank line`,
+				`Error directive is in the same comment group as the Output directive, separate them with a blank line`,
+				`duplicate Output directive`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fset := token.NewFileSet()
+			f, err := parser.ParseFile(fset, "a_filetest.gno", test.src, parser.ParseComments)
+			if err != nil {
+				t.Fatal(err)
+			}
+			directives, errs := Parse(f)
+
+			var gotDirectives []string
+			for _, d := range directives {
+				if d.Kind == Line {
+					gotDirectives = append(gotDirectives, d.Name+"="+d.Value)
+				} else {
+					gotDirectives = append(gotDirectives, d.Name+"+"+string(rune('0'+len(d.Content))))
+				}
+			}
+			if !reflect.DeepEqual(gotDirectives, test.directives) {
+				t.Errorf("directives = %q, want %q", gotDirectives, test.directives)
+			}
+
+			var gotErrs []string
+			for _, e := range errs {
+				gotErrs = append(gotErrs, e.Msg)
+			}
+			if !reflect.DeepEqual(gotErrs, test.errs) {
+				t.Errorf("errors = %q, want %q", gotErrs, test.errs)
+			}
+		})
+	}
+}
+
+func TestIsFiletest(t *testing.T) {
+	for name, want := range map[string]bool{
+		"z_0_filetest.gno": true,
+		"foo_test.gno":     false,
+		"foo.gno":          false,
+		"filetest.gno":     false,
+	} {
+		if got := IsFiletest(name); got != want {
+			t.Errorf("IsFiletest(%q) = %t, want %t", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+// Package gnoestimate estimates the costs of calling a function of a Gno
+// realm, by executing it in an in-memory gnovm, in the manner of a
+// transaction calling it on chain.
+package gnoestimate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gnolang/gno/gnovm/pkg/gnoenv"
+	gno "github.com/gnolang/gno/gnovm/pkg/gnolang"
+	"github.com/gnolang/gno/gnovm/tests"
+	"github.com/gnolang/gno/tm2/pkg/crypto"
+	"github.com/gnolang/gno/tm2/pkg/std"
+	"github.com/gnolang/gno/tm2/pkg/store"
+)
+
+// maxAlloc is the allocation limit of the machine executing the call.
+const maxAlloc = 500 * 1000 * 1000
+
+// maxCycles bounds the execution of the call, so that a function stuck
+// in a loop doesn't hang the server.
+const maxCycles = 100 * 1000 * 1000
+
+// A Context is the mock context of the transaction calling a function.
+type Context struct {
+	// Caller is the bech32 address of the caller.
+	// If empty, it is that of the test context of the gnovm.
+	Caller string
+
+	// Send are the coins sent to the realm with the call.
+	Send std.Coins
+
+	// Height is the block height of the call.
+	// If zero, it is that of the test context of the gnovm.
+	Height int64
+}
+
+// Options configures the execution of a call.
+type Options struct {
+	// RootDir is the GNOROOT directory used to load the standard
+	// libraries and the examples imported by the realm.
+	// If empty, it is guessed from the environment.
+	RootDir string
+
+	// Context is the mock context of the call.
+	Context Context
+
+	// Output receives the output of the realm, such as the output of
+	// println, while it is deployed and called. It may be nil.
+	Output io.Writer
+}
+
+// Costs are the costs of a call. They exclude those of the deployment
+// of the realm.
+type Costs struct {
+	Gas       int64 `json:"gas"`       // gas consumed by the execution of the call
+	Cycles    int64 `json:"cycles"`    // CPU cycles of the gnovm
+	Alloc     int64 `json:"alloc"`     // bytes allocated by the call
+	Persisted int64 `json:"persisted"` // bytes of the objects created or updated in realms
+}
+
+// Estimate deploys memPkg, a realm, to an in-memory gnovm, calls its
+// function fn with the arguments args, which are Gno expressions, and
+// returns the costs of the call.
+//
+// The files of memPkg are used as is: callers pass the contents of the
+// editor buffers to estimate unsaved changes. Imports are resolved
+// against the standard libraries and the examples of the Gno root
+// directory.
+//
+// The storage access of the realm is not metered, so the gas is that of
+// the execution only, and the persisted bytes are the size of the JSON
+// encoding of the objects the realm writes, which approximates that of
+// their encoding in the store of a node.
+func Estimate(ctx context.Context, memPkg *std.MemPackage, fn string, args []string, opts Options) (_ Costs, err error) {
+	if opts.RootDir == "" {
+		opts.RootDir, err = gnoenv.GuessRootDir()
+		if err != nil {
+			return Costs{}, fmt.Errorf("unable to guess gno root dir: %w", err)
+		}
+	}
+	if opts.Output == nil {
+		opts.Output = io.Discard
+	}
+	if err := ctx.Err(); err != nil {
+		return Costs{}, err
+	}
+
+	defer func() {
+		// The gnovm reports most errors, including type and runtime
+		// errors of the realm, by panicking.
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic while calling %s.%s: %v", memPkg.Path, fn, r)
+		}
+	}()
+
+	execCtx := tests.TestContext(memPkg.Path, opts.Context.Send)
+	if opts.Context.Caller != "" {
+		execCtx.OrigCaller = crypto.Bech32Address(opts.Context.Caller)
+	}
+	if opts.Context.Height != 0 {
+		execCtx.Height = opts.Context.Height
+	}
+
+	gasMeter := store.NewInfiniteGasMeter()
+	gnoStore := tests.TestStore(opts.RootDir, "", new(bytes.Buffer), opts.Output, opts.Output, tests.ImportModeStdlibsOnly)
+	m := gno.NewMachineWithOptions(gno.MachineOptions{
+		PkgPath:       "",
+		Output:        opts.Output,
+		Store:         gnoStore,
+		Context:       execCtx,
+		MaxAllocBytes: maxAlloc,
+		MaxCycles:     maxCycles,
+		GasMeter:      gasMeter,
+	})
+	defer m.Release()
+
+	m.RunMemPackage(memPkg, true)
+
+	// Only the costs of the call are reported.
+	gas, cycles := gasMeter.GasConsumed(), m.Cycles
+	_, alloc := m.Alloc.Status()
+	gnoStore.SetLogStoreOps(true)
+
+	var callArgs []any
+	for _, arg := range args {
+		callArgs = append(callArgs, arg)
+	}
+	m.Eval(gno.Call(fn, callArgs...))
+
+	_, allocAfter := m.Alloc.Status()
+	return Costs{
+		Gas:       gasMeter.GasConsumed() - gas,
+		Cycles:    m.Cycles - cycles,
+		Alloc:     allocAfter - alloc,
+		Persisted: persistedBytes(gnoStore.SprintStoreOps()),
+	}, nil
+}
+
+// persistedBytes returns the size of the objects created ("c[oid]=...")
+// or updated ("u[oid]=...") in the log of store operations ops, with
+// their pretty-printed JSON compacted.
+func persistedBytes(ops string) int64 {
+	var (
+		total int64
+		obj   bytes.Buffer // JSON of the current object
+		write bool         // whether the current operation writes obj
+	)
+	flush := func() {
+		if write {
+			var compact bytes.Buffer
+			if json.Compact(&compact, obj.Bytes()) == nil {
+				total += int64(compact.Len())
+			} else {
+				total += int64(len(bytes.TrimSpace(obj.Bytes())))
+			}
+		}
+		obj.Reset()
+		write = false
+	}
+	for _, line := range strings.Split(ops, "\n") {
+		switch {
+		case strings.HasPrefix(line, "c["), strings.HasPrefix(line, "u["):
+			flush()
+			if _, body, ok := strings.Cut(line, "]="); ok {
+				write = true
+				obj.WriteString(body)
+				obj.WriteByte('\n')
+			}
+		case strings.HasPrefix(line, "d["), strings.HasPrefix(line, "switchrealm["):
+			flush()
+		default:
+			obj.WriteString(line)
+			obj.WriteByte('\n')
+		}
+	}
+	flush()
+	return total
+}
@@ -0,0 +1,43 @@
+package gnoestimate
+
+import "testing"
+
+func TestPersistedBytes(t *testing.T) {
+	// A log of store operations, as printed by the store of the gnovm.
+	const ops = `switchrealm["gno.land/r/demo/counter"]
+u[a8ada09dee16d791fd406d629fe29bb0ed084a30:2]={
+    "Blank": {},
+    "ObjectInfo": {
+        "ID": "a8ada09dee16d791fd406d629fe29bb0ed084a30:2"
+    }
+}
+c[a8ada09dee16d791fd406d629fe29bb0ed084a30:3]={
+    "Value": 1
+}
+d[a8ada09dee16d791fd406d629fe29bb0ed084a30:4]
+`
+	const (
+		updated = `{"Blank":{},"ObjectInfo":{"ID":"a8ada09dee16d791fd406d629fe29bb0ed084a30:2"}}`
+		created = `{"Value":1}`
+	)
+	if got, want := persistedBytes(ops), int64(len(updated)+len(created)); got != want {
+		t.Errorf("persistedBytes() = %d, want %d", got, want)
+	}
+	if got := persistedBytes(""); got != 0 {
+		t.Errorf("persistedBytes(\"\") = %d, want 0", got)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	costs := Costs{Gas: 1200, Cycles: 300, Alloc: 4096, Persisted: 80}
+	if got, want := Format(costs, nil), "gas 1200, cycles 300, allocated 4096 B, persisted 80 B"; got != want {
+		t.Errorf("Format(nil) = %q, want %q", got, want)
+	}
+	prev := Costs{Gas: 1000, Cycles: 300, Alloc: 4100, Persisted: 80}
+	if got, want := Format(costs, &prev), "gas 1200 (+200), cycles 300, allocated 4096 B (-4), persisted 80 B"; got != want {
+		t.Errorf("Format(prev) = %q, want %q", got, want)
+	}
+	if !Regressed(costs, prev) {
+		t.Errorf("Regressed(%v, %v) = false, want true", costs, prev)
+	}
+}
@@ -0,0 +1,81 @@
+package gnoestimate
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/gfanton/gnopls/internal/filecache"
+)
+
+// historyKind is the filecache kind of the costs of the last estimate
+// of each call.
+const historyKind = "gnoestimate"
+
+// historyKey returns the key of the costs of calling fn of the realm
+// pkgPath with args in the context ctx: the costs of a call depend on
+// all of them.
+func historyKey(pkgPath, fn string, args []string, ctx Context) [32]byte {
+	return sha256.Sum256([]byte(fmt.Sprintf("%s.%s(%s)\x00%s\x00%v\x00%d",
+		pkgPath, fn, strings.Join(args, ", "), ctx.Caller, ctx.Send, ctx.Height)))
+}
+
+// Previous returns the costs last recorded for the call, if any.
+func Previous(pkgPath, fn string, args []string, ctx Context) (Costs, bool) {
+	data, err := filecache.Get(historyKind, historyKey(pkgPath, fn, args, ctx))
+	if err != nil {
+		return Costs{}, false
+	}
+	var costs Costs
+	if err := json.Unmarshal(data, &costs); err != nil {
+		return Costs{}, false
+	}
+	return costs, true
+}
+
+// Record records the costs of the call, for later estimates of the same
+// call to report their changes.
+func Record(pkgPath, fn string, args []string, ctx Context, costs Costs) error {
+	data, err := json.Marshal(costs)
+	if err != nil {
+		return err
+	}
+	return filecache.Set(historyKind, historyKey(pkgPath, fn, args, ctx), data)
+}
+
+// Format returns a one-line description of costs, and of their changes
+// since prev, if not nil, such as "gas 1200 (+200), cycles 300, ...".
+func Format(costs Costs, prev *Costs) string {
+	values := []struct {
+		name        string
+		value, prev int64
+		unit        string
+	}{
+		{"gas", costs.Gas, 0, ""},
+		{"cycles", costs.Cycles, 0, ""},
+		{"allocated", costs.Alloc, 0, " B"},
+		{"persisted", costs.Persisted, 0, " B"},
+	}
+	if prev != nil {
+		values[0].prev, values[1].prev = prev.Gas, prev.Cycles
+		values[2].prev, values[3].prev = prev.Alloc, prev.Persisted
+	}
+	var parts []string
+	for _, v := range values {
+		part := fmt.Sprintf("%s %d%s", v.name, v.value, v.unit)
+		if prev != nil && v.value != v.prev {
+			part += fmt.Sprintf(" (%+d)", v.value-v.prev)
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Regressed reports whether any of costs exceeds those of prev.
+func Regressed(costs, prev Costs) bool {
+	return costs.Gas > prev.Gas ||
+		costs.Cycles > prev.Cycles ||
+		costs.Alloc > prev.Alloc ||
+		costs.Persisted > prev.Persisted
+}
@@ -0,0 +1,71 @@
+package gnopkg
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/gfanton/gnopls/internal/packages"
+	"github.com/gnolang/gno/gnovm/pkg/gnoenv"
+)
+
+// LoadGnoPackages loads the packages in dirs, and their dependencies
+// from the standard libraries and the examples of the Gno root
+// directory, and returns them in the form of a go/packages driver
+// response. The packages of dirs are the roots of the response.
+func LoadGnoPackages(dirs ...string) (*packages.DriverResponse, error) {
+	rootDir, err := gnoenv.GuessRootDir()
+	if err != nil {
+		return nil, fmt.Errorf("unable to guess gno root dir: %w", err)
+	}
+	l := NewLoader(
+		filepath.Join(rootDir, "gnovm", "stdlibs"),
+		filepath.Join(rootDir, "examples"),
+	)
+	for _, dir := range dirs {
+		if err := l.AddPackage(dir); err != nil {
+			return nil, err
+		}
+	}
+	return l.driverResponse(dirs)
+}
+
+// driverResponse returns the loaded packages in the form of a
+// go/packages driver response, whose roots are the packages of dirs.
+func (l *Loader) driverResponse(dirs []string) (*packages.DriverResponse, error) {
+	sorted, err := l.sortList()
+	if err != nil {
+		return nil, err
+	}
+
+	res := &packages.DriverResponse{}
+	gopkgs := make(map[*Package]*packages.Package, len(sorted))
+	for _, lp := range sorted {
+		// Dependencies come first: their packages are already
+		// converted.
+		imports := make(map[string]*packages.Package, len(lp.childrenDeps))
+		for path, child := range lp.childrenDeps {
+			imports[string(path)] = gopkgs[child]
+		}
+		gopkg := &packages.Package{
+			ID:              lp.Dir,
+			Name:            lp.mempkg.Name,
+			PkgPath:         string(lp.Path()),
+			GoFiles:         lp.Files,
+			CompiledGoFiles: lp.Files,
+			Imports:         imports,
+		}
+		gopkgs[lp] = gopkg
+		res.Packages = append(res.Packages, gopkg)
+	}
+
+	for _, dir := range dirs {
+		dir, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, err
+		}
+		if lp, ok := l.dirs[filepath.Clean(dir)]; ok {
+			res.Roots = append(res.Roots, gopkgs[lp].ID)
+		}
+	}
+	return res, nil
+}
@@ -1,3 +1,5 @@
+// Package gnopkg loads Gno packages from source, along with their
+// dependencies, in the order in which a gnovm must run them.
 package gnopkg
 
 import (
@@ -6,192 +8,433 @@ import (
 	"go/token"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/gnolang/gno/gnovm/pkg/gnomod"
 	"github.com/gnolang/gno/tm2/pkg/std"
 )
 
+// A PackagePath is the path of a Gno package, such as
+// "gno.land/p/demo/avl" or "strings".
+type PackagePath string
+
+// A PackageLoader loads Gno packages and their dependencies, and keeps
+// them up to date with the changes of their files.
 type PackageLoader interface {
-	AddPackage(path string) error
-	Update(path string) error
-	LoadPackages() []*PackagePath
+	// AddPackage loads the package in dir, and its dependencies.
+	AddPackage(dir string) error
+
+	// Update reads again the files of the package in dir, which must
+	// have been loaded, and loads its new dependencies.
+	Update(dir string) error
+
+	// LoadPackages returns all loaded packages, each one after its
+	// dependencies.
+	LoadPackages() ([]*std.MemPackage, error)
+
+	// Invalidate updates the package of the edited file, and returns
+	// the paths of the packages affected by the edit: the package
+	// itself and the packages importing it, directly or not.
+	Invalidate(file string) []PackagePath
 }
 
+// A Package is a package loaded by a Loader.
 type Package struct {
-	update       int
-	parentsDeps  map[string]*Package
-	childrenDeps map[string]*Package
-	mempkg       std.MemPackage
+	Dir   string   // absolute directory of the package
+	Files []string // absolute paths of the .gno files, without tests
+
+	parentsDeps  map[PackagePath]*Package // packages importing this one
+	childrenDeps map[PackagePath]*Package // packages imported by this one
+	mempkg       *std.MemPackage
 }
 
+// Path returns the package path of p.
+func (p *Package) Path() PackagePath { return PackagePath(p.mempkg.Path) }
+
+// MemPackage returns the files of p, as run by a gnovm.
+func (p *Package) MemPackage() *std.MemPackage { return p.mempkg }
+
+// Loader is the PackageLoader of the packages of a set of directories,
+// and of the packages they import.
+//
+// Imports are resolved, in order, against the packages already loaded,
+// then against the root directories: the import of "gno.land/p/demo/avl"
+// is the package in the first root that has a gno.land/p/demo/avl
+// subdirectory. Packages added with AddPackage should thus be added
+// before the packages importing them.
 type Loader struct {
-	update int
-	fset   *token.FileSet
-	roots  []string // Root folder
-	pkgs   map[string]*Package
+	fset  *token.FileSet
+	roots []string                 // root directories of imports
+	pkgs  map[PackagePath]*Package // by package path
+	dirs  map[string]*Package      // by directory
 }
 
-func (l *Loader) AddPackage(path string) {
-	return
+var _ PackageLoader = (*Loader)(nil)
+
+// NewLoader returns a Loader resolving imports in the given root
+// directories, such as $GNOROOT/gnovm/stdlibs and $GNOROOT/examples.
+func NewLoader(roots ...string) *Loader {
+	return &Loader{
+		fset:  token.NewFileSet(),
+		roots: roots,
+		pkgs:  make(map[PackagePath]*Package),
+		dirs:  make(map[string]*Package),
+	}
 }
 
-func (l *Loader) LoadPackages() []*PackagePath {
-	return nil
+func (l *Loader) AddPackage(dir string) error {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("unable to determine absolute path of %q: %w", dir, err)
+	}
+	_, err = l.loadPackage(dir, "")
+	return err
 }
 
-func (l *Loader) Invalidate(path string) []*PackagePath {
-	return nil
+func (l *Loader) Update(dir string) error {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("unable to determine absolute path of %q: %w", dir, err)
+	}
+	lp, ok := l.dirs[dir]
+	if !ok {
+		return fmt.Errorf("package %q is not loaded", dir)
+	}
+	return l.readPackage(lp, lp.Path())
 }
 
-// Resolve path string -> Package
-// Package -> resolve Import
-// Import Resolve path
+func (l *Loader) LoadPackages() ([]*std.MemPackage, error) {
+	sorted, err := l.sortList()
+	if err != nil {
+		return nil, err
+	}
+	mempkgs := make([]*std.MemPackage, len(sorted))
+	for i, lp := range sorted {
+		mempkgs[i] = lp.mempkg
+	}
+	return mempkgs, nil
+}
 
-func (l *Loader) getPkg(path string) *Package {
-	lp, ok := l.pkgs[path]
+func (l *Loader) Invalidate(file string) []PackagePath {
+	file, err := filepath.Abs(file)
+	if err != nil {
+		return nil
+	}
+	name := filepath.Base(file)
+	if !isGnoFile(name) || isTestFile(name) {
+		return nil // not part of any MemPackage
+	}
+	lp, ok := l.dirs[filepath.Dir(file)]
 	if !ok {
-		lp := &Package{
-			parentsDeps:  map[string]*Package{},
-			childrenDeps: map[string]*Package{},
+		return nil
+	}
+
+	// Errors leave the package as it was: it is still affected, and
+	// the error is reported again by the next Update.
+	_ = l.readPackage(lp, lp.Path())
+
+	affected := map[PackagePath]bool{}
+	var visit func(p *Package)
+	visit = func(p *Package) {
+		if affected[p.Path()] {
+			return
+		}
+		affected[p.Path()] = true
+		for _, parent := range p.parentsDeps {
+			visit(parent)
 		}
-		l.pkgs[path] = lp
 	}
-	return lp
+	visit(lp)
+
+	paths := make([]PackagePath, 0, len(affected))
+	for path := range affected {
+		paths = append(paths, path)
+	}
+	sort.Slice(paths, func(i, j int) bool { return paths[i] < paths[j] })
+	return paths
 }
 
-func (l *Loader) Resolve(path string) {
-	return
+// Package returns the loaded package of the given path, or nil.
+func (l *Loader) Package(path PackagePath) *Package {
+	return l.pkgs[path]
 }
 
-func (l *Loader) sortList() []*std.MemPackage {
-	return nil
+// resolve returns the directory of the package imported as path.
+func (l *Loader) resolve(path string) (string, error) {
+	for _, root := range l.roots {
+		dir := filepath.Join(root, filepath.FromSlash(path))
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return filepath.Abs(dir)
+		}
+	}
+	return "", fmt.Errorf("unable to resolve import %q", path)
+}
+
+// loadPackage returns the package in dir, loading it and its
+// dependencies if needed. The package path is read from the gno.mod file
+// of dir, if any, and is pkgPath otherwise.
+func (l *Loader) loadPackage(dir, pkgPath string) (*Package, error) {
+	dir = filepath.Clean(dir)
+	if lp, ok := l.dirs[dir]; ok {
+		return lp, nil
+	}
+
+	lp := &Package{
+		Dir:          dir,
+		parentsDeps:  map[PackagePath]*Package{},
+		childrenDeps: map[PackagePath]*Package{},
+	}
+	if err := l.readPackage(lp, PackagePath(pkgPath)); err != nil {
+		l.forget(lp)
+		return nil, err
+	}
+	return lp, nil
+}
+
+// forget removes lp, which failed to load, from the loader.
+func (l *Loader) forget(lp *Package) {
+	if l.dirs[lp.Dir] == lp {
+		delete(l.dirs, lp.Dir)
+	}
+	if lp.mempkg == nil {
+		return
+	}
+	if l.pkgs[lp.Path()] == lp {
+		delete(l.pkgs, lp.Path())
+	}
+	for _, child := range lp.childrenDeps {
+		delete(child.parentsDeps, lp.Path())
+	}
+	for _, parent := range lp.parentsDeps {
+		delete(parent.childrenDeps, lp.Path())
+	}
 }
 
-func (l *Loader) loadPackage(path string) (*Package, error) {
-	files, err := os.ReadDir(path)
+// readPackage reads the files of lp, and updates its dependencies.
+// Imports of packages that are not loaded yet load them; lp is
+// registered before, so that import cycles terminate, and are reported
+// by sortList.
+//
+// The new dependencies are resolved before they replace the old ones:
+// an error leaves a loaded package as it was.
+func (l *Loader) readPackage(lp *Package, pkgPath PackagePath) error {
+	mempkg, files, imports, err := l.readDir(lp.Dir, pkgPath)
 	if err != nil {
-		return nil, fmt.Errorf("unable to read dir %q: %w", path, err)
+		return err
+	}
+	if old, ok := l.pkgs[PackagePath(mempkg.Path)]; ok && old != lp {
+		return fmt.Errorf("package %q in %q is already loaded from %q", mempkg.Path, lp.Dir, old.Dir)
 	}
 
-	if !filepath.IsAbs(path) {
-		var impdir string
-		for _, root := range l.roots {
-			impdir = filepath.Join(root, path)
-			_, err := os.Stat(impdir)
+	// Register the package under its, possibly new, path.
+	prev, prevFiles := lp.mempkg, lp.Files
+	if prev != nil && prev.Path != mempkg.Path {
+		delete(l.pkgs, lp.Path())
+	}
+	lp.mempkg, lp.Files = mempkg, files
+	l.pkgs[lp.Path()] = lp
+	l.dirs[lp.Dir] = lp
+
+	children := make(map[PackagePath]*Package, len(imports))
+	for _, imp := range imports {
+		child, ok := l.pkgs[PackagePath(imp)]
+		if !ok {
+			dir, err := l.resolve(imp)
+			if err == nil {
+				child, err = l.loadPackage(dir, imp)
+				if err != nil {
+					err = fmt.Errorf("unable to load %q: %w", imp, err)
+				}
+			}
 			if err != nil {
-				continue
+				if prev != nil {
+					// Restore the package; a new one is forgotten by
+					// loadPackage.
+					if l.pkgs[lp.Path()] == lp {
+						delete(l.pkgs, lp.Path())
+					}
+					lp.mempkg, lp.Files = prev, prevFiles
+					l.pkgs[lp.Path()] = lp
+				}
+				return err
 			}
 		}
+		children[child.Path()] = child
+	}
 
-		if impdir == "" {
-			return nil, fmt.Errorf("unable to resolve import %q", path)
-		}
-
-		var err error
-		path, err = filepath.Abs(path)
-		if err != nil {
-			return nil, fmt.Errorf("unable to determine absolute path of %q: %w", path, err)
+	// Replace the dependencies.
+	for _, child := range lp.childrenDeps {
+		if prev != nil {
+			delete(child.parentsDeps, PackagePath(prev.Path))
 		}
-	} else {
-		path = filepath.Clean(path)
 	}
+	lp.childrenDeps = children
+	for _, child := range children {
+		child.parentsDeps[lp.Path()] = lp
+	}
+	return nil
+}
 
-	var pkgpath string
+// readDir reads the package in dir, and returns its files and sorted
+// imports.
+func (l *Loader) readDir(dir string, pkgPath PackagePath) (*std.MemPackage, []string, []string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("unable to read dir %q: %w", dir, err)
+	}
 
 	// Check for a gno.mod, in which case it will define the module path
-	gnoModPath := filepath.Join(path, "gno.mod")
+	gnoModPath := filepath.Join(dir, "gno.mod")
 	data, err := os.ReadFile(gnoModPath)
 	switch {
 	case os.IsNotExist(err):
 	case err == nil:
 		gnoMod, err := gnomod.Parse(gnoModPath, data)
 		if err != nil {
-			return nil, fmt.Errorf("unable to parse gnomod %q: %w", gnoModPath, err)
+			return nil, nil, nil, fmt.Errorf("unable to parse gnomod %q: %w", gnoModPath, err)
 		}
 
 		gnoMod.Sanitize()
 		if err := gnoMod.Validate(); err != nil {
-			return nil, fmt.Errorf("unable to validate gnomod %q: %w", gnoModPath, err)
+			return nil, nil, nil, fmt.Errorf("unable to validate gnomod %q: %w", gnoModPath, err)
 		}
 
-		pkgpath = gnoMod.Module.Mod.Path
+		pkgPath = PackagePath(gnoMod.Module.Mod.Path)
 	default:
-		return nil, fmt.Errorf("unable to read %q: %w", gnoModPath, err)
+		return nil, nil, nil, fmt.Errorf("unable to read %q: %w", gnoModPath, err)
 	}
 
-	if pkgpath == "" {
-		return nil, fmt.Errorf("unable to determine package path of %q", path)
-	}
-
-	lp := l.getPkg(path)
-	if lp.update == l.update {
-		return lp, nil
+	if pkgPath == "" {
+		return nil, nil, nil, fmt.Errorf("unable to determine package path of %q", dir)
 	}
 
 	// Resolve pkg deps
 	var pkgname string
 	imports := map[string]struct{}{}
 	memfiles := []*std.MemFile{}
-	for _, file := range files {
-		name := file.Name()
-		if !isGnoFile(name) || isTestFile(name) {
+	var files []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !isGnoFile(name) || isTestFile(name) {
 			continue
 		}
 
-		filepath := filepath.Join(path, name)
-		body, err := os.ReadFile(filepath)
+		fpath := filepath.Join(dir, name)
+		body, err := os.ReadFile(fpath)
 		if err != nil {
-			return nil, fmt.Errorf("unable to read file %q: %w", filepath, err)
-		}
-
-		mf := &std.MemFile{
-			Name: name,
-			Body: string(body),
+			return nil, nil, nil, fmt.Errorf("unable to read file %q: %w", fpath, err)
 		}
 
-		f, err := parser.ParseFile(l.fset, name, body, parser.ImportsOnly)
+		f, err := parser.ParseFile(l.fset, fpath, body, parser.ImportsOnly)
 		if err != nil {
-			return nil, fmt.Errorf("unable to parse file %q: %w", name, err)
+			return nil, nil, nil, fmt.Errorf("unable to parse file %q: %w", fpath, err)
 		}
 
 		if pkgname != "" && pkgname != f.Name.Name {
-			return nil, fmt.Errorf("conflict package name between %q and %q", pkgname, f.Name.Name)
+			return nil, nil, nil, fmt.Errorf("conflict package name between %q and %q", pkgname, f.Name.Name)
 		}
 
 		for _, imp := range f.Imports {
-			if len(imp.Path.Value) <= 2 {
+			val, err := strconv.Unquote(imp.Path.Value)
+			if err != nil || val == "" {
 				continue
 			}
-
-			val := imp.Path.Value[1 : len(imp.Path.Value)-1]
 			imports[val] = struct{}{}
 		}
 
 		pkgname = f.Name.Name
-		memfiles = append(memfiles, mf)
+		memfiles = append(memfiles, &std.MemFile{
+			Name: name,
+			Body: string(body),
+		})
+		files = append(files, fpath)
 	}
 
 	if len(memfiles) == 0 {
-		return nil, fmt.Errorf("%q empty package", path)
+		return nil, nil, nil, fmt.Errorf("%q empty package", dir)
 	}
 
+	sortedImports := make([]string, 0, len(imports))
 	for imp := range imports {
-		child, err := l.loadPackage(imp)
-		if err != nil {
-			return nil, fmt.Errorf("unable to load %q: %w", imp, err)
-		}
-
-		child.parentsDeps[path] = lp
+		sortedImports = append(sortedImports, imp)
 	}
+	sort.Strings(sortedImports)
 
-	lp.mempkg = std.MemPackage{
+	return &std.MemPackage{
 		Name:  pkgname,
-		Path:  pkgpath,
+		Path:  string(pkgPath),
 		Files: memfiles,
+	}, files, sortedImports, nil
+}
+
+// sortList returns the loaded packages in topological order, each one
+// after its dependencies, and packages of the same depth by path. It
+// reports an error if the imports form a cycle.
+func (l *Loader) sortList() ([]*Package, error) {
+	paths := make([]PackagePath, 0, len(l.pkgs))
+	for path := range l.pkgs {
+		paths = append(paths, path)
 	}
-	lp.update = l.update // set as updated
-	return lp, nil
+	sort.Slice(paths, func(i, j int) bool { return paths[i] < paths[j] })
+
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := map[*Package]int{}
+	var (
+		sorted []*Package
+		stack  []PackagePath
+	)
+	var visit func(lp *Package) error
+	visit = func(lp *Package) error {
+		switch state[lp] {
+		case done:
+			return nil
+		case visiting:
+			// Report the cycle from its first occurrence.
+			i := len(stack) - 1
+			for stack[i] != lp.Path() {
+				i--
+			}
+			cycle := append(append([]PackagePath{}, stack[i:]...), lp.Path())
+			return fmt.Errorf("import cycle: %s", joinPaths(cycle, " -> "))
+		}
+		state[lp] = visiting
+		stack = append(stack, lp.Path())
+
+		children := make([]PackagePath, 0, len(lp.childrenDeps))
+		for path := range lp.childrenDeps {
+			children = append(children, path)
+		}
+		sort.Slice(children, func(i, j int) bool { return children[i] < children[j] })
+		for _, path := range children {
+			if err := visit(lp.childrenDeps[path]); err != nil {
+				return err
+			}
+		}
+
+		stack = stack[:len(stack)-1]
+		state[lp] = done
+		sorted = append(sorted, lp)
+		return nil
+	}
+	for _, path := range paths {
+		if err := visit(l.pkgs[path]); err != nil {
+			return nil, err
+		}
+	}
+	return sorted, nil
+}
+
+func joinPaths(paths []PackagePath, sep string) string {
+	strs := make([]string, len(paths))
+	for i, path := range paths {
+		strs[i] = string(path)
+	}
+	return strings.Join(strs, sep)
 }
 
 func isGnoFile(name string) bool {
@@ -0,0 +1,166 @@
+package gnopkg
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeTree writes files, by slash-separated path relative to root.
+func writeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLoader(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"stdlibs/strings/strings.gno":                "package strings\n",
+		"stdlibs/strings/strings_test.gno":           "package strings\n\nimport \"testing\"\n",
+		"examples/gno.land/p/demo/avl/gno.mod":       "module gno.land/p/demo/avl\n",
+		"examples/gno.land/p/demo/avl/avl.gno":       "package avl\n",
+		"examples/gno.land/p/demo/ufmt/gno.mod":      "module gno.land/p/demo/ufmt\n",
+		"examples/gno.land/p/demo/ufmt/ufmt.gno":     "package ufmt\n\nimport \"strings\"\n",
+		"examples/gno.land/p/demo/unused/gno.mod":    "module gno.land/p/demo/unused\n",
+		"examples/gno.land/p/demo/unused/unused.gno": "package unused\n",
+		"work/app/gno.mod":                           "module gno.land/r/demo/app\n",
+		"work/app/app.gno":                           "package app\n\nimport (\n\t\"gno.land/p/demo/avl\"\n\t\"gno.land/p/demo/ufmt\"\n)\n",
+		"work/app/README.md":                         "# app\n",
+	})
+
+	l := NewLoader(filepath.Join(root, "stdlibs"), filepath.Join(root, "examples"))
+	if err := l.AddPackage(filepath.Join(root, "work", "app")); err != nil {
+		t.Fatal(err)
+	}
+
+	order := func() []string {
+		t.Helper()
+		mempkgs, err := l.LoadPackages()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var paths []string
+		for _, mempkg := range mempkgs {
+			paths = append(paths, mempkg.Path)
+		}
+		return paths
+	}
+	want := []string{"gno.land/p/demo/avl", "strings", "gno.land/p/demo/ufmt", "gno.land/r/demo/app"}
+	if got := order(); !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadPackages() = %q, want %q", got, want)
+	}
+	if files := l.Package("strings").MemPackage().Files; len(files) != 1 {
+		t.Errorf("strings has %d files, want 1: test files are not loaded", len(files))
+	}
+
+	// Editing a dependency affects its importers, directly or not.
+	stringsFile := filepath.Join(root, "stdlibs", "strings", "strings.gno")
+	if got, want := l.Invalidate(stringsFile), []PackagePath{"gno.land/p/demo/ufmt", "gno.land/r/demo/app", "strings"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Invalidate(strings.gno) = %q, want %q", got, want)
+	}
+	if got := l.Invalidate(filepath.Join(root, "examples", "gno.land", "p", "demo", "avl", "avl.gno")); !reflect.DeepEqual(got, []PackagePath{"gno.land/p/demo/avl", "gno.land/r/demo/app"}) {
+		t.Errorf("Invalidate(avl.gno) = %q", got)
+	}
+	// Test files and unrelated files affect nothing.
+	if got := l.Invalidate(filepath.Join(root, "stdlibs", "strings", "strings_test.gno")); got != nil {
+		t.Errorf("Invalidate(strings_test.gno) = %q, want nil", got)
+	}
+	if got := l.Invalidate(filepath.Join(root, "work", "app", "README.md")); got != nil {
+		t.Errorf("Invalidate(README.md) = %q, want nil", got)
+	}
+
+	// Edits that change the imports update the graph.
+	writeTree(t, root, map[string]string{
+		"work/app/app.gno": "package app\n\nimport \"gno.land/p/demo/unused\"\n",
+	})
+	if got := l.Invalidate(filepath.Join(root, "work", "app", "app.gno")); !reflect.DeepEqual(got, []PackagePath{"gno.land/r/demo/app"}) {
+		t.Errorf("Invalidate(app.gno) = %q", got)
+	}
+	if got := l.Invalidate(stringsFile); !reflect.DeepEqual(got, []PackagePath{"gno.land/p/demo/ufmt", "strings"}) {
+		t.Errorf("after dropping the ufmt import, Invalidate(strings.gno) = %q", got)
+	}
+	want = []string{"gno.land/p/demo/avl", "strings", "gno.land/p/demo/ufmt", "gno.land/p/demo/unused", "gno.land/r/demo/app"}
+	if got := order(); !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadPackages() = %q, want %q", got, want)
+	}
+}
+
+func TestLoaderCycle(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"gno.land/p/demo/a/gno.mod": "module gno.land/p/demo/a\n",
+		"gno.land/p/demo/a/a.gno":   "package a\n\nimport \"gno.land/p/demo/b\"\n",
+		"gno.land/p/demo/b/gno.mod": "module gno.land/p/demo/b\n",
+		"gno.land/p/demo/b/b.gno":   "package b\n\nimport \"gno.land/p/demo/a\"\n",
+	})
+
+	l := NewLoader(root)
+	if err := l.AddPackage(filepath.Join(root, "gno.land", "p", "demo", "a")); err != nil {
+		t.Fatal(err)
+	}
+	_, err := l.LoadPackages()
+	if err == nil || !strings.Contains(err.Error(), "import cycle: gno.land/p/demo/a -> gno.land/p/demo/b -> gno.land/p/demo/a") {
+		t.Errorf("LoadPackages() error = %v, want import cycle", err)
+	}
+}
+
+func TestLoaderUnresolved(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"app/gno.mod": "module gno.land/r/demo/app\n",
+		"app/app.gno": "package app\n\nimport \"gno.land/p/demo/missing\"\n",
+	})
+
+	l := NewLoader(root)
+	err := l.AddPackage(filepath.Join(root, "app"))
+	if err == nil || !strings.Contains(err.Error(), `unable to resolve import "gno.land/p/demo/missing"`) {
+		t.Fatalf("AddPackage() error = %v, want unresolved import", err)
+	}
+	if l.Package("gno.land/r/demo/app") != nil {
+		t.Errorf("package that failed to load is still loaded")
+	}
+}
+
+func TestLoaderUpdateError(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"app/gno.mod":                 "module gno.land/r/demo/app\n",
+		"app/app.gno":                 "package app\n\nimport \"gno.land/p/demo/avl\"\n",
+		"gno.land/p/demo/avl/avl.gno": "package avl\n",
+	})
+
+	l := NewLoader(root)
+	app := filepath.Join(root, "app")
+	if err := l.AddPackage(app); err != nil {
+		t.Fatal(err)
+	}
+
+	// An edit importing a missing package leaves the package as it was.
+	writeTree(t, root, map[string]string{
+		"app/app.gno": "package app\n\nimport (\n\t\"gno.land/p/demo/avl\"\n\t\"gno.land/p/demo/aaa\"\n)\n",
+	})
+	if err := l.Update(app); err == nil {
+		t.Fatal("Update() succeeded, want unresolved import")
+	}
+	pkg := l.Package("gno.land/r/demo/app")
+	if pkg == nil {
+		t.Fatal("package that failed to update is no longer loaded")
+	}
+	if body := pkg.MemPackage().Files[0].Body; strings.Contains(body, "aaa") {
+		t.Errorf("package that failed to update has the new files")
+	}
+	avl := filepath.Join(root, "gno.land", "p", "demo", "avl", "avl.gno")
+	if got, want := l.Invalidate(avl), []PackagePath{"gno.land/p/demo/avl", "gno.land/r/demo/app"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Invalidate(avl.gno) = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,353 @@
+package gnorender
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// This file converts the Markdown returned by Render functions to HTML.
+//
+// It supports the subset of Markdown used by realms: headings, fenced
+// code blocks, block quotes, lists, tables, thematic breaks and
+// paragraphs, with code spans, emphasis, links, images and autolinks.
+// Raw HTML is escaped, as the output of a realm is not trusted.
+
+var (
+	headingRx   = regexp.MustCompile(`^(#{1,6})(?:[ \t]+(.*?))?[ \t#]*$`)
+	fenceRx     = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})[ \t]*([^`\\s]*)")
+	breakRx     = regexp.MustCompile(`^ {0,3}(?:(?:\*[ \t]*){3,}|(?:-[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	bulletRx    = regexp.MustCompile(`^ {0,3}[-*+][ \t]+(.*)$`)
+	orderedRx   = regexp.MustCompile(`^ {0,3}[0-9]{1,9}[.)][ \t]+(.*)$`)
+	quoteRx     = regexp.MustCompile(`^ {0,3}> ?(.*)$`)
+	tableSepRx  = regexp.MustCompile(`^ *\|? *:?-+:? *(?:\| *:?-+:? *)*\|? *$`)
+	linkTailRx  = regexp.MustCompile(`^\(\s*(<[^>]*>|[^\s()]*(?:\([^\s()]*\)[^\s()]*)*)(?:\s+"([^"]*)")?\s*\)`)
+	autolinkRx  = regexp.MustCompile(`^<((?:https?|mailto):[^\s<>]+)>`)
+	safeSchemes = []string{"http:", "https:", "mailto:"}
+)
+
+// HTML returns the HTML rendering of the Markdown document md.
+func HTML(md string) string {
+	var b strings.Builder
+	renderBlocks(&b, strings.Split(strings.ReplaceAll(md, "\r\n", "\n"), "\n"))
+	return b.String()
+}
+
+// renderBlocks writes the HTML of the block-level elements of lines.
+func renderBlocks(b *strings.Builder, lines []string) {
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		switch {
+		case strings.TrimSpace(line) == "":
+			i++
+
+		case fenceRx.MatchString(line):
+			m := fenceRx.FindStringSubmatch(line)
+			fence, lang := m[1], m[2]
+			i++
+			b.WriteString("<pre><code")
+			if lang != "" {
+				b.WriteString(` class="language-` + html.EscapeString(lang) + `"`)
+			}
+			b.WriteString(">")
+			for ; i < len(lines); i++ {
+				if strings.HasPrefix(strings.TrimSpace(lines[i]), fence) {
+					i++
+					break
+				}
+				b.WriteString(html.EscapeString(lines[i]) + "\n")
+			}
+			b.WriteString("</code></pre>\n")
+
+		case headingRx.MatchString(line):
+			m := headingRx.FindStringSubmatch(line)
+			tag := "h" + string(rune('0'+len(m[1])))
+			b.WriteString("<" + tag + ">" + renderInline(m[2]) + "</" + tag + ">\n")
+			i++
+
+		case breakRx.MatchString(line):
+			b.WriteString("<hr>\n")
+			i++
+
+		case quoteRx.MatchString(line):
+			var quoted []string
+			for ; i < len(lines) && quoteRx.MatchString(lines[i]); i++ {
+				quoted = append(quoted, quoteRx.FindStringSubmatch(lines[i])[1])
+			}
+			b.WriteString("<blockquote>\n")
+			renderBlocks(b, quoted)
+			b.WriteString("</blockquote>\n")
+
+		case bulletRx.MatchString(line), orderedRx.MatchString(line):
+			i = renderList(b, lines, i)
+
+		case i+1 < len(lines) && strings.Contains(line, "|") && tableSepRx.MatchString(lines[i+1]):
+			i = renderTable(b, lines, i)
+
+		default:
+			var para []string
+			for ; i < len(lines) && strings.TrimSpace(lines[i]) != "" && !startsBlock(lines, i); i++ {
+				para = append(para, lines[i])
+			}
+			b.WriteString("<p>")
+			for j, l := range para {
+				text := strings.TrimSpace(l)
+				hardBreak := strings.HasSuffix(l, "  ") || strings.HasSuffix(text, `\`)
+				b.WriteString(renderInline(strings.TrimSuffix(text, `\`)))
+				if j < len(para)-1 {
+					if hardBreak {
+						b.WriteString("<br>")
+					}
+					b.WriteString("\n")
+				}
+			}
+			b.WriteString("</p>\n")
+		}
+	}
+}
+
+// startsBlock reports whether lines[i] interrupts a paragraph.
+func startsBlock(lines []string, i int) bool {
+	line := lines[i]
+	return fenceRx.MatchString(line) ||
+		headingRx.MatchString(line) ||
+		breakRx.MatchString(line) ||
+		quoteRx.MatchString(line) ||
+		bulletRx.MatchString(line) ||
+		orderedRx.MatchString(line)
+}
+
+// renderList writes the list starting at lines[i] and returns the
+// index of the line following it. Indented lines continue the current
+// item, and may hold a nested list.
+func renderList(b *strings.Builder, lines []string, i int) int {
+	itemRx, tag := bulletRx, "ul"
+	if orderedRx.MatchString(lines[i]) {
+		itemRx, tag = orderedRx, "ol"
+	}
+	b.WriteString("<" + tag + ">\n")
+	for i < len(lines) && itemRx.MatchString(lines[i]) {
+		item := []string{itemRx.FindStringSubmatch(lines[i])[1]}
+		for i++; i < len(lines); i++ {
+			l := lines[i]
+			if strings.TrimSpace(l) == "" || !(strings.HasPrefix(l, "  ") || strings.HasPrefix(l, "\t")) {
+				break
+			}
+			item = append(item, unindent(l))
+		}
+		b.WriteString("<li>")
+		if len(item) == 1 {
+			b.WriteString(renderInline(item[0]))
+		} else {
+			// The first line is the text of the item; the
+			// following ones may form nested blocks.
+			b.WriteString(renderInline(item[0]) + "\n")
+			renderBlocks(b, item[1:])
+		}
+		b.WriteString("</li>\n")
+	}
+	b.WriteString("</" + tag + ">\n")
+	return i
+}
+
+// unindent removes one level of indentation, a tab or up to four
+// spaces, from line.
+func unindent(line string) string {
+	if strings.HasPrefix(line, "\t") {
+		return line[1:]
+	}
+	for i := 0; i < 4 && strings.HasPrefix(line, " "); i++ {
+		line = line[1:]
+	}
+	return line
+}
+
+// renderTable writes the table whose header is lines[i] and returns the
+// index of the line following it.
+func renderTable(b *strings.Builder, lines []string, i int) int {
+	header := tableCells(lines[i])
+	var aligns []string
+	for _, sep := range tableCells(lines[i+1]) {
+		switch left, right := strings.HasPrefix(sep, ":"), strings.HasSuffix(sep, ":"); {
+		case left && right:
+			aligns = append(aligns, "center")
+		case right:
+			aligns = append(aligns, "right")
+		case left:
+			aligns = append(aligns, "left")
+		default:
+			aligns = append(aligns, "")
+		}
+	}
+	row := func(cells []string, tag string) {
+		b.WriteString("<tr>")
+		for j := range header {
+			cell := ""
+			if j < len(cells) {
+				cell = cells[j]
+			}
+			b.WriteString("<" + tag)
+			if j < len(aligns) && aligns[j] != "" {
+				b.WriteString(` style="text-align: ` + aligns[j] + `"`)
+			}
+			b.WriteString(">" + renderInline(cell) + "</" + tag + ">")
+		}
+		b.WriteString("</tr>\n")
+	}
+
+	b.WriteString("<table>\n<thead>\n")
+	row(header, "th")
+	b.WriteString("</thead>\n<tbody>\n")
+	for i += 2; i < len(lines) && strings.TrimSpace(lines[i]) != "" && strings.Contains(lines[i], "|"); i++ {
+		row(tableCells(lines[i]), "td")
+	}
+	b.WriteString("</tbody>\n</table>\n")
+	return i
+}
+
+// tableCells returns the trimmed cells of a table row.
+func tableCells(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	line = strings.TrimSuffix(line, "|")
+	cells := strings.Split(line, "|")
+	for i := range cells {
+		cells[i] = strings.TrimSpace(cells[i])
+	}
+	return cells
+}
+
+// renderInline returns the HTML of the inline elements of text.
+func renderInline(text string) string {
+	var b strings.Builder
+	for i := 0; i < len(text); {
+		rest := text[i:]
+		switch c := text[i]; {
+		case c == '\\' && i+1 < len(text) && strings.ContainsRune("\\`*_{}[]()#+-.!|~<>", rune(text[i+1])):
+			b.WriteString(html.EscapeString(text[i+1 : i+2]))
+			i += 2
+			continue
+
+		case c == '`':
+			n := len(rest) - len(strings.TrimLeft(rest, "`"))
+			delim := rest[:n]
+			if end := strings.Index(rest[n:], delim); end >= 0 {
+				code := strings.TrimSpace(rest[n : n+end])
+				b.WriteString("<code>" + html.EscapeString(code) + "</code>")
+				i += 2*n + end
+				continue
+			}
+
+		case c == '!' && strings.HasPrefix(rest, "!["):
+			if alt, url, title, n, ok := parseLink(rest[1:]); ok {
+				b.WriteString(`<img src="` + html.EscapeString(safeURL(url)) + `" alt="` + html.EscapeString(alt) + `"`)
+				if title != "" {
+					b.WriteString(` title="` + html.EscapeString(title) + `"`)
+				}
+				b.WriteString(">")
+				i += 1 + n
+				continue
+			}
+
+		case c == '[':
+			if label, url, title, n, ok := parseLink(rest); ok {
+				b.WriteString(`<a href="` + html.EscapeString(safeURL(url)) + `"`)
+				if title != "" {
+					b.WriteString(` title="` + html.EscapeString(title) + `"`)
+				}
+				b.WriteString(">" + renderInline(label) + "</a>")
+				i += n
+				continue
+			}
+
+		case c == '<':
+			if m := autolinkRx.FindStringSubmatch(rest); m != nil {
+				b.WriteString(`<a href="` + html.EscapeString(m[1]) + `">` + html.EscapeString(m[1]) + "</a>")
+				i += len(m[0])
+				continue
+			}
+
+		case c == '*' || c == '~' || c == '_' && (i == 0 || !isWordByte(text[i-1])):
+			if em, n, ok := parseEmphasis(rest); ok {
+				b.WriteString(em)
+				i += n
+				continue
+			}
+		}
+		b.WriteString(html.EscapeString(text[i : i+1]))
+		i++
+	}
+	return b.String()
+}
+
+// isWordByte reports whether c is an ASCII letter or digit. Underscores
+// within words, as in snake_case, do not delimit emphasis.
+func isWordByte(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
+}
+
+// emphases are the emphasis delimiters, longest first.
+var emphases = []struct{ delim, tag string }{
+	{"**", "strong"},
+	{"__", "strong"},
+	{"~~", "del"},
+	{"*", "em"},
+	{"_", "em"},
+}
+
+// parseEmphasis parses emphasized text at the start of s, and returns
+// its HTML and length.
+func parseEmphasis(s string) (string, int, bool) {
+	for _, em := range emphases {
+		d := len(em.delim)
+		if !strings.HasPrefix(s, em.delim) || len(s) <= d || s[d] == ' ' {
+			continue
+		}
+		end := strings.Index(s[d:], em.delim)
+		if end <= 0 || s[d+end-1] == ' ' {
+			continue
+		}
+		return "<" + em.tag + ">" + renderInline(s[d:d+end]) + "</" + em.tag + ">", 2*d + end, true
+	}
+	return "", 0, false
+}
+
+// parseLink parses a link of the form [label](url "title") at the start
+// of s, and returns its parts and length.
+func parseLink(s string) (label, url, title string, n int, ok bool) {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				m := linkTailRx.FindStringSubmatch(s[i+1:])
+				if m == nil {
+					return "", "", "", 0, false
+				}
+				url := strings.TrimSuffix(strings.TrimPrefix(m[1], "<"), ">")
+				return s[1:i], url, m[2], i + 1 + len(m[0]), true
+			}
+		}
+	}
+	return "", "", "", 0, false
+}
+
+// safeURL returns url if it is relative or uses a safe scheme, and "#"
+// otherwise, so that a realm cannot inject scripts in the page.
+func safeURL(url string) string {
+	scheme, _, found := strings.Cut(url, ":")
+	if !found || strings.ContainsAny(scheme, "/?#") {
+		return url // relative
+	}
+	for _, safe := range safeSchemes {
+		if strings.EqualFold(scheme+":", safe) {
+			return url
+		}
+	}
+	return "#"
+}
@@ -0,0 +1,57 @@
+package gnorender
+
+import "testing"
+
+func TestHTML(t *testing.T) {
+	tests := []struct {
+		name, md, want string
+	}{
+		{
+			name: "heading and paragraph",
+			md:   "# Hello *world*\n\nSome **bold** and `code`,\nwith snake_case_name.\n",
+			want: "<h1>Hello <em>world</em></h1>\n<p>Some <strong>bold</strong> and <code>code</code>,\nwith snake_case_name.</p>\n",
+		},
+		{
+			name: "links",
+			md:   "[boards](/r/demo/boards:hello) ![logo](https://gno.land/logo.png \"Gno\") <https://gno.land> [x](javascript:alert(1))",
+			want: "<p><a href=\"/r/demo/boards:hello\">boards</a> <img src=\"https://gno.land/logo.png\" alt=\"logo\" title=\"Gno\"> <a href=\"https://gno.land\">https://gno.land</a> <a href=\"#\">x</a></p>\n",
+		},
+		{
+			name: "raw html",
+			md:   "<script>alert(1)</script>",
+			want: "<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>\n",
+		},
+		{
+			name: "code block",
+			md:   "```go\nfunc main() {\n\tprintln(\"<hi>\")\n}\n```\n",
+			want: "<pre><code class=\"language-go\">func main() {\n\tprintln(&#34;&lt;hi&gt;&#34;)\n}\n</code></pre>\n",
+		},
+		{
+			name: "lists",
+			md:   "- one\n- two\n  - nested\n\n1. first\n2. second\n",
+			want: "<ul>\n<li>one</li>\n<li>two\n<ul>\n<li>nested</li>\n</ul>\n</li>\n</ul>\n<ol>\n<li>first</li>\n<li>second</li>\n</ol>\n",
+		},
+		{
+			name: "quote and break",
+			md:   "> quoted\n> text\n\n---\n",
+			want: "<blockquote>\n<p>quoted\ntext</p>\n</blockquote>\n<hr>\n",
+		},
+		{
+			name: "table",
+			md:   "| Name | Score |\n|:-----|------:|\n| alice | 10 |\n| bob | 5 |\n",
+			want: "<table>\n<thead>\n<tr><th style=\"text-align: left\">Name</th><th style=\"text-align: right\">Score</th></tr>\n</thead>\n<tbody>\n<tr><td style=\"text-align: left\">alice</td><td style=\"text-align: right\">10</td></tr>\n<tr><td style=\"text-align: left\">bob</td><td style=\"text-align: right\">5</td></tr>\n</tbody>\n</table>\n",
+		},
+		{
+			name: "hard break",
+			md:   "line one  \nline two\\\nline three",
+			want: "<p>line one<br>\nline two<br>\nline three</p>\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := HTML(test.md); got != test.want {
+				t.Errorf("HTML(%q) =\n%s\nwant:\n%s", test.md, got, test.want)
+			}
+		})
+	}
+}
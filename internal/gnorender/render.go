@@ -0,0 +1,83 @@
+// Package gnorender executes the Render function of a Gno realm
+// in-process, in the manner of gnoweb, and formats its Markdown output
+// as HTML.
+package gnorender
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/gnolang/gno/gnovm/pkg/gnoenv"
+	gno "github.com/gnolang/gno/gnovm/pkg/gnolang"
+	"github.com/gnolang/gno/gnovm/tests"
+	"github.com/gnolang/gno/tm2/pkg/std"
+)
+
+// maxAlloc is the allocation limit of the machine executing Render.
+const maxAlloc = 500 * 1000 * 1000
+
+// maxCycles bounds the execution of Render, so that a realm stuck in a
+// loop doesn't hang the server.
+const maxCycles = 100 * 1000 * 1000
+
+// Options configures the execution of Render.
+type Options struct {
+	// RootDir is the GNOROOT directory used to load the standard
+	// libraries and the examples imported by the realm.
+	// If empty, it is guessed from the environment.
+	RootDir string
+
+	// Output receives the output of the realm, such as the output of
+	// println, while it is deployed and rendered. It may be nil.
+	Output io.Writer
+}
+
+// Render deploys memPkg, a realm, to an in-memory gnovm and returns the
+// result of its Render function for the given path, as gnoweb does
+// when it serves the page of the realm at that path.
+//
+// The files of memPkg are used as is: callers pass the contents of the
+// editor buffers to render unsaved changes. Imports are resolved against
+// the standard libraries and the examples of the Gno root directory.
+func Render(ctx context.Context, memPkg *std.MemPackage, path string, opts Options) (_ string, err error) {
+	if opts.RootDir == "" {
+		opts.RootDir, err = gnoenv.GuessRootDir()
+		if err != nil {
+			return "", fmt.Errorf("unable to guess gno root dir: %w", err)
+		}
+	}
+	if opts.Output == nil {
+		opts.Output = io.Discard
+	}
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	defer func() {
+		// The gnovm reports most errors, including type and runtime
+		// errors of the realm, by panicking.
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic while rendering %q: %v", memPkg.Path, r)
+		}
+	}()
+
+	store := tests.TestStore(opts.RootDir, "", new(bytes.Buffer), opts.Output, opts.Output, tests.ImportModeStdlibsOnly)
+	m := gno.NewMachineWithOptions(gno.MachineOptions{
+		PkgPath:       "",
+		Output:        opts.Output,
+		Store:         store,
+		Context:       tests.TestContext(memPkg.Path, std.Coins{}),
+		MaxAllocBytes: maxAlloc,
+		MaxCycles:     maxCycles,
+	})
+	defer m.Release()
+
+	m.RunMemPackage(memPkg, true)
+	ret := m.Eval(gno.Call("Render", fmt.Sprintf("%q", path)))
+	if len(ret) != 1 {
+		return "", fmt.Errorf("%s.Render returned %d values, want 1", memPkg.Path, len(ret))
+	}
+	return ret[0].GetString(), nil
+}
@@ -0,0 +1,161 @@
+// Package gnostate reads the values of the package-level variables of
+// realms, as persisted by a gno.land chain.
+//
+// The values are read either from a node, by evaluating each variable
+// with the vm/qeval ABCI query of its RPC endpoint, or from a state file:
+// a JSON object mapping the path of each realm to an object mapping the
+// names of its variables to their values, as printed by the VM:
+//
+//	{
+//		"gno.land/r/demo/counter": {
+//			"counter": "(42 int)"
+//		}
+//	}
+package gnostate
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// A Source is where realm state is read from: the RPC address of a
+// gno.land node, such as "http://127.0.0.1:26657", or the path of a
+// state file.
+type Source string
+
+// IsRemote reports whether src is the address of a node.
+func (src Source) IsRemote() bool {
+	return strings.Contains(string(src), "://")
+}
+
+// A Value is the value of a variable, or the error reading it.
+type Value struct {
+	Text string // as printed by the VM, e.g. "(42 int)"
+	Err  error
+}
+
+// Values returns the values of the package-level variables names of the
+// realm pkgPath. Variables that the source doesn't have are absent from
+// the result. The error is that of the source as a whole, such as an
+// unreadable state file; errors of single variables, such as a node
+// failing to evaluate them, are reported in their Value.
+func Values(ctx context.Context, src Source, pkgPath string, names []string) (map[string]Value, error) {
+	if src == "" {
+		return nil, errors.New("no realm state source is configured")
+	}
+	values := make(map[string]Value)
+	if !src.IsRemote() {
+		state, err := readStateFile(string(src))
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			if text, ok := state[pkgPath][name]; ok {
+				values[name] = Value{Text: text}
+			}
+		}
+		return values, nil
+	}
+
+	for _, name := range names {
+		text, err := QueryEval(ctx, string(src), pkgPath, name)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		var nodeErr *NodeError
+		if errors.As(err, &nodeErr) && strings.Contains(nodeErr.Log, "not found") {
+			continue // e.g. a realm or variable that wasn't deployed
+		}
+		values[name] = Value{Text: text, Err: err}
+	}
+	return values, nil
+}
+
+// readStateFile reads the state file at path.
+func readStateFile(path string) (map[string]map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var state map[string]map[string]string
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("parsing state file %s: %w", path, err)
+	}
+	return state, nil
+}
+
+// A NodeError is an error returned by a node for a query.
+type NodeError struct {
+	Log string // the log of the response, which describes the error
+}
+
+func (e *NodeError) Error() string { return e.Log }
+
+// QueryEval evaluates the expression expr in the package pkgPath with the
+// vm/qeval query of the node at the RPC address remote, and returns its
+// result as printed by the VM.
+func QueryEval(ctx context.Context, remote, pkgPath, expr string) (string, error) {
+	u, err := url.Parse(remote)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme == "tcp" {
+		u.Scheme = "http" // the RPC endpoint of a node serves HTTP
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/abci_query"
+	u.RawQuery = url.Values{
+		"path": {strconv.Quote("vm/qeval")},
+		"data": {"0x" + hex.EncodeToString([]byte(pkgPath+"."+expr))},
+	}.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	// The JSON-RPC response of an ABCI query.
+	var rpc struct {
+		Error *struct {
+			Message string `json:"message"`
+			Data    string `json:"data"`
+		} `json:"error"`
+		Result struct {
+			Response struct {
+				ResponseBase struct {
+					Error json.RawMessage `json:"Error"`
+					Data  []byte          `json:"Data"` // base64
+					Log   string          `json:"Log"`
+				} `json:"ResponseBase"`
+			} `json:"response"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(body, &rpc); err != nil {
+		return "", fmt.Errorf("invalid response from %s (%s): %w", remote, resp.Status, err)
+	}
+	if rpc.Error != nil {
+		return "", fmt.Errorf("%s: %s %s", remote, rpc.Error.Message, rpc.Error.Data)
+	}
+	base := rpc.Result.Response.ResponseBase
+	if len(base.Error) > 0 && string(base.Error) != "null" {
+		return "", &NodeError{Log: base.Log}
+	}
+	return strings.TrimSpace(string(base.Data)), nil
+}
@@ -0,0 +1,72 @@
+package gnostate
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fakeNode serves vm/qeval queries of the variables of state, in the
+// manner of the RPC endpoint of a gno.land node.
+func fakeNode(t *testing.T, state map[string]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/abci_query" || req.URL.Query().Get("path") != `"vm/qeval"` {
+			http.NotFound(w, req)
+			return
+		}
+		data, err := hex.DecodeString(strings.TrimPrefix(req.URL.Query().Get("data"), "0x"))
+		if err != nil {
+			t.Errorf("invalid query data: %v", err)
+		}
+		value, ok := state[string(data)]
+		if !ok {
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":"","result":{"response":{"ResponseBase":{"Error":{"@type":"/vm.InvalidExprError"},"Data":null,"Log":"name %s not found"}}}}`, data)
+			return
+		}
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":"","result":{"response":{"ResponseBase":{"Error":null,"Data":%q,"Log":""}}}}`,
+			base64.StdEncoding.EncodeToString([]byte(value+"\n")))
+	}))
+}
+
+func TestValues(t *testing.T) {
+	const pkgPath = "gno.land/r/demo/counter"
+	names := []string{"counter", "owner", "missing"}
+	want := map[string]Value{
+		"counter": {Text: "(42 int)"},
+		"owner":   {Text: `("g1abc" std.Address)`},
+	}
+
+	node := fakeNode(t, map[string]string{
+		pkgPath + ".counter": "(42 int)",
+		pkgPath + ".owner":   `("g1abc" std.Address)`,
+	})
+	defer node.Close()
+	got, err := Values(context.Background(), Source(node.URL), pkgPath, names)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Values(node) = %v, want %v", got, want)
+	}
+
+	file := filepath.Join(t.TempDir(), "state.json")
+	content := `{"gno.land/r/demo/counter": {"counter": "(42 int)", "owner": "(\"g1abc\" std.Address)"}}`
+	if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got, err = Values(context.Background(), Source(file), pkgPath, names)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Values(file) = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,51 @@
+package gnotest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gnolang/gno/gnovm/pkg/gnoenv"
+	"github.com/gnolang/gno/gnovm/tests"
+)
+
+// UpdateFiletest runs the filetest src, named filename, and returns its
+// content with the expected blocks of its directives, such as
+// "// Output:", replaced by the actual results of the run, in the manner
+// of "gno test -update-golden-tests".
+//
+// The filetest is run on a copy of src: the file on disk is left
+// untouched. If rootDir is empty, it is guessed from the environment.
+func UpdateFiletest(rootDir, filename string, src []byte) (_ []byte, err error) {
+	if rootDir == "" {
+		rootDir, err = gnoenv.GuessRootDir()
+		if err != nil {
+			return nil, fmt.Errorf("unable to guess gno root dir: %w", err)
+		}
+	}
+
+	dir, err := os.MkdirTemp("", "gnopls-filetest-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, filepath.Base(filename))
+	if err := os.WriteFile(path, src, 0o644); err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		// The gnovm reports some errors by panicking.
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic while running filetest %q: %v", filename, r)
+		}
+	}()
+
+	// With WithSyncWanted, RunFileTest rewrites the expected blocks of
+	// the file instead of failing on a mismatch.
+	if err := tests.RunFileTest(rootDir, path, tests.WithSyncWanted(true)); err != nil {
+		return nil, fmt.Errorf("filetest %q: %w", filename, err)
+	}
+	return os.ReadFile(path)
+}
@@ -0,0 +1,273 @@
+// Package gnotest runs the tests of a Gno package in-process, in the
+// manner of "gno test", using the gnovm test machinery.
+package gnotest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/gnolang/gno/gnovm/pkg/gnoenv"
+	gno "github.com/gnolang/gno/gnovm/pkg/gnolang"
+	"github.com/gnolang/gno/gnovm/tests"
+	"github.com/gnolang/gno/tm2/pkg/std"
+)
+
+// maxAllocTx is the allocation limit of a test machine, as in "gno test".
+const maxAllocTx = 500 * 1000 * 1000
+
+// Options configures a test run.
+type Options struct {
+	// RootDir is the GNOROOT directory used to load the standard
+	// libraries and the examples imported by the tests.
+	// If empty, it is guessed from the environment.
+	RootDir string
+
+	// Run, if set, is a regular expression selecting the tests to run,
+	// as the -run flag of "gno test".
+	Run string
+
+	// Verbose enables the verbose output of the gno testing package.
+	Verbose bool
+
+	// Output receives the output of the tests as they run.
+	// It may be nil.
+	Output io.Writer
+}
+
+// A Result reports the outcome of a single test function.
+type Result struct {
+	Name     string        // name of the test function
+	PkgPath  string        // path of its package, ending in "_test" for an external test
+	Failed   bool          // whether the test failed
+	Skipped  bool          // whether the test was skipped
+	Output   string        // output produced while running the test
+	Duration time.Duration // time spent running the test
+}
+
+// report is the JSON report returned by testing.RunTest.
+type report struct {
+	Failed  bool
+	Skipped bool
+}
+
+// Run runs the tests of the package in dir, whose package path is
+// pkgPath, and returns the result of each test function that was run,
+// in-package tests first.
+//
+// Imports are resolved against the standard libraries and the examples
+// of the Gno root directory, as "gno test" does. Run reads the files
+// from disk: unsaved changes are not taken into account.
+func Run(ctx context.Context, dir, pkgPath string, opts Options) (_ []Result, err error) {
+	if opts.RootDir == "" {
+		opts.RootDir, err = gnoenv.GuessRootDir()
+		if err != nil {
+			return nil, fmt.Errorf("unable to guess gno root dir: %w", err)
+		}
+	}
+	if opts.Output == nil {
+		opts.Output = io.Discard
+	}
+	if opts.Run != "" {
+		if _, err := regexp.Compile(opts.Run); err != nil {
+			return nil, fmt.Errorf("invalid -run regexp %q: %w", opts.Run, err)
+		}
+	}
+
+	defer func() {
+		// The gnovm reports most errors, including those of
+		// ReadMemPackage, by panicking.
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic while running tests of %q: %v", pkgPath, r)
+		}
+	}()
+
+	memPkg := gno.ReadMemPackage(dir, pkgPath)
+	tfiles, ifiles := gno.ParseMemPackageTests(memPkg)
+
+	// The output of the machine goes through a buffer, reset before
+	// each test, so that the output of each test can be reported along
+	// with its result.
+	buf := new(bytes.Buffer)
+	stdout := io.MultiWriter(opts.Output, buf)
+	store := tests.TestStore(opts.RootDir, "", new(bytes.Buffer), stdout, stdout, tests.ImportModeStdlibsOnly)
+
+	// Load the package under test first: the in-package tests extend
+	// it, and the external tests import it.
+	m := newMachine(store, stdout, pkgPath)
+	defer m.Release()
+	m.RunMemPackage(memPkg, true)
+
+	var results []Result
+
+	// Run the in-package tests.
+	if len(tfiles.Files) > 0 {
+		res, err := runTestFiles(ctx, m, tfiles, memPkg.Name, pkgPath, buf, opts)
+		results = append(results, res...)
+		if err != nil {
+			return results, err
+		}
+	}
+
+	// Run the tests of the external test package.
+	if len(ifiles.Files) > 0 {
+		testPkgName := fileSetPkgName(ifiles)
+		xm := newMachine(store, stdout, testPkgName)
+		defer xm.Release()
+
+		names := make(map[string]bool)
+		for _, f := range ifiles.Files {
+			names[string(f.Name)] = true
+		}
+		var memFiles []*std.MemFile
+		for _, f := range memPkg.Files {
+			if f.Name == "gno.mod" || names[f.Name] {
+				memFiles = append(memFiles, f)
+			}
+		}
+		xm.RunMemPackage(&std.MemPackage{
+			Name:  testPkgName,
+			Path:  pkgPath + "_test",
+			Files: memFiles,
+		}, true)
+		res, err := runTestFiles(ctx, xm, ifiles, testPkgName, pkgPath+"_test", buf, opts)
+		results = append(results, res...)
+		if err != nil {
+			return results, err
+		}
+	}
+
+	return results, nil
+}
+
+func newMachine(store gno.Store, output io.Writer, pkgPath string) *gno.Machine {
+	return gno.NewMachineWithOptions(gno.MachineOptions{
+		PkgPath:       "",
+		Output:        output,
+		Store:         store,
+		Context:       tests.TestContext(pkgPath, std.Coins{}),
+		MaxAllocBytes: maxAllocTx,
+	})
+}
+
+// runTestFiles runs the test functions declared in files, which
+// must belong to the package pkgName, of path pkgPath, loaded by m.
+func runTestFiles(ctx context.Context, m *gno.Machine, files *gno.FileSet, pkgName, pkgPath string, buf *bytes.Buffer, opts Options) ([]Result, error) {
+	var names []string
+	for _, f := range files.Files {
+		for _, d := range f.Decls {
+			if fd, ok := d.(*gno.FuncDecl); ok && !fd.IsMethod && isTestName(string(fd.Name)) {
+				names = append(names, string(fd.Name))
+			}
+		}
+	}
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	var testmain bytes.Buffer
+	if err := testmainTmpl.Execute(&testmain, testmainData{
+		PackageName: pkgName,
+		Tests:       names,
+		RunFlag:     opts.Run,
+		Verbose:     opts.Verbose,
+	}); err != nil {
+		return nil, fmt.Errorf("unable to generate testmain: %w", err)
+	}
+
+	m.RunFiles(files.Files...)
+	m.RunFiles(gno.MustParseFile("main_test.gno", testmain.String()))
+
+	var results []Result
+	for _, name := range names {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+
+		buf.Reset()
+		start := time.Now()
+		ret := m.Eval(gno.Call("runtest", fmt.Sprintf("%q", name)))
+		res := Result{
+			Name:     name,
+			PkgPath:  pkgPath,
+			Output:   buf.String(),
+			Duration: time.Since(start),
+		}
+
+		var rep report
+		if len(ret) == 0 || json.Unmarshal([]byte(ret[0].GetString()), &rep) != nil {
+			// The test was filtered out by the -run flag, or the
+			// testing package failed to report.
+			if opts.Run != "" && !matchRun(opts.Run, name) {
+				continue
+			}
+			rep.Failed = true
+		}
+		res.Failed, res.Skipped = rep.Failed, rep.Skipped
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+// isTestName reports whether name is the name of a test function:
+// TestFoo or Test but not Testable.
+func isTestName(name string) bool {
+	rest, ok := strings.CutPrefix(name, "Test")
+	return ok && (rest == "" || !('a' <= rest[0] && rest[0] <= 'z'))
+}
+
+// matchRun reports whether the top-level test name is selected by the
+// -run pattern, which must be a valid regular expression. As with
+// "go test", the pattern is split by slashes to match subtests, and
+// only its first element applies to top-level tests.
+func matchRun(pattern, name string) bool {
+	top, _, _ := strings.Cut(pattern, "/")
+	return regexp.MustCompile(top).MatchString(name)
+}
+
+// fileSetPkgName returns the package name of the first file of fs.
+func fileSetPkgName(fs *gno.FileSet) string {
+	if len(fs.Files) == 0 {
+		return ""
+	}
+	return string(fs.Files[0].PkgName)
+}
+
+type testmainData struct {
+	PackageName string
+	Tests       []string
+	RunFlag     string
+	Verbose     bool
+}
+
+// testmainTmpl is the entry point of the tests of a package, in the
+// manner of the testmain.go file generated by "go test".
+var testmainTmpl = template.Must(template.New("testmain").Parse(`
+package {{ .PackageName }}
+
+import (
+	"testing"
+)
+
+var tests = []testing.InternalTest{
+{{range .Tests}}
+	{"{{.}}", {{.}}},
+{{end}}
+}
+
+func runtest(name string) (report string) {
+	for _, test := range tests {
+		if test.Name == name {
+			return testing.RunTest({{printf "%q" .RunFlag}}, {{.Verbose}}, test)
+		}
+	}
+	panic("no such test: " + name)
+	return ""
+}
+`))
@@ -0,0 +1,109 @@
+package gnotest
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIsTestName(t *testing.T) {
+	for name, want := range map[string]bool{
+		"Test":       true,
+		"TestFoo":    true,
+		"Test_foo":   true,
+		"Test1":      true,
+		"Testable":   false,
+		"testFoo":    false,
+		"BenchFoo":   false,
+		"ExampleFoo": false,
+	} {
+		if got := isTestName(name); got != want {
+			t.Errorf("isTestName(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestMatchRun(t *testing.T) {
+	tests := []struct {
+		pattern, name string
+		want          bool
+	}{
+		{"^(TestFoo)$", "TestFoo", true},
+		{"^(TestFoo)$", "TestFooBar", false},
+		{"^(TestFoo|TestBar)$", "TestBar", true},
+		{"TestFoo/sub", "TestFoo", true},
+		{"TestFoo/sub", "TestBar", false},
+	}
+	for _, test := range tests {
+		if got := matchRun(test.pattern, test.name); got != test.want {
+			t.Errorf("matchRun(%q, %q) = %v, want %v", test.pattern, test.name, got, test.want)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	// Run needs the standard libraries of a Gno root directory.
+	rootDir := os.Getenv("GNOROOT")
+	if _, err := os.Stat(filepath.Join(rootDir, "gnovm", "stdlibs", "testing")); rootDir == "" || err != nil {
+		t.Skip("no Gno standard libraries in $GNOROOT")
+	}
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"gno.mod": "module gno.land/p/demo/foo\n",
+		"foo.gno": "package foo\n\nfunc Foo() int { return 1 }\n",
+		"foo_test.gno": `package foo
+
+import "testing"
+
+func TestPass(t *testing.T) {
+	if Foo() != 1 {
+		t.Errorf("Foo() = %d, want 1", Foo())
+	}
+}
+
+func TestFail(t *testing.T) {
+	if Foo() != 2 {
+		t.Errorf("Foo() = %d, want 2", Foo())
+	}
+}
+`,
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		run  string
+		want map[string]bool // whether each test that was run failed
+	}{
+		{"", map[string]bool{"TestPass": false, "TestFail": true}},
+		{"^(TestPass)$", map[string]bool{"TestPass": false}},
+		{"^(TestFail)$", map[string]bool{"TestFail": true}},
+	}
+	for _, test := range tests {
+		results, err := Run(context.Background(), dir, "gno.land/p/demo/foo", Options{RootDir: rootDir, Run: test.run})
+		if err != nil {
+			t.Fatalf("Run(%q): %v", test.run, err)
+		}
+		got := make(map[string]bool)
+		for _, res := range results {
+			got[res.Name] = res.Failed
+			if res.Failed && !strings.Contains(res.Output, "Foo() = 1, want 2") {
+				t.Errorf("Run(%q): output of %s = %q, want the error of the test", test.run, res.Name, res.Output)
+			}
+		}
+		if len(got) != len(test.want) {
+			t.Errorf("Run(%q) = %v, want %v", test.run, got, test.want)
+			continue
+		}
+		for name, failed := range test.want {
+			if f, ok := got[name]; !ok || f != failed {
+				t.Errorf("Run(%q) = %v, want %v", test.run, got, test.want)
+				break
+			}
+		}
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"strings"
 
 	"github.com/gfanton/gnopls/internal/cache"
+	"github.com/gfanton/gnopls/internal/cache/metadata"
 	"github.com/gfanton/gnopls/internal/cache/parsego"
 	"github.com/gfanton/gnopls/internal/file"
 	"github.com/gfanton/gnopls/internal/protocol"
@@ -27,6 +28,10 @@ func CodeLensSources() map[settings.CodeLensSource]cache.CodeLensSourceFunc {
 		settings.CodeLensTest:          runTestCodeLens,       // commands: Test
 		settings.CodeLensRegenerateCgo: regenerateCgoLens,     // commands: RegenerateCgo
 		settings.CodeLensGCDetails:     toggleDetailsCodeLens, // commands: GCDetails
+		settings.CodeLensRender:        renderCodeLens,        // commands: RenderPreview
+		settings.CodeLensRealmState:    realmStateCodeLens,    // commands: RealmState
+		settings.CodeLensEstimate:      estimateCodeLens,      // commands: Estimate
+		settings.CodeLensMakeTx:        makeTxCodeLens,        // commands: MakeTx
 	}
 }
 
@@ -79,6 +84,135 @@ func runTestCodeLens(ctx context.Context, snapshot *cache.Snapshot, fh file.Hand
 	return codeLens, nil
 }
 
+// renderCodeLens annotates the Render function of a realm with a
+// command to preview its output in a browser.
+func renderCodeLens(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle) ([]protocol.CodeLens, error) {
+	pkg, pgf, err := NarrowestPackageForFile(ctx, snapshot, fh.URI())
+	if err != nil {
+		return nil, err
+	}
+	mp := pkg.Metadata()
+	if !metadata.IsRealmPath(mp.PkgPath) || strings.HasSuffix(pgf.URI.Path(), "_test.gno") {
+		return nil, nil
+	}
+	for _, decl := range pgf.File.Decls {
+		fd, ok := decl.(*ast.FuncDecl)
+		if !ok || !isRenderFunc(pkg.TypesInfo(), fd) {
+			continue
+		}
+		rng, err := pgf.PosRange(fd.Pos(), fd.Pos())
+		if err != nil {
+			return nil, err
+		}
+		cmd := command.NewRenderPreviewCommand("preview Render", snapshot.View().ID(), string(mp.ID))
+		return []protocol.CodeLens{{Range: rng, Command: cmd}}, nil
+	}
+	return nil, nil
+}
+
+// realmStateCodeLens annotates the package clause of a realm with a
+// command to inspect its state in a browser.
+func realmStateCodeLens(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle) ([]protocol.CodeLens, error) {
+	mps, err := snapshot.MetadataForFile(ctx, fh.URI())
+	if err != nil || len(mps) == 0 {
+		return nil, err
+	}
+	mp := mps[0] // narrowest
+	if !metadata.IsRealmPath(mp.PkgPath) || strings.HasSuffix(fh.URI().Path(), "_test.gno") {
+		return nil, nil
+	}
+	pgf, err := snapshot.ParseGo(ctx, fh, parsego.Header)
+	if err != nil {
+		return nil, err
+	}
+	rng, err := pgf.PosRange(pgf.File.Package, pgf.File.Package)
+	if err != nil {
+		return nil, err
+	}
+	cmd := command.NewRealmStateCommand("inspect realm state", snapshot.View().ID(), string(mp.ID))
+	return []protocol.CodeLens{{Range: rng, Command: cmd}}, nil
+}
+
+// estimateCodeLens annotates each exported function of a realm with a
+// command to estimate the costs of calling it with the zero values of
+// its parameters.
+func estimateCodeLens(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle) ([]protocol.CodeLens, error) {
+	pkg, pgf, err := NarrowestPackageForFile(ctx, snapshot, fh.URI())
+	if err != nil {
+		return nil, err
+	}
+	if !metadata.IsRealmPath(pkg.Metadata().PkgPath) || strings.HasSuffix(pgf.URI.Path(), "_test.gno") {
+		return nil, nil
+	}
+	var codeLens []protocol.CodeLens
+	for _, decl := range pgf.File.Decls {
+		fd, ok := decl.(*ast.FuncDecl)
+		if !ok || fd.Recv != nil || !fd.Name.IsExported() {
+			continue
+		}
+		fn, ok := pkg.TypesInfo().Defs[fd.Name].(*types.Func)
+		if !ok {
+			continue
+		}
+		args, ok := zeroArgs(pkg.Types(), fn.Signature())
+		if !ok {
+			continue
+		}
+		rng, err := pgf.PosRange(fd.Pos(), fd.Pos())
+		if err != nil {
+			return nil, err
+		}
+		cmd := command.NewEstimateCommand("estimate", command.EstimateArgs{
+			URI:      fh.URI(),
+			Function: fd.Name.Name,
+			Args:     args,
+		})
+		codeLens = append(codeLens, protocol.CodeLens{Range: rng, Command: cmd})
+	}
+	return codeLens, nil
+}
+
+// makeTxCodeLens annotates each exported function of a realm with
+// commands to generate a gnokey transaction calling it.
+func makeTxCodeLens(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle) ([]protocol.CodeLens, error) {
+	pkg, pgf, err := NarrowestPackageForFile(ctx, snapshot, fh.URI())
+	if err != nil {
+		return nil, err
+	}
+	if !metadata.IsRealmPath(pkg.Metadata().PkgPath) || strings.HasSuffix(pgf.URI.Path(), "_test.gno") {
+		return nil, nil
+	}
+	var codeLens []protocol.CodeLens
+	for _, decl := range pgf.File.Decls {
+		fd, ok := decl.(*ast.FuncDecl)
+		if !ok || fd.Recv != nil || !fd.Name.IsExported() {
+			continue
+		}
+		fn, ok := pkg.TypesInfo().Defs[fd.Name].(*types.Func)
+		if !ok {
+			continue
+		}
+		rng, err := pgf.PosRange(fd.Pos(), fd.Pos())
+		if err != nil {
+			return nil, err
+		}
+		lens := func(title, kind string) protocol.CodeLens {
+			cmd := command.NewMakeTxCommand(title, command.MakeTxArgs{
+				URI:      fh.URI(),
+				Function: fd.Name.Name,
+				Kind:     kind,
+			})
+			return protocol.CodeLens{Range: rng, Command: cmd}
+		}
+		if hasPrimitiveParams(fn.Signature()) {
+			codeLens = append(codeLens, lens("maketx call", MakeTxCall), lens("MsgCall tx", MakeTxJSON))
+		} else {
+			codeLens = append(codeLens, lens("maketx run", MakeTxRun))
+		}
+	}
+	return codeLens, nil
+}
+
 type testFunc struct {
 	name string
 	rng  protocol.Range // of *ast.FuncDecl
@@ -87,7 +221,7 @@ type testFunc struct {
 // testsAndBenchmarks returns all Test and Benchmark functions in the
 // specified file.
 func testsAndBenchmarks(info *types.Info, pgf *parsego.File) (tests, benchmarks []testFunc, _ error) {
-	if !strings.HasSuffix(pgf.URI.Path(), "_test.go") {
+	if !strings.HasSuffix(pgf.URI.Path(), "_test.gno") {
 		return nil, nil, nil // empty
 	}
 
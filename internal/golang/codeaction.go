@@ -19,6 +19,7 @@ import (
 	"github.com/gfanton/gnopls/internal/cache"
 	"github.com/gfanton/gnopls/internal/cache/parsego"
 	"github.com/gfanton/gnopls/internal/file"
+	"github.com/gfanton/gnopls/internal/filetest"
 	"github.com/gfanton/gnopls/internal/label"
 	"github.com/gfanton/gnopls/internal/protocol"
 	"github.com/gfanton/gnopls/internal/protocol/command"
@@ -116,6 +117,12 @@ func CodeActions(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle,
 		add(cmd, kind)
 	}
 
+	if kind := settings.GoUpdateFiletest; enabled(kind) && filetest.IsFiletest(pgf.URI.Path()) {
+		cmd := command.NewUpdateFiletestCommand("Update filetest expectations", command.URIArg{URI: pgf.URI})
+		// For implementation, see commandHandler.UpdateFiletest.
+		add(cmd, kind)
+	}
+
 	if kind := settings.GoplsDocFeatures; enabled(kind) {
 		// TODO(adonovan): after the docs are published in gopls/v0.17.0,
 		// use the gopls release tag instead of master.
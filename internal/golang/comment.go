@@ -7,7 +7,6 @@ package golang
 import (
 	"context"
 	"errors"
-	"fmt"
 	"go/ast"
 	"go/doc/comment"
 	"go/token"
@@ -38,15 +37,12 @@ func CommentToMarkdown(text string, options *settings.Options) string {
 	// avoid a security problem.
 	pr.HeadingID = func(*comment.Heading) string { return "" }
 	pr.DocLinkURL = func(link *comment.DocLink) string {
-		msg := fmt.Sprintf("https://%s/%s", options.LinkTarget, link.ImportPath)
-		if link.Name != "" {
-			msg += "#"
-			if link.Recv != "" {
-				msg += link.Recv + "."
-			}
-			msg += link.Name
+		anchor := link.Name
+		if anchor != "" && link.Recv != "" {
+			anchor = link.Recv + "." + anchor
 		}
-		return msg
+		// The kind of the symbol is unknown: link a realm to its source.
+		return cache.DocLink(options.GnowebLinks, options.LinkTarget, link.ImportPath, anchor, cache.RealmSource)
 	}
 	easy := pr.Markdown(doc)
 	return string(easy)
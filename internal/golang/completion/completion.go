@@ -120,6 +120,7 @@ type completionOptions struct {
 	placeholders          bool
 	snippets              bool
 	postfix               bool
+	postfixSnippets       map[string]string
 	matcher               settings.Matcher
 	budget                time.Duration
 	completeFunctionCalls bool
@@ -512,7 +513,10 @@ func Completion(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle, p
 				break
 			}
 		}
-		return nil, nil, nil
+		// The path passed to the Render function of a realm is completed
+		// with its routes.
+		items, sel := routeCompletions(ctx, snapshot, pkg, pgf, path, pos)
+		return items, sel, nil
 	case *ast.CallExpr:
 		if n.Ellipsis.IsValid() && pos > n.Ellipsis && pos <= n.Ellipsis+token.Pos(len("...")) {
 			// Don't offer completions inside or directly after "...". For
@@ -585,6 +589,7 @@ func Completion(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle, p
 			budget:                opts.CompletionBudget,
 			snippets:              opts.InsertTextFormat == protocol.SnippetTextFormat,
 			postfix:               opts.ExperimentalPostfixCompletions,
+			postfixSnippets:       opts.PostfixSnippets,
 			completeFunctionCalls: opts.CompleteFunctionCalls,
 		},
 		// default to a matcher that always matches
@@ -12,6 +12,7 @@ import (
 	"go/types"
 	"log"
 	"reflect"
+	"sort"
 	"strings"
 	"sync"
 	"text/template"
@@ -20,6 +21,7 @@ import (
 	"github.com/gfanton/gnopls/internal/golang"
 	"github.com/gfanton/gnopls/internal/golang/completion/snippet"
 	"github.com/gfanton/gnopls/internal/protocol"
+	"github.com/gfanton/gnopls/internal/settings"
 	"github.com/gfanton/gnopls/internal/util/safetoken"
 	"github.com/gfanton/gnopls/internal/aliases"
 	"github.com/gfanton/gnopls/internal/event"
@@ -44,6 +46,10 @@ type postfixTmpl struct {
 	// facilities available to the template.
 	body string
 
+	// gno is true for the snippets of Gno idioms, offered only in realm
+	// and pure packages.
+	gno bool
+
 	tmpl *template.Template
 }
 
@@ -335,6 +341,50 @@ if {{$errName | $a.SpecifiedPlaceholder 1}} != nil {
 	{{end}}
 }
 {{end}}`,
+}, {
+	label:   "assertcaller",
+	details: "panic unless called by address",
+	gno:     true,
+	body: `{{if and (.IsNamed "std" "Address") .StmtOK -}}
+if {{.Import "std"}}.PrevRealm().Addr() != {{if eq .Kind "pointer"}}*{{end}}{{.X}} {
+	panic("{{.Placeholder "unauthorized"}}")
+}
+{{- end}}`,
+}, {
+	label:   "emit",
+	details: "std.Emit(event)",
+	gno:     true,
+	body: `{{if and (eq (.TypeName .Type) "string") .StmtOK -}}
+{{.Import "std"}}.Emit({{.X}}{{.Cursor}})
+{{- end}}`,
+}, {
+	label:   "iterate",
+	details: "iterate over tree",
+	gno:     true,
+	body: `{{if and (.IsNamed "gno.land/p/demo/avl" "Tree") .StmtOK -}}
+{{.X}}.Iterate("", "", func({{.VarName nil "key" | .Placeholder}} string, {{.VarName nil "value" | .Placeholder}} interface{}) bool {
+	{{.Cursor}}
+	return false
+})
+{{- end}}`,
+}, {
+	label:   "panicifempty",
+	details: "panic if empty",
+	gno:     true,
+	body: `{{if and (eq (.TypeName .Type.Underlying) "string") .StmtOK -}}
+if {{.X}} == "" {
+	panic("{{.Placeholder (printf "%s is empty" (.EscapeQuotes .X))}}")
+}
+{{- end}}`,
+}, {
+	label:   "panicifempty",
+	details: "panic if empty",
+	gno:     true,
+	body: `{{if and (eq .Kind "slice" "map") .StmtOK -}}
+if len({{.X}}) == 0 {
+	panic("{{.Placeholder (printf "%s is empty" (.EscapeQuotes .X))}}")
+}
+{{- end}}`,
 }}
 
 // Cursor indicates where the client's cursor should end up after the
@@ -446,6 +496,17 @@ func (a *postfixTmplArgs) Zero(t types.Type) string {
 	return formatZeroValue(t, a.qf)
 }
 
+// IsNamed reports whether the type of X, or the type it points to, is
+// the named type name of the package path.
+func (a *postfixTmplArgs) IsNamed(path, name string) bool {
+	named, ok := aliases.Unalias(typesinternal.Unpointer(a.Type)).(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == path && obj.Name() == name
+}
+
 func (a *postfixTmplArgs) IsIdent() bool {
 	_, ok := a.sel.X.(*ast.Ident)
 	return ok
@@ -571,7 +632,14 @@ func (c *completer) addPostfixSnippetCandidates(ctx context.Context, sel *ast.Se
 		afterDot = c.pos
 	}
 
-	for _, rule := range postfixTmpls {
+	pkgPath := c.pkg.Metadata().PkgPath
+	gno := metadata.IsRealmPath(pkgPath) || metadata.IsPurePath(pkgPath)
+
+	for _, rule := range append(postfixTmpls, c.userPostfixTmpls()...) {
+		if rule.gno && !gno {
+			continue
+		}
+
 		// When completing foo.print<>, "print" is naturally overwritten,
 		// but we need to also remove "foo." so the snippet has a clean
 		// slate.
@@ -625,14 +693,43 @@ func (c *completer) addPostfixSnippetCandidates(ctx context.Context, sel *ast.Se
 
 var postfixRulesOnce sync.Once
 
+// userPostfixTemplates holds the parsed templates of the
+// postfixSnippets setting, by body.
+var userPostfixTemplates sync.Map // string -> *template.Template
+
+// userPostfixTmpls returns the postfix snippets of the
+// postfixSnippets setting, sorted by label. Their templates were
+// validated with the setting, and are parsed once.
+func (c *completer) userPostfixTmpls() []postfixTmpl {
+	var rules []postfixTmpl
+	for label, body := range c.opts.postfixSnippets {
+		tmpl, ok := userPostfixTemplates.Load(body)
+		if !ok {
+			parsed, err := settings.ParsePostfixSnippet(body)
+			if err != nil {
+				continue // reported by the setting
+			}
+			tmpl, _ = userPostfixTemplates.LoadOrStore(body, parsed)
+		}
+		rules = append(rules, postfixTmpl{
+			label:   label,
+			details: "user postfix snippet",
+			body:    body,
+			tmpl:    tmpl.(*template.Template),
+		})
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].label < rules[j].label
+	})
+	return rules
+}
+
 func initPostfixRules() {
 	postfixRulesOnce.Do(func() {
 		var idx int
 		for _, rule := range postfixTmpls {
 			var err error
-			rule.tmpl, err = template.New("postfix_snippet").Funcs(template.FuncMap{
-				"inc": inc,
-			}).Parse(rule.body)
+			rule.tmpl, err = settings.ParsePostfixSnippet(rule.body)
 			if err != nil {
 				log.Panicf("error parsing postfix snippet template: %v", err)
 			}
@@ -643,10 +740,6 @@ func initPostfixRules() {
 	})
 }
 
-func inc(i int) int {
-	return i + 1
-}
-
 // importIfNeeded returns the package identifier and any necessary
 // edits to import package pkgPath.
 func (c *completer) importIfNeeded(pkgPath string, scope *types.Scope) (string, []protocol.TextEdit, error) {
@@ -0,0 +1,88 @@
+package completion
+
+import (
+	"fmt"
+	"go/types"
+	"strings"
+	"testing"
+
+	"github.com/gfanton/gnopls/internal/protocol"
+)
+
+func TestPostfixIsNamed(t *testing.T) {
+	named := func(path, name string, underlying types.Type) *types.Named {
+		pkg := types.NewPackage(path, path[len(path)-3:])
+		return types.NewNamed(types.NewTypeName(0, pkg, name, nil), underlying, nil)
+	}
+	address := named("std", "Address", types.Typ[types.String])
+	tree := named("gno.land/p/demo/avl", "Tree", types.NewStruct(nil, nil))
+
+	for _, test := range []struct {
+		typ        types.Type
+		path, name string
+		want       bool
+	}{
+		{address, "std", "Address", true},
+		{types.NewPointer(tree), "gno.land/p/demo/avl", "Tree", true},
+		{tree, "std", "Address", false},
+		{types.Typ[types.String], "std", "Address", false},
+	} {
+		a := &postfixTmplArgs{Type: test.typ}
+		if got := a.IsNamed(test.path, test.name); got != test.want {
+			t.Errorf("IsNamed(%s, %q, %q) = %t, want %t", test.typ, test.path, test.name, got, test.want)
+		}
+	}
+}
+
+func TestUserPostfixTmpls(t *testing.T) {
+	c := &completer{opts: &completionOptions{postfixSnippets: map[string]string{
+		"owner":   `{{if .StmtOK}}{{.X}}.AssertCallerIsOwner(){{end}}`,
+		"broken":  `{{if .StmtOK}`,
+		"counted": `{{inc 1}}`,
+	}}}
+	var labels []string
+	for _, rule := range c.userPostfixTmpls() {
+		if rule.tmpl == nil || rule.gno {
+			t.Errorf("user snippet %q: template %v, gno %t", rule.label, rule.tmpl, rule.gno)
+		}
+		labels = append(labels, rule.label)
+	}
+	if got, want := fmt.Sprint(labels), "[counted owner]"; got != want {
+		t.Errorf("user snippets = %s, want %s", got, want)
+	}
+}
+
+func TestPostfixAssertCaller(t *testing.T) {
+	initPostfixRules()
+	var assertcaller *postfixTmpl
+	for i := range postfixTmpls {
+		if postfixTmpls[i].label == "assertcaller" {
+			assertcaller = &postfixTmpls[i]
+		}
+	}
+	std := types.NewPackage("std", "std")
+	address := types.NewNamed(types.NewTypeName(0, std, "Address", nil), types.Typ[types.String], nil)
+
+	for _, test := range []struct {
+		typ  types.Type
+		want string // compared operand
+	}{
+		{address, "!= addr {"},
+		{types.NewPointer(address), "!= *addr {"},
+	} {
+		a := &postfixTmplArgs{
+			X:      "addr",
+			StmtOK: true,
+			Type:   test.typ,
+			importIfNeeded: func(path string, scope *types.Scope) (string, []protocol.TextEdit, error) {
+				return path, nil, nil
+			},
+		}
+		if err := assertcaller.tmpl.Execute(&a.snip, a); err != nil {
+			t.Fatal(err)
+		}
+		if got := a.snip.String(); !strings.Contains(got, test.want) {
+			t.Errorf("assertcaller on %s = %q, want %q", test.typ, got, test.want)
+		}
+	}
+}
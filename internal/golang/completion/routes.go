@@ -0,0 +1,152 @@
+package completion
+
+// This file completes the path passed to the Render function of a
+// realm, e.g. in its tests, with the routes of the realm.
+//
+// See also:
+// - ../../analysis/renderroutes - finds the routes of a realm.
+// - ../routes.go - the routes as document symbols, and their handlers.
+
+import (
+	"context"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+
+	"github.com/gfanton/gnopls/internal/analysis/renderroutes"
+	"github.com/gfanton/gnopls/internal/cache"
+	"github.com/gfanton/gnopls/internal/cache/metadata"
+	"github.com/gfanton/gnopls/internal/cache/parsego"
+	"github.com/gfanton/gnopls/internal/protocol"
+)
+
+// routeCompletions returns the routes of a realm, as completions of the
+// string literal path[0] passed to its Render function, or nil if
+// path[0] is not such a literal.
+//
+// A pattern is completed up to its first parameter or wildcard segment,
+// e.g. "users/" for "users/{name}".
+func routeCompletions(ctx context.Context, snapshot *cache.Snapshot, pkg *cache.Package, pgf *parsego.File, path []ast.Node, pos token.Pos) ([]CompletionItem, *Selection) {
+	lit, ok := path[0].(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING || len(path) < 2 {
+		return nil, nil
+	}
+	call, ok := path[1].(*ast.CallExpr)
+	if !ok || len(call.Args) != 1 || call.Args[0] != lit {
+		return nil, nil
+	}
+	render := renderFunc(pkg.TypesInfo(), call.Fun)
+	if render == nil {
+		return nil, nil
+	}
+
+	// The cursor must be within the quotes of the literal.
+	start, end := lit.Pos()+1, lit.End()-1
+	if lit.Value[0] == '`' || pos < start || pos > end || end < start {
+		return nil, nil
+	}
+	prefix := lit.Value[1 : pos-lit.Pos()]
+
+	files := realmFiles(ctx, snapshot, pkg, metadata.PackagePath(render.Pkg().Path()))
+	var items []CompletionItem
+	seen := make(map[string]bool)
+	for _, f := range files {
+		for _, group := range renderroutes.Find(f, nil) {
+			for _, route := range group {
+				text := route.Path
+				if route.Kind == renderroutes.Pattern {
+					text = patternPrefix(text)
+				}
+				if text == "" || seen[text] || !strings.HasPrefix(text, prefix) {
+					continue
+				}
+				seen[text] = true
+				items = append(items, CompletionItem{
+					Label:      text,
+					Detail:     route.Kind.String() + " " + strconv.Quote(route.Path),
+					InsertText: text,
+					Kind:       protocol.ValueCompletion,
+					Score:      stdScore,
+				})
+			}
+		}
+	}
+	if len(items) == 0 {
+		return nil, nil
+	}
+	return items, &Selection{
+		content: prefix,
+		cursor:  pos,
+		tokFile: pgf.Tok,
+		start:   start,
+		end:     end,
+		mapper:  pgf.Mapper,
+	}
+}
+
+// renderFunc returns the Render function of a realm called by fun, or
+// nil if fun is not such a function.
+func renderFunc(info *types.Info, fun ast.Expr) *types.Func {
+	var id *ast.Ident
+	switch fun := ast.Unparen(fun).(type) {
+	case *ast.Ident:
+		id = fun
+	case *ast.SelectorExpr:
+		id = fun.Sel
+	default:
+		return nil
+	}
+	fn, ok := info.Uses[id].(*types.Func)
+	if !ok || fn.Name() != "Render" || fn.Pkg() == nil || fn.Type().(*types.Signature).Recv() != nil {
+		return nil
+	}
+	if !metadata.IsRealmPath(metadata.PackagePath(fn.Pkg().Path())) {
+		return nil
+	}
+	return fn
+}
+
+// realmFiles returns the syntax of the files of the realm pkgPath: the
+// files of pkg, if it is that realm, or else those of its dependency.
+func realmFiles(ctx context.Context, snapshot *cache.Snapshot, pkg *cache.Package, pkgPath metadata.PackagePath) []*ast.File {
+	var files []*ast.File
+	if pkg.Metadata().PkgPath == pkgPath {
+		for _, pgf := range pkg.CompiledGoFiles() {
+			files = append(files, pgf.File)
+		}
+		return files
+	}
+	mp := snapshot.Metadata(pkg.Metadata().DepsByPkgPath[pkgPath])
+	if mp == nil {
+		return nil
+	}
+	for _, uri := range mp.CompiledGoFiles {
+		fh, err := snapshot.ReadFile(ctx, uri)
+		if err != nil {
+			continue
+		}
+		pgf, err := snapshot.ParseGo(ctx, fh, parsego.Full)
+		if err != nil {
+			continue
+		}
+		files = append(files, pgf.File)
+	}
+	return files
+}
+
+// patternPrefix returns the part of a mux pattern before its first
+// parameter or wildcard segment.
+func patternPrefix(pattern string) string {
+	segments := strings.Split(pattern, "/")
+	for i, segment := range segments {
+		if segment == "*" || strings.HasPrefix(segment, "{") {
+			if i == 0 {
+				return ""
+			}
+			return strings.Join(segments[:i], "/") + "/"
+		}
+	}
+	return pattern
+}
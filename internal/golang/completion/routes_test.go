@@ -0,0 +1,73 @@
+package completion
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+)
+
+func TestPatternPrefix(t *testing.T) {
+	for _, test := range []struct {
+		pattern, want string
+	}{
+		{"", ""},
+		{"about", "about"},
+		{"users/{name}", "users/"},
+		{"users/{name}/posts", "users/"},
+		{"files/*", "files/"},
+		{"{dir}/*", ""},
+		{"*", ""},
+	} {
+		if got := patternPrefix(test.pattern); got != test.want {
+			t.Errorf("patternPrefix(%q) = %q, want %q", test.pattern, got, test.want)
+		}
+	}
+}
+
+func TestRenderFunc(t *testing.T) {
+	const src = `package routes
+
+type T struct{}
+
+func (T) Render(path string) string { return "" }
+
+func Render(path string) string { return "" }
+
+func render(path string) string { return "" }
+
+var (
+	_ = Render("")
+	_ = T{}.Render("")
+	_ = render("")
+)
+`
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "routes.gno", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		path string
+		want []bool // whether each call is to the Render function of a realm
+	}{
+		{"gno.land/r/demo/routes", []bool{true, false, false}},
+		{"gno.land/p/demo/routes", []bool{false, false, false}},
+	} {
+		info := &types.Info{Uses: make(map[*ast.Ident]types.Object)}
+		if _, err := new(types.Config).Check(test.path, fset, []*ast.File{f}, info); err != nil {
+			t.Fatal(err)
+		}
+		i := 0
+		ast.Inspect(f, func(n ast.Node) bool {
+			if call, ok := n.(*ast.CallExpr); ok {
+				if got := renderFunc(info, call.Fun) != nil; got != test.want[i] {
+					t.Errorf("%s: renderFunc(%s) = %t, want %t", test.path, types.ExprString(call.Fun), got, test.want[i])
+				}
+				i++
+			}
+			return true
+		})
+	}
+}
@@ -84,6 +84,12 @@ func Definition(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle, p
 		return locations, err // may be success or failure
 	}
 
+	// Handle the case where the cursor is in the route of a realm.
+	locations, err = routeDefinition(ctx, snapshot, pkg, pgf, pos)
+	if !errors.Is(err, errNoRoute) {
+		return locations, err // may be success or failure
+	}
+
 	// The general case: the cursor is on an identifier.
 	_, obj, _ := referencedObject(pkg, pgf, pos)
 	if obj == nil {
@@ -118,6 +124,12 @@ func Definition(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle, p
 // builtinDefinition returns the location of the fake source
 // declaration of a built-in in {builtin,unsafe}.go.
 func builtinDefinition(ctx context.Context, snapshot *cache.Snapshot, obj types.Object) ([]protocol.Location, error) {
+	// The Gno predeclared identifiers (see cache.declareGnoUniverse)
+	// have no declaration syntax.
+	if obj.Pkg() != nil && obj.Pkg() != types.Unsafe {
+		return nil, nil
+	}
+
 	pgf, ident, err := builtinDecl(ctx, snapshot, obj)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,121 @@
+package golang
+
+// This file estimates the costs of calling the functions of a realm.
+//
+// See also:
+// - ./code_lens.go - offers the Estimate command on exported realm functions.
+// - ../server/command.go - handles the command by reporting the estimate.
+
+import (
+	"context"
+	"fmt"
+	"go/types"
+
+	"github.com/gfanton/gnopls/internal/cache"
+	"github.com/gfanton/gnopls/internal/cache/metadata"
+	"github.com/gfanton/gnopls/internal/event"
+	"github.com/gfanton/gnopls/internal/gnoestimate"
+	"github.com/gnolang/gno/tm2/pkg/std"
+)
+
+// Estimate executes a call of the function fn of the realm mp with the
+// arguments args, which are Gno expressions, and returns a description
+// of its costs. It reports whether any of them increased since the last
+// estimate of the same call, whose costs it replaces.
+func Estimate(ctx context.Context, snapshot *cache.Snapshot, mp *metadata.Package, fn string, args []string) (string, bool, error) {
+	options := snapshot.Options()
+	estimateCtx := gnoestimate.Context{
+		Caller: options.EstimateCaller,
+		Height: options.EstimateHeight,
+	}
+	if options.EstimateSend != "" {
+		send, err := std.ParseCoins(options.EstimateSend)
+		if err != nil {
+			return "", false, fmt.Errorf("invalid estimateSend setting: %v", err)
+		}
+		estimateCtx.Send = send
+	}
+	rootDir, err := snapshot.View().ResolverConfig().GnoRootDir()
+	if err != nil {
+		return "", false, fmt.Errorf("unable to guess gno root dir: %w", err)
+	}
+	memPkg, err := realmMemPackage(ctx, snapshot, mp)
+	if err != nil {
+		return "", false, err
+	}
+
+	costs, err := gnoestimate.Estimate(ctx, memPkg, fn, args, gnoestimate.Options{
+		RootDir: rootDir,
+		Context: estimateCtx,
+	})
+	if err != nil {
+		return "", false, err
+	}
+
+	pkgPath := string(mp.PkgPath)
+	var prev *gnoestimate.Costs
+	if last, ok := gnoestimate.Previous(pkgPath, fn, args, estimateCtx); ok {
+		prev = &last
+	}
+	if err := gnoestimate.Record(pkgPath, fn, args, estimateCtx, costs); err != nil {
+		event.Error(ctx, "recording the estimate of "+fn, err)
+	}
+	msg := fmt.Sprintf("%s.%s: %s", pkgPath, fn, gnoestimate.Format(costs, prev))
+	return msg, prev != nil && gnoestimate.Regressed(costs, *prev), nil
+}
+
+// zeroArgs returns the Gno expressions of the zero values of the
+// parameters of sig, as written in the package pkg, or false if one of
+// them can't be, such as a struct of another package. The variadic
+// parameter, if any, is omitted.
+func zeroArgs(pkg *types.Package, sig *types.Signature) ([]string, bool) {
+	if sig.TypeParams() != nil {
+		return nil, false
+	}
+	params := sig.Params()
+	n := params.Len()
+	if sig.Variadic() {
+		n--
+	}
+	var args []string
+	for i := 0; i < n; i++ {
+		arg, ok := zeroValueExpr(pkg, params.At(i).Type())
+		if !ok {
+			return nil, false
+		}
+		args = append(args, arg)
+	}
+	return args, true
+}
+
+// zeroValueExpr returns the Gno expression of the zero value of type t,
+// as written in the package pkg, or false if it can't be.
+func zeroValueExpr(pkg *types.Package, t types.Type) (string, bool) {
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case u.Info()&types.IsBoolean != 0:
+			return "false", true
+		case u.Info()&types.IsString != 0:
+			return `""`, true
+		case u.Info()&types.IsNumeric != 0:
+			return "0", true
+		case u.Kind() == types.UnsafePointer:
+			return "nil", true
+		}
+	case *types.Pointer, *types.Slice, *types.Map, *types.Chan, *types.Signature, *types.Interface:
+		return "nil", true
+	case *types.Struct, *types.Array:
+		// A composite literal of the type, if it doesn't refer to
+		// another package, which the realm may not import.
+		local := true
+		name := types.TypeString(t, func(p *types.Package) string {
+			if p != pkg {
+				local = false
+			}
+			return ""
+		})
+		return name + "{}", local
+	}
+	return "", false
+}
@@ -0,0 +1,55 @@
+package golang
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"reflect"
+	"testing"
+)
+
+func TestZeroArgs(t *testing.T) {
+	const src = `package p
+
+import "strings"
+
+type Point struct{ X, Y int }
+type Amount int64
+
+func Transfer(to string, amount Amount, memo []byte, ok bool) {}
+func Move(p Point, ps [2]Point, f func()) {}
+func Join(sep string, parts ...string) {}
+func Build(b strings.Builder) {}
+func Apply[T any](x T) {}
+`
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "p.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := &types.Info{Defs: make(map[*ast.Ident]types.Object)}
+	conf := types.Config{Importer: importer.Default()}
+	pkg, err := conf.Check("p", fset, []*ast.File{f}, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		fn   string
+		want []string // nil: no expressible arguments
+	}{
+		{"Transfer", []string{`""`, "0", "nil", "false"}},
+		{"Move", []string{"Point{}", "[2]Point{}", "nil"}},
+		{"Join", []string{`""`}},
+		{"Build", nil},
+		{"Apply", nil},
+	} {
+		fn := pkg.Scope().Lookup(test.fn).(*types.Func)
+		got, ok := zeroArgs(pkg, fn.Signature())
+		if ok != (test.want != nil) || !reflect.DeepEqual(got, test.want) {
+			t.Errorf("zeroArgs(%s) = %q, %t, want %q", test.fn, got, ok, test.want)
+		}
+	}
+}
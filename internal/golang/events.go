@@ -0,0 +1,230 @@
+package golang
+
+// This file finds the events emitted by realms with std.Emit across the
+// workspace: their constant types and attribute keys are workspace
+// symbols, and the references of one are its other occurrences.
+//
+// See also:
+// - ../cache/events - the index of the events of a package.
+// - ./workspace_symbol.go - matches the events with the other symbols.
+// - ./references.go - finds the references of an event.
+// - ./hover.go - lists the other emitters of an event.
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gfanton/gnopls/internal/cache"
+	"github.com/gfanton/gnopls/internal/cache/events"
+	"github.com/gfanton/gnopls/internal/cache/metadata"
+	"github.com/gfanton/gnopls/internal/cache/parsego"
+	"github.com/gfanton/gnopls/internal/protocol"
+	gastutil "github.com/gfanton/gnopls/internal/util/astutil"
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// An eventEntry is an entry of the events index of a package.
+type eventEntry struct {
+	events.Entry
+	mp *metadata.Package
+}
+
+// workspaceEvents returns the entries of the events indexes of the
+// workspace packages of snapshot, sorted by location. Unless typeCheck
+// is set, only the packages that are already type-checked are indexed.
+func workspaceEvents(ctx context.Context, snapshot *cache.Snapshot, typeCheck bool) ([]eventEntry, error) {
+	workspace, err := snapshot.WorkspaceMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]PackageID, len(workspace))
+	for i, mp := range workspace {
+		ids[i] = mp.ID
+	}
+	getEvents := snapshot.CheckedEvents
+	if typeCheck {
+		getEvents = snapshot.Events
+	}
+	indexes, err := getEvents(ctx, ids...)
+	if err != nil {
+		return nil, err
+	}
+
+	// The files of a package are also those of its test variant.
+	seen := make(map[protocol.Location]bool)
+	var entries []eventEntry
+	for i, index := range indexes {
+		if index == nil {
+			continue // not type-checked
+		}
+		for _, e := range index.All() {
+			if !seen[e.Location] {
+				seen[e.Location] = true
+				entries = append(entries, eventEntry{e, workspace[i]})
+			}
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return protocol.CompareLocation(entries[i].Location, entries[j].Location) < 0
+	})
+	return entries, nil
+}
+
+// sameEvent reports whether the entries x and y denote the same event
+// type, or the same attribute key. A key of an event of unknown type
+// matches the key of any event.
+func sameEvent(x, y events.Entry) bool {
+	if x.Kind != y.Kind || x.Key != y.Key {
+		return false
+	}
+	return x.Event == y.Event || x.Kind == events.AttrKey && (x.Event == "" || y.Event == "")
+}
+
+// emitCallAt returns the innermost std.Emit call of pgf enclosing pos,
+// and the name of the function it is in.
+func emitCallAt(pkg *cache.Package, pgf *parsego.File, pos token.Pos) (*ast.CallExpr, string) {
+	path, _ := astutil.PathEnclosingInterval(pgf.File, pos, pos)
+	var call *ast.CallExpr
+	for _, n := range path {
+		switch n := n.(type) {
+		case *ast.CallExpr:
+			if call == nil && events.IsEmit(pkg.TypesInfo(), n) {
+				call = n
+			}
+		case *ast.FuncDecl:
+			if call != nil {
+				return call, n.Name.Name
+			}
+		}
+	}
+	return call, ""
+}
+
+// eventAt returns the constant event type or attribute key literal of
+// an std.Emit call at pos, if any.
+func eventAt(pkg *cache.Package, pgf *parsego.File, pos token.Pos) (events.Entry, bool) {
+	path, _ := astutil.PathEnclosingInterval(pgf.File, pos, pos)
+	if len(path) == 0 {
+		return events.Entry{}, false
+	}
+	lit, ok := path[0].(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return events.Entry{}, false
+	}
+	call, fn := emitCallAt(pkg, pgf, pos)
+	if call == nil {
+		return events.Entry{}, false
+	}
+	loc, err := pgf.NodeLocation(lit)
+	if err != nil {
+		return events.Entry{}, false
+	}
+	for _, e := range events.CallEntries(pgf, pkg.TypesInfo(), call, fn) {
+		if e.Location == loc {
+			return e, true
+		}
+	}
+	return events.Entry{}, false
+}
+
+// eventReferences returns the references of the constant event type
+// or attribute key literal of an std.Emit call at pp, which are its
+// occurrences in the std.Emit calls of the workspace. It reports
+// whether there is one at pp; if not, the references are those of an
+// ordinary object.
+//
+// Event types and attribute keys given by named constants are ordinary
+// objects.
+func eventReferences(ctx context.Context, snapshot *cache.Snapshot, uri protocol.DocumentURI, pp protocol.Position) ([]reference, bool, error) {
+	pkg, pgf, err := NarrowestPackageForFile(ctx, snapshot, uri)
+	if err != nil {
+		return nil, false, err
+	}
+	pos, err := pgf.PositionPos(pp)
+	if err != nil {
+		return nil, false, err
+	}
+	target, ok := eventAt(pkg, pgf, pos)
+	if !ok {
+		return nil, false, nil
+	}
+	entries, err := workspaceEvents(ctx, snapshot, true)
+	if err != nil {
+		return nil, true, err
+	}
+	refs := []reference{{location: target.Location, pkgPath: pkg.Metadata().PkgPath}}
+	for _, e := range entries {
+		if sameEvent(e.Entry, target) {
+			refs = append(refs, reference{location: e.Location, pkgPath: e.mp.PkgPath})
+		}
+	}
+	return refs, true, nil
+}
+
+// eventSymbolFiles returns the constant event types and attribute keys
+// of the workspace packages of snapshot as symbols, by file. As the
+// other symbols are syntactic, the packages that are not type-checked
+// yet are left out, rather than checked for each query.
+func eventSymbolFiles(ctx context.Context, snapshot *cache.Snapshot) ([]symbolFile, error) {
+	entries, err := workspaceEvents(ctx, snapshot, false)
+	if err != nil {
+		return nil, err
+	}
+	var files []symbolFile
+	for _, e := range entries {
+		if len(files) == 0 || files[len(files)-1].uri != e.Location.URI {
+			files = append(files, symbolFile{uri: e.Location.URI, mp: e.mp})
+		}
+		kind := protocol.Event
+		if e.Kind == events.AttrKey {
+			kind = protocol.Key
+		}
+		f := &files[len(files)-1]
+		f.syms = append(f.syms, cache.Symbol{Name: e.Name(), Kind: kind, Range: e.Location.Range})
+	}
+	return files, nil
+}
+
+// otherEmitters returns the list of the other emitters, in the
+// workspace, of the event of the std.Emit call whose function is
+// denoted by ident, or "" if ident is not that of a call emitting an
+// event of constant type.
+func otherEmitters(ctx context.Context, snapshot *cache.Snapshot, pkg *cache.Package, pgf *parsego.File, ident *ast.Ident) (string, error) {
+	call, fn := emitCallAt(pkg, pgf, ident.Pos())
+	if call == nil || !gastutil.NodeContains(call.Fun, ident.Pos()) {
+		return "", nil
+	}
+	var typ *events.Entry
+	for _, e := range events.CallEntries(pgf, pkg.TypesInfo(), call, fn) {
+		if e.Kind == events.Type {
+			typ = &e
+			break
+		}
+	}
+	if typ == nil {
+		return "", nil
+	}
+	entries, err := workspaceEvents(ctx, snapshot, true)
+	if err != nil {
+		return "", err
+	}
+	var lines []string
+	for _, e := range entries {
+		if e.Location != typ.Location && sameEvent(e.Entry, *typ) {
+			name := string(e.mp.PkgPath)
+			if e.Func != "" {
+				name += "." + e.Func
+			}
+			lines = append(lines, fmt.Sprintf("%s (%s:%d)", name, filepath.Base(e.Location.URI.Path()), e.Location.Range.Start.Line+1))
+		}
+	}
+	if len(lines) == 0 {
+		return fmt.Sprintf("No other emitter of event %q", typ.Event), nil
+	}
+	return fmt.Sprintf("Other emitters of event %q:\n- %s", typ.Event, strings.Join(lines, "\n- ")), nil
+}
@@ -0,0 +1,197 @@
+package golang
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gfanton/gnopls/internal/cache"
+	"github.com/gfanton/gnopls/internal/file"
+	"github.com/gfanton/gnopls/internal/protocol"
+	"github.com/gfanton/gnopls/internal/settings"
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+const (
+	eventsA = `package a
+
+import "std"
+
+func Transfer() {
+	std.Emit("Transfer", "to", "bob")
+}
+
+func Burn(typ string) {
+	std.Emit(typ)
+}
+`
+	eventsB = `package b
+
+import "std"
+
+func Send() {
+	std.Emit("Transfer", "to", "alice")
+}
+`
+)
+
+// eventsSnapshot returns a snapshot of a gno.work of the realms a and b
+// emitting the same event, with a.gno open.
+func eventsSnapshot(t *testing.T) (*cache.Snapshot, protocol.DocumentURI) {
+	t.Helper()
+	ctx := context.Background()
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"gno.work":                      "go 1.22\n\nuse (\n\t./a\n\t./b\n)\n",
+		"a/gno.mod":                     "module gno.land/r/demo/a\n",
+		"a/a.gno":                       eventsA,
+		"b/gno.mod":                     "module gno.land/r/demo/b\n",
+		"b/b.gno":                       eventsB,
+		"gno/gnovm/stdlibs/std/std.gno": "package std\n\nfunc Emit(typ string, attrs ...string) {}\n",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	opts := settings.DefaultOptions()
+	opts.GnoRoot = filepath.Join(dir, "gno")
+	opts.GnoModCache = filepath.Join(dir, "modcache")
+
+	session := cache.NewSession(ctx, cache.New(nil))
+	t.Cleanup(func() { session.Shutdown(ctx) })
+	_, _, release, err := session.NewView(ctx, &cache.Folder{
+		Dir:     protocol.URIFromPath(dir),
+		Name:    "events",
+		Options: opts,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+	uri := protocol.URIFromPath(filepath.Join(dir, "a", "a.gno"))
+	if _, err := session.DidModifyFiles(ctx, []file.Modification{{
+		URI:        uri,
+		Action:     file.Open,
+		Version:    1,
+		Text:       []byte(eventsA),
+		LanguageID: "gno",
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	snapshot, release, err := session.SnapshotOf(ctx, uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(release)
+	return snapshot, uri
+}
+
+// position returns the position of the first occurrence of substr in
+// src.
+func position(t *testing.T, src, substr string) protocol.Position {
+	t.Helper()
+	offset := strings.Index(src, substr)
+	if offset < 0 {
+		t.Fatalf("no %q in source", substr)
+	}
+	pos, err := protocol.NewMapper("", []byte(src)).OffsetPosition(offset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pos
+}
+
+func TestEventReferences(t *testing.T) {
+	ctx := context.Background()
+	snapshot, uri := eventsSnapshot(t)
+
+	// The workspace symbols don't type-check the packages.
+	files, err := eventSymbolFiles(ctx, snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 0 {
+		t.Errorf("event symbols of unchecked packages: %v", files)
+	}
+
+	for _, test := range []struct {
+		substr string
+		want   []string // files and lines of the references
+	}{
+		{`"Transfer"`, []string{"a.gno:6", "b.gno:6"}},
+		{`"to"`, []string{"a.gno:6", "b.gno:6"}},
+		{`"bob"`, nil}, // an attribute value
+	} {
+		refs, ok, err := eventReferences(ctx, snapshot, uri, position(t, eventsA, test.substr))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok != (test.want != nil) {
+			t.Errorf("eventReferences(%s) found an event: %t, want %t", test.substr, ok, test.want != nil)
+			continue
+		}
+		var got []string // as references sorts and de-duplicates them
+		seen := make(map[protocol.Location]bool)
+		for _, ref := range refs {
+			if !seen[ref.location] {
+				seen[ref.location] = true
+				got = append(got, fmt.Sprintf("%s:%d", filepath.Base(ref.location.URI.Path()), ref.location.Range.Start.Line+1))
+			}
+		}
+		sort.Strings(got)
+		if strings.Join(got, " ") != strings.Join(test.want, " ") {
+			t.Errorf("eventReferences(%s) = %v, want %v", test.substr, got, test.want)
+		}
+	}
+
+	// The package of the open file is now type-checked.
+	files, err = eventSymbolFiles(ctx, snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 || files[0].uri != uri || len(files[0].syms) != 2 {
+		t.Errorf("event symbols of a.gno: %v, want Transfer and Transfer.to", files)
+	}
+}
+
+func TestOtherEmitters(t *testing.T) {
+	ctx := context.Background()
+	snapshot, uri := eventsSnapshot(t)
+	pkg, pgf, err := NarrowestPackageForFile(ctx, snapshot, uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		substr string
+		want   string
+	}{
+		{"Emit", "Other emitters of event \"Transfer\":\n- gno.land/r/demo/b.Send (b.gno:6)"},
+		{"Emit(typ", ""}, // an event of unknown type
+	} {
+		pos, err := pgf.PositionPos(position(t, eventsA, test.substr))
+		if err != nil {
+			t.Fatal(err)
+		}
+		path, _ := astutil.PathEnclosingInterval(pgf.File, pos, pos)
+		ident, ok := path[0].(*ast.Ident)
+		if !ok {
+			t.Fatalf("no identifier at %s", test.substr)
+		}
+		got, err := otherEmitters(ctx, snapshot, pkg, pgf, ident)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != test.want {
+			t.Errorf("otherEmitters(%s) = %q, want %q", test.substr, got, test.want)
+		}
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/gfanton/gnopls/internal/cache"
 	"github.com/gfanton/gnopls/internal/cache/parsego"
 	"github.com/gfanton/gnopls/internal/file"
+	"github.com/gfanton/gnopls/internal/filetest"
 	"github.com/gfanton/gnopls/internal/protocol"
 	"github.com/gfanton/gnopls/internal/util/bug"
 	"github.com/gfanton/gnopls/internal/util/safetoken"
@@ -165,9 +166,19 @@ func validLineFoldingRange(tokFile *token.File, open, close, start, end token.Po
 
 // commentsFoldingRange returns the folding ranges for all comment blocks in file.
 // The folding range starts at the end of the first line of the comment block, and ends at the end of the
-// comment block and has kind protocol.Comment.
+// comment block and has kind protocol.Comment, or protocol.Region for the
+// directive blocks of a filetest, such as "// Output:".
 func commentsFoldingRange(pgf *parsego.File) (comments []*FoldingRangeInfo) {
 	tokFile := pgf.Tok
+	blocks := make(map[*ast.Comment]bool)
+	if filetest.IsFiletest(pgf.URI.Path()) {
+		directives, _ := filetest.Parse(pgf.File)
+		for _, d := range directives {
+			if d.Kind == filetest.Block {
+				blocks[d.Comment] = true
+			}
+		}
+	}
 	for _, commentGrp := range pgf.File.Comments {
 		startGrpLine, endGrpLine := safetoken.Line(tokFile, commentGrp.Pos()), safetoken.Line(tokFile, commentGrp.End())
 		if startGrpLine == endGrpLine {
@@ -187,10 +198,14 @@ func commentsFoldingRange(pgf *parsego.File) (comments []*FoldingRangeInfo) {
 		if err != nil {
 			bug.Errorf("%w", err) // can't happen
 		}
+		kind := protocol.Comment
+		if blocks[firstComment] {
+			kind = protocol.Region
+		}
 		comments = append(comments, &FoldingRangeInfo{
 			// Fold from the end of the first line comment to the end of the comment block.
 			MappedRange: mrng,
-			Kind:        protocol.Comment,
+			Kind:        kind,
 		})
 	}
 	return comments
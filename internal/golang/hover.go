@@ -77,6 +77,9 @@ type hoverJSON struct {
 	// For example, the "Node" part of "pkg.go.dev/go/ast#Node".
 	LinkAnchor string `json:"linkAnchor"`
 
+	// linkView is the gnoweb view of a realm that documents the symbol.
+	linkView cache.RealmView
+
 	// stdVersion is the Go release version at which this symbol became available.
 	// It is nil for non-std library.
 	stdVersion *stdlib.Version
@@ -100,6 +103,14 @@ type hoverJSON struct {
 	// fields of a (struct) type that were promoted through an
 	// embedded field.
 	promotedFields string
+
+	// storedValue is the value a package-level variable of a realm
+	// has on chain, or "" if unknown.
+	storedValue string
+
+	// emitters lists the other emitters of the event of an std.Emit
+	// call, or is "" if not hovering one.
+	emitters string
 }
 
 // Hover implements the "textDocument/hover" RPC for Go files.
@@ -604,6 +615,20 @@ func hover(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle, pp pro
 		version = &symbol.Version
 	}
 
+	// gnoweb lists the functions of a realm in its $help view only.
+	linkView := cache.RealmSource
+	if fn, ok := obj.(*types.Func); ok && fn.Type().(*types.Signature).Recv() == nil {
+		linkView = cache.RealmHelp
+	}
+
+	var emitters string
+	if obj.Pkg() != nil && obj.Pkg().Path() == "std" && obj.Name() == "Emit" {
+		emitters, err = otherEmitters(ctx, snapshot, pkg, pgf, ident)
+		if err != nil {
+			event.Error(ctx, "finding the emitters of an event", err)
+		}
+	}
+
 	return *hoverRange, &hoverJSON{
 		Synopsis:          doc.Synopsis(docText),
 		FullDocumentation: docText,
@@ -612,10 +637,13 @@ func hover(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle, pp pro
 		Signature:         signature,
 		LinkPath:          linkPath,
 		LinkAnchor:        anchor,
+		linkView:          linkView,
 		typeDecl:          typeDecl,
 		methods:           methods,
 		promotedFields:    fields,
 		stdVersion:        version,
+		storedValue:       storedValue(ctx, snapshot, obj),
+		emitters:          emitters,
 	}, nil
 }
 
@@ -637,6 +665,17 @@ func hoverBuiltin(ctx context.Context, snapshot *cache.Snapshot, obj types.Objec
 		}, nil
 	}
 
+	// The Gno predeclared identifiers (see cache.declareGnoUniverse)
+	// have no documentation syntax.
+	if obj.Pkg() != nil && obj.Pkg() != types.Unsafe {
+		signature := types.ObjectString(obj, types.RelativeTo(obj.Pkg()))
+		return &hoverJSON{
+			Signature:  signature,
+			SingleLine: signature,
+			SymbolName: obj.Name(),
+		}, nil
+	}
+
 	pgf, ident, err := builtinDecl(ctx, snapshot, obj)
 	if err != nil {
 		return nil, err
@@ -1172,8 +1211,10 @@ func formatHover(h *hoverJSON, options *settings.Options, pkgURL func(path Packa
 		// (This awkwardness is to preserve JSON compatibility.)
 		parts := []string{
 			maybeMarkdown(h.Signature),
+			formatStoredValue(h, options),
 			maybeMarkdown(h.typeDecl),
 			formatDoc(h, options),
+			h.emitters,
 			maybeMarkdown(h.promotedFields),
 			maybeMarkdown(h.methods),
 			fmt.Sprintf("Added in %v", h.stdVersion),
@@ -1183,7 +1224,7 @@ func formatHover(h *hoverJSON, options *settings.Options, pkgURL func(path Packa
 			parts[0] = "" // type: suppress redundant Signature
 		}
 		if h.stdVersion == nil || *h.stdVersion == stdlib.Version(0) {
-			parts[5] = "" // suppress stdlib version if not applicable or initial version 1.0
+			parts[7] = "" // suppress stdlib version if not applicable or initial version 1.0
 		}
 
 		var b strings.Builder
@@ -1278,6 +1319,18 @@ func StdSymbolOf(obj types.Object) *stdlib.Symbol {
 	return nil
 }
 
+// formatStoredValue returns the value a realm variable has on chain, if
+// known.
+func formatStoredValue(h *hoverJSON, options *settings.Options) string {
+	if h.storedValue == "" {
+		return ""
+	}
+	if options.PreferredContentFormat == protocol.Markdown {
+		return fmt.Sprintf("Stored value:\n```\n%s\n```", h.storedValue)
+	}
+	return "Stored value: " + h.storedValue
+}
+
 // If pkgURL is non-nil, it should be used to generate doc links.
 func formatLink(h *hoverJSON, options *settings.Options, pkgURL func(path PackagePath, fragment string) protocol.URI) string {
 	if options.LinksInHover == settings.LinksInHover_None || h.LinkPath == "" {
@@ -1293,7 +1346,7 @@ func formatLink(h *hoverJSON, options *settings.Options, pkgURL func(path Packag
 		if options.LinkTarget == "" {
 			return ""
 		}
-		url = cache.BuildLink(options.LinkTarget, h.LinkPath, h.LinkAnchor)
+		url = cache.DocLink(options.GnowebLinks, options.LinkTarget, h.LinkPath, h.LinkAnchor, h.linkView)
 		caption = "on " + options.LinkTarget
 	}
 	switch options.PreferredContentFormat {
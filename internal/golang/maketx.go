@@ -0,0 +1,233 @@
+package golang
+
+// This file generates gnokey transactions calling the functions of a
+// realm.
+//
+// See also:
+// - ./code_lens.go - offers the MakeTx command on exported realm functions.
+// - ../server/command.go - handles the command by opening the transaction.
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"go/types"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The kinds of transactions generated by MakeTx.
+const (
+	MakeTxCall = "call" // a gnokey maketx call command line
+	MakeTxJSON = "json" // a MsgCall transaction, as printed by gnokey maketx call
+	MakeTxRun  = "run"  // a gnokey maketx run script
+)
+
+// The defaults of the generated transactions, those of a local gnodev
+// node.
+const (
+	txGasFee    = "1000000ugnot"
+	txGasWanted = "2000000"
+	txChainID   = "dev"
+	txRemote    = "127.0.0.1:26657"
+)
+
+// hasPrimitiveParams reports whether all the parameters of sig are of
+// primitive types, whose values gnokey maketx call can pass.
+func hasPrimitiveParams(sig *types.Signature) bool {
+	if sig.TypeParams() != nil || sig.Variadic() {
+		return false
+	}
+	for i := 0; i < sig.Params().Len(); i++ {
+		basic, ok := sig.Params().At(i).Type().Underlying().(*types.Basic)
+		if !ok || basic.Info()&(types.IsBoolean|types.IsNumeric|types.IsString) == 0 || basic.Info()&types.IsComplex != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// MakeTx returns the transaction of the given kind calling the function
+// fn of the realm pkg, with placeholders for its arguments, and the
+// extension of the file to write it to. The transaction is sent to the
+// node remote, of the chain chainID, or to a local gnodev node if they
+// are empty.
+//
+// For a run script, file is the name of the file it is written to.
+func MakeTx(pkg *types.Package, fn *types.Func, kind, remote, chainID, file string) ([]byte, string, error) {
+	if remote == "" {
+		remote = txRemote
+	}
+	if chainID == "" {
+		chainID = txChainID
+	}
+	sig := fn.Signature()
+	if kind != MakeTxRun && !hasPrimitiveParams(sig) {
+		return nil, "", fmt.Errorf("the parameters of %s are not all of primitive types; use a run script", fn.Name())
+	}
+	switch kind {
+	case MakeTxCall:
+		return makeTxCall(pkg, fn, remote, chainID), ".sh", nil
+	case MakeTxJSON:
+		data, err := makeTxJSON(pkg, fn)
+		return data, ".json", err
+	case MakeTxRun:
+		data, err := makeTxRun(pkg, fn, remote, chainID, file)
+		return data, ".gno", err
+	}
+	return nil, "", fmt.Errorf("unknown transaction kind %q", kind)
+}
+
+// argPlaceholders returns the placeholders of the arguments of a call
+// of fn, such as "<amount int64>".
+func argPlaceholders(pkg *types.Package, fn *types.Func) []string {
+	args := []string{} // an empty JSON array, not null
+	params := fn.Signature().Params()
+	for i := 0; i < params.Len(); i++ {
+		typ := types.TypeString(params.At(i).Type(), types.RelativeTo(pkg))
+		args = append(args, fmt.Sprintf("<%s %s>", paramName(params.At(i), i), typ))
+	}
+	return args
+}
+
+// paramName returns the name of the parameter v, the ith one, or a name
+// for it if it has none.
+func paramName(v *types.Var, i int) string {
+	if v.Name() == "" || v.Name() == "_" {
+		return fmt.Sprintf("arg%d", i)
+	}
+	return v.Name()
+}
+
+// makeTxCall returns a shell script running the gnokey maketx call
+// command that calls fn.
+func makeTxCall(pkg *types.Package, fn *types.Func, remote, chainID string) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "#!/bin/sh\n# Calls %s.%s: replace the placeholders, in <>, and run.\n", pkg.Path(), fn.Name())
+	fmt.Fprintf(&buf, "gnokey maketx call \\\n")
+	fmt.Fprintf(&buf, "\t-pkgpath %q \\\n", pkg.Path())
+	fmt.Fprintf(&buf, "\t-func %q \\\n", fn.Name())
+	for _, arg := range argPlaceholders(pkg, fn) {
+		fmt.Fprintf(&buf, "\t-args %q \\\n", arg)
+	}
+	fmt.Fprintf(&buf, "\t-gas-fee %s \\\n", txGasFee)
+	fmt.Fprintf(&buf, "\t-gas-wanted %s \\\n", txGasWanted)
+	fmt.Fprintf(&buf, "\t-broadcast \\\n")
+	fmt.Fprintf(&buf, "\t-chainid %s \\\n", chainID)
+	fmt.Fprintf(&buf, "\t-remote %s \\\n", remote)
+	fmt.Fprintf(&buf, "\t\"<key name>\"\n")
+	return buf.Bytes()
+}
+
+// makeTxJSON returns the unsigned MsgCall transaction calling fn, as
+// printed by gnokey maketx call without -broadcast.
+func makeTxJSON(pkg *types.Package, fn *types.Func) ([]byte, error) {
+	type msgCall struct {
+		Type    string   `json:"@type"`
+		Caller  string   `json:"caller"`
+		Send    string   `json:"send"`
+		PkgPath string   `json:"pkg_path"`
+		Func    string   `json:"func"`
+		Args    []string `json:"args"`
+	}
+	type fee struct {
+		GasWanted string `json:"gas_wanted"`
+		GasFee    string `json:"gas_fee"`
+	}
+	tx := struct {
+		Msg        []msgCall `json:"msg"`
+		Fee        fee       `json:"fee"`
+		Signatures any       `json:"signatures"`
+		Memo       string    `json:"memo"`
+	}{
+		Msg: []msgCall{{
+			Type:    "/vm.m_call",
+			Caller:  "<caller address>",
+			PkgPath: pkg.Path(),
+			Func:    fn.Name(),
+			Args:    argPlaceholders(pkg, fn),
+		}},
+		Fee: fee{GasWanted: txGasWanted, GasFee: txGasFee},
+	}
+	data, err := json.MarshalIndent(tx, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// makeTxRun returns the source of a gnokey maketx run script, written
+// to file, whose main function calls fn with the zero values of its
+// parameters, and prints its results.
+func makeTxRun(pkg *types.Package, fn *types.Func, remote, chainID, file string) ([]byte, error) {
+	// The names of the imports of the script, by path.
+	imports := map[string]string{pkg.Path(): pkg.Name()}
+	qualifier := func(p *types.Package) string {
+		imports[p.Path()] = p.Name()
+		return p.Name()
+	}
+
+	sig := fn.Signature()
+	params := sig.Params()
+	names := make([]string, params.Len())
+	typs := make([]string, params.Len())
+	for i := range names {
+		names[i] = paramName(params.At(i), i)
+		typs[i] = types.TypeString(params.At(i).Type(), qualifier)
+	}
+	// A variable named like an imported package would shadow it.
+	used := make(map[string]bool)
+	for _, name := range imports {
+		used[name] = true
+	}
+	for i := range names {
+		for used[names[i]] {
+			names[i] += "_"
+		}
+		used[names[i]] = true
+	}
+
+	var buf bytes.Buffer
+	if len(names) > 0 {
+		buf.WriteString("\t// Set the arguments of the call.\n\tvar (\n")
+		for i := range names {
+			fmt.Fprintf(&buf, "\t\t%s %s\n", names[i], typs[i])
+		}
+		buf.WriteString("\t)\n")
+	}
+	args := names
+	if sig.Variadic() {
+		args = append(names[:len(names)-1:len(names)-1], names[len(names)-1]+"...")
+	}
+	call := fmt.Sprintf("%s.%s(%s)", pkg.Name(), fn.Name(), strings.Join(args, ", "))
+	if n := sig.Results().Len(); n > 0 {
+		var results []string
+		for i := 0; i < n; i++ {
+			results = append(results, fmt.Sprintf("r%d", i))
+		}
+		fmt.Fprintf(&buf, "\t%s := %s\n", strings.Join(results, ", "), call)
+		fmt.Fprintf(&buf, "\tprintln(%s)\n", strings.Join(results, ", "))
+	} else {
+		fmt.Fprintf(&buf, "\t%s\n", call)
+	}
+	body := buf.String()
+
+	var paths []string
+	for path := range imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	buf.Reset()
+	fmt.Fprintf(&buf, "// Calls %s.%s: set the arguments of the call, and run with:\n", pkg.Path(), fn.Name())
+	fmt.Fprintf(&buf, "//\n//\tgnokey maketx run -gas-fee %s -gas-wanted %s -broadcast -chainid %s -remote %s \"<key name>\" %s\n",
+		txGasFee, txGasWanted, chainID, remote, file)
+	buf.WriteString("package main\n\nimport (\n")
+	for _, path := range paths {
+		buf.WriteString("\t" + strconv.Quote(path) + "\n")
+	}
+	buf.WriteString(")\n\nfunc main() {\n" + body + "}\n")
+	return format.Source(buf.Bytes())
+}
@@ -0,0 +1,92 @@
+package golang
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+)
+
+func TestMakeTx(t *testing.T) {
+	const src = `package foo
+
+type Point struct{ X, Y int }
+
+func Transfer(to string, amount int64, ok bool) {}
+func Move(p Point, foo int) Point { return p }
+`
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "foo.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := types.Config{Importer: importer.Default()}
+	pkg, err := conf.Check("gno.land/r/demo/foo", fset, []*ast.File{f}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	transfer := pkg.Scope().Lookup("Transfer").(*types.Func)
+	move := pkg.Scope().Lookup("Move").(*types.Func)
+
+	if !hasPrimitiveParams(transfer.Signature()) || hasPrimitiveParams(move.Signature()) {
+		t.Errorf("hasPrimitiveParams: want true for Transfer, false for Move")
+	}
+
+	call, ext, err := MakeTx(pkg, transfer, MakeTxCall, "", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`-pkgpath "gno.land/r/demo/foo"`,
+		`-func "Transfer"`,
+		`-args "<to string>"`,
+		`-args "<amount int64>"`,
+		`-remote ` + txRemote,
+		`-chainid ` + txChainID,
+	} {
+		if ext != ".sh" || !strings.Contains(string(call), want) {
+			t.Errorf("MakeTx(Transfer, call) = %s, %q, want %q in it", call, ext, want)
+		}
+	}
+
+	data, _, err := MakeTx(pkg, transfer, MakeTxJSON, "", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tx struct {
+		Msg []struct {
+			Type string   `json:"@type"`
+			Func string   `json:"func"`
+			Args []string `json:"args"`
+		} `json:"msg"`
+	}
+	if err := json.Unmarshal(data, &tx); err != nil {
+		t.Fatal(err)
+	}
+	if len(tx.Msg) != 1 || tx.Msg[0].Type != "/vm.m_call" || tx.Msg[0].Func != "Transfer" || len(tx.Msg[0].Args) != 3 {
+		t.Errorf("MakeTx(Transfer, json) = %s", data)
+	}
+
+	if _, _, err := MakeTx(pkg, move, MakeTxCall, "", "", ""); err == nil {
+		t.Errorf("MakeTx(Move, call) succeeded, want an error")
+	}
+	run, _, err := MakeTx(pkg, move, MakeTxRun, "127.0.0.1:36657", "test5", "Move.gno")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"-chainid test5 -remote 127.0.0.1:36657",
+		`"gno.land/r/demo/foo"`,
+		"foo.Point",
+		"foo_ int", // renamed not to shadow the realm
+		"r0 := foo.Move(p, foo_)",
+	} {
+		if !strings.Contains(string(run), want) {
+			t.Errorf("MakeTx(Move, run) = %s, want %q in it", run, want)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package golang
+
+// This file produces the realm state page, and the stored values shown
+// in hovers.
+//
+// See also:
+// - ./code_lens.go - offers the RealmState command on realm package clauses.
+// - ../server/command.go - handles the command by opening a web page.
+// - ../server/server.go - handles the HTTP request and calls this function.
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/types"
+	"html"
+	"sort"
+
+	"github.com/gfanton/gnopls/internal/cache"
+	"github.com/gfanton/gnopls/internal/cache/metadata"
+	"github.com/gfanton/gnopls/internal/event"
+	"github.com/gfanton/gnopls/internal/gnostate"
+	"github.com/gfanton/gnopls/internal/typesinternal"
+)
+
+// realmGlobals returns the package-level variables of the package, in
+// declaration order.
+func realmGlobals(pkg *types.Package) []*types.Var {
+	var globals []*types.Var
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		if v, ok := scope.Lookup(name).(*types.Var); ok && v.Pos().IsValid() {
+			globals = append(globals, v)
+		}
+	}
+	sort.Slice(globals, func(i, j int) bool { return globals[i].Pos() < globals[j].Pos() })
+	return globals
+}
+
+// RealmStateHTML returns an HTML document showing the value of each
+// package-level variable of the realm pkg, as read from src.
+//
+// Errors reading the state are shown in the document rather than
+// returned.
+func RealmStateHTML(ctx context.Context, viewID string, pkg *cache.Package, src gnostate.Source, web Web) ([]byte, error) {
+	globals := realmGlobals(pkg.Types())
+	var names []string
+	for _, v := range globals {
+		names = append(names, v.Name())
+	}
+	values, stateErr := gnostate.Values(ctx, src, string(pkg.Metadata().PkgPath), names)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	escape := html.EscapeString
+	title := string(pkg.Metadata().PkgPath)
+	qualifier := typesinternal.NameRelativeTo(pkg.Types())
+
+	var buf bytes.Buffer
+	buf.WriteString(`<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="UTF-8">
+  <title>` + escape(title) + ` state</title>
+  <link rel="stylesheet" href="/assets/common.css">
+  <script src="/assets/common.js"></script>
+</head>
+<body>
+<h1>` + escape(title) + `</h1>
+<p>
+  Values of the package-level variables of the realm, as read from
+  <code>` + escape(string(src)) + `</code>.
+</p>
+`)
+	if stateErr != nil {
+		fmt.Fprintf(&buf, "<pre class='error'>%s</pre>\n", escape(stateErr.Error()))
+	}
+	buf.WriteString("<table>\n<tr><th>Variable</th><th>Type</th><th>Value</th></tr>\n")
+	for _, v := range globals {
+		value := "<i>(not stored)</i>"
+		if val, ok := values[v.Name()]; ok && val.Err != nil {
+			value = "<span class='error'>" + escape(val.Err.Error()) + "</span>"
+		} else if ok {
+			value = "<code>" + escape(val.Text) + "</code>"
+		}
+		fmt.Fprintf(&buf, "<tr><td>%s</td><td><code>%s</code></td><td>%s</td></tr>\n",
+			objHTML(pkg.FileSet(), web, v),
+			escape(types.TypeString(v.Type(), qualifier)),
+			value)
+	}
+	if len(globals) == 0 {
+		buf.WriteString("<tr><td colspan='3'>(none)</td></tr>\n")
+	}
+	buf.WriteString("</table>\n</body>\n</html>\n")
+	return buf.Bytes(), nil
+}
+
+// storedValue returns the value the package-level variable obj of a realm
+// has on chain, or "" if it is not such a variable, or its value can't
+// be read.
+func storedValue(ctx context.Context, snapshot *cache.Snapshot, obj types.Object) string {
+	v, ok := obj.(*types.Var)
+	if !ok || v.Pkg() == nil || !isPackageLevel(v) || !metadata.IsRealmPath(PackagePath(v.Pkg().Path())) {
+		return ""
+	}
+	value, ok, err := snapshot.StoredValue(ctx, v.Pkg().Path(), v.Name())
+	if err == nil && value.Err != nil {
+		err = value.Err
+	}
+	if err != nil {
+		event.Error(ctx, "reading the stored value of "+v.Name(), err)
+		return ""
+	}
+	if !ok {
+		return ""
+	}
+	return value.Text
+}
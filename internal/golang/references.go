@@ -75,7 +75,11 @@ func references(ctx context.Context, snapshot *cache.Snapshot, f file.Handle, pp
 	if inPackageName {
 		refs, err = packageReferences(ctx, snapshot, f.URI())
 	} else {
-		refs, err = ordinaryReferences(ctx, snapshot, f.URI(), pp)
+		var isEvent bool
+		refs, isEvent, err = eventReferences(ctx, snapshot, f.URI(), pp)
+		if err == nil && !isEvent {
+			refs, err = ordinaryReferences(ctx, snapshot, f.URI(), pp)
+		}
 	}
 	if err != nil {
 		return nil, err
@@ -125,14 +129,14 @@ func packageReferences(ctx context.Context, snapshot *cache.Snapshot, uri protoc
 	// The narrowest package is the most broadly imported,
 	// so we choose it for the external references.
 	//
-	// But if the file ends with _test.go then we need to
+	// But if the file ends with _test.gno then we need to
 	// find the package it is testing; there's no direct way
 	// to do that, so pick a file from the same package that
-	// doesn't end in _test.go and start over.
+	// doesn't end in _test.gno and start over.
 	narrowest := metas[0]
-	if narrowest.ForTest != "" && strings.HasSuffix(string(uri), "_test.go") {
+	if narrowest.ForTest != "" && strings.HasSuffix(string(uri), "_test.gno") {
 		for _, f := range narrowest.CompiledGoFiles {
-			if !strings.HasSuffix(string(f), "_test.go") {
+			if !strings.HasSuffix(string(f), "_test.gno") {
 				return packageReferences(ctx, snapshot, f)
 			}
 		}
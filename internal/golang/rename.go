@@ -55,6 +55,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/gnolang/gno/gnovm/pkg/gnomod"
 	"golang.org/x/mod/modfile"
 	"golang.org/x/tools/go/ast/astutil"
 	"golang.org/x/tools/go/types/objectpath"
@@ -651,7 +652,7 @@ func renamePackageName(ctx context.Context, s *cache.Snapshot, f file.Handle, ne
 		if len(affectedReplaces) == 0 {
 			continue
 		}
-		copied, err := modfile.Parse("", pm.Mapper.Content, nil)
+		copied, err := gnomod.Parse("", pm.Mapper.Content)
 		if err != nil {
 			return nil, err
 		}
@@ -680,11 +681,8 @@ func renamePackageName(ctx context.Context, s *cache.Snapshot, f file.Handle, ne
 			}
 		}
 
-		copied.Cleanup()
-		newContent, err := copied.Format()
-		if err != nil {
-			return nil, err
-		}
+		copied.Syntax.Cleanup()
+		newContent := modfile.Format(copied.Syntax)
 
 		// Calculate the edits to be made due to the change.
 		edits := diff.Bytes(pm.Mapper.Content, newContent)
@@ -0,0 +1,136 @@
+package golang
+
+// This file produces the Render preview of a realm.
+//
+// See also:
+// - ./code_lens.go - offers the RenderPreview command on Render functions.
+// - ../server/command.go - handles the command by opening a web page.
+// - ../server/server.go - handles the HTTP request and calls this function.
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"html"
+	"path/filepath"
+	"strings"
+
+	"github.com/gfanton/gnopls/internal/cache"
+	"github.com/gfanton/gnopls/internal/cache/metadata"
+	"github.com/gfanton/gnopls/internal/gnorender"
+	"github.com/gnolang/gno/tm2/pkg/std"
+)
+
+// RenderHTML returns an HTML document showing the output of the Render
+// function of the realm mp for the given path, formatted as gnoweb
+// would.
+//
+// The realm is executed from the contents of its files in the snapshot,
+// including unsaved editor buffers. Errors of the execution are shown
+// in the document rather than returned, so that the page keeps
+// refreshing until they are fixed.
+func RenderHTML(ctx context.Context, snapshot *cache.Snapshot, viewID string, mp *metadata.Package, path string) ([]byte, error) {
+	memPkg, err := realmMemPackage(ctx, snapshot, mp)
+	if err != nil {
+		return nil, err
+	}
+
+	var output bytes.Buffer
+	markdown, renderErr := gnorender.Render(ctx, memPkg, path, gnorender.Options{Output: &output})
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	escape := html.EscapeString
+	title := string(mp.PkgPath)
+	if path != "" {
+		title += ":" + path
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(`<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="UTF-8">
+  <title>` + escape(title) + `</title>
+  <link rel="stylesheet" href="/assets/common.css">
+  <script src="/assets/common.js"></script>
+  <script>
+    // Reload the page when a file is saved, to render the realm again.
+    window.addEventListener('load', function() {
+      var x = new XMLHttpRequest();
+      x.open("GET", "render/wait", true);
+      x.onload = () => { if (x.status == 200) location.reload(); };
+      x.send();
+    });
+  </script>
+</head>
+<body>
+<h1>` + escape(title) + `</h1>
+<form action="render" method="get">
+  <input type="hidden" name="view" value="` + escape(viewID) + `">
+  <input type="hidden" name="pkg" value="` + escape(string(mp.ID)) + `">
+  <label>Render path: <input type="text" name="path" value="` + escape(path) + `"></label>
+  <input type="submit" value="Render">
+</form>
+<p>
+  Rendered from the editor buffers. Save a file to render again.
+</p>
+<hr>
+`)
+	if renderErr != nil {
+		fmt.Fprintf(&buf, "<pre class='error'>%s</pre>\n", escape(renderErr.Error()))
+	} else {
+		buf.WriteString("<div class='render'>\n")
+		buf.WriteString(gnorender.HTML(markdown))
+		buf.WriteString("</div>\n")
+	}
+	if output.Len() > 0 {
+		fmt.Fprintf(&buf, "<h2>Output</h2>\n<pre>%s</pre>\n", escape(output.String()))
+	}
+	buf.WriteString("</body>\n</html>\n")
+	return buf.Bytes(), nil
+}
+
+// realmMemPackage returns the package mp, without its tests, from the
+// contents of its files in the snapshot, to be run in a gnovm.
+func realmMemPackage(ctx context.Context, snapshot *cache.Snapshot, mp *metadata.Package) (*std.MemPackage, error) {
+	memPkg := &std.MemPackage{
+		Name: string(mp.Name),
+		Path: string(mp.PkgPath),
+	}
+	for _, uri := range mp.CompiledGoFiles {
+		name := filepath.Base(uri.Path())
+		if strings.HasSuffix(name, "_test.gno") || strings.HasSuffix(name, "_filetest.gno") {
+			continue
+		}
+		fh, err := snapshot.ReadFile(ctx, uri)
+		if err != nil {
+			return nil, err
+		}
+		content, err := fh.Content()
+		if err != nil {
+			return nil, err
+		}
+		memPkg.Files = append(memPkg.Files, &std.MemFile{Name: name, Body: string(content)})
+	}
+	return memPkg, nil
+}
+
+// isRenderFunc reports whether fd declares the Render function of a
+// realm: func Render(path string) string.
+func isRenderFunc(info *types.Info, fd *ast.FuncDecl) bool {
+	if fd.Recv != nil || fd.Name.Name != "Render" {
+		return false
+	}
+	obj, ok := info.Defs[fd.Name].(*types.Func)
+	if !ok {
+		return false
+	}
+	sig := obj.Signature()
+	return sig.TypeParams() == nil &&
+		sig.Params().Len() == 1 && types.Identical(sig.Params().At(0).Type(), types.Typ[types.String]) &&
+		sig.Results().Len() == 1 && types.Identical(sig.Results().At(0).Type(), types.Typ[types.String])
+}
@@ -0,0 +1,149 @@
+package golang
+
+// This file provides the routes of the Render functions of realms as
+// document symbols, and the definition of a route: its handler.
+//
+// See also:
+// - ../analysis/renderroutes - finds the routes, and reports those
+//   that are unreachable.
+// - ./symbols.go - adds the routes to the document symbols.
+// - ./definition.go - finds the handler of the route at the cursor.
+
+import (
+	"context"
+	"errors"
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"github.com/gfanton/gnopls/internal/analysis/renderroutes"
+	"github.com/gfanton/gnopls/internal/cache"
+	"github.com/gfanton/gnopls/internal/cache/metadata"
+	"github.com/gfanton/gnopls/internal/cache/parsego"
+	"github.com/gfanton/gnopls/internal/protocol"
+	"github.com/gfanton/gnopls/internal/util/astutil"
+)
+
+var errNoRoute = errors.New("no route found")
+
+// routeSymbols returns the routes of the file pgf of a realm as
+// document symbols.
+func routeSymbols(pgf *parsego.File) []protocol.DocumentSymbol {
+	var symbols []protocol.DocumentSymbol
+	for _, group := range renderroutes.Find(pgf.File, nil) {
+		for _, route := range group {
+			rng, err := pgf.NodeRange(route.Lit)
+			if err != nil {
+				continue
+			}
+			symbols = append(symbols, protocol.DocumentSymbol{
+				Name:           route.Lit.Value,
+				Detail:         route.Kind.String(),
+				Kind:           protocol.String,
+				Range:          rng,
+				SelectionRange: rng,
+			})
+		}
+	}
+	return symbols
+}
+
+// routeDefinition returns the location of the handler of the route of
+// a realm whose string literal is at pos, or errNoRoute if there is
+// none (see routeHandler).
+func routeDefinition(ctx context.Context, snapshot *cache.Snapshot, pkg *cache.Package, pgf *parsego.File, pos token.Pos) ([]protocol.Location, error) {
+	if !metadata.IsRealmPath(pkg.Metadata().PkgPath) {
+		return nil, errNoRoute
+	}
+	node, obj, err := routeHandler(pkg.TypesInfo(), pkg.Types(), pgf.File, pos)
+	if err != nil {
+		return nil, err
+	}
+	if obj != nil {
+		loc, err := mapPosition(ctx, pkg.FileSet(), snapshot, obj.Pos(), adjustedObjEnd(obj))
+		if err != nil {
+			return nil, err
+		}
+		return []protocol.Location{loc}, nil
+	}
+	loc, err := pgf.NodeLocation(node)
+	if err != nil {
+		return nil, err
+	}
+	return []protocol.Location{loc}, nil
+}
+
+// routeHandler returns the handler of the route of the file f of pkg
+// whose string literal is at pos, or errNoRoute if there is none: the
+// handler function of a mux pattern, and the function called by the
+// branch of a prefix or exact path, if it starts with a call, or else
+// the branch itself. The handler is either an object declared
+// elsewhere, or a node of f.
+func routeHandler(info *types.Info, pkg *types.Package, f *ast.File, pos token.Pos) (ast.Node, types.Object, error) {
+	var route *renderroutes.Route
+	for _, group := range renderroutes.Find(f, info) {
+		for i := range group {
+			if astutil.NodeContains(group[i].Lit, pos) {
+				route = &group[i]
+			}
+		}
+	}
+	if route == nil {
+		return nil, nil, errNoRoute
+	}
+
+	// The node of the handler, a function or the first statement of a
+	// branch.
+	var handler ast.Node
+	switch h := route.Handler.(type) {
+	case *ast.BlockStmt:
+		if len(h.List) > 0 {
+			handler = h.List[0]
+		}
+	case *ast.CaseClause:
+		if len(h.Body) > 0 {
+			handler = h.Body[0]
+		}
+	default:
+		handler = h
+	}
+	if handler == nil {
+		return route.Handler, nil, nil
+	}
+
+	// A branch that starts with a call is handled by the function it
+	// calls, e.g. return renderUser(path).
+	var fn ast.Expr
+	switch h := handler.(type) {
+	case *ast.ReturnStmt:
+		if len(h.Results) == 1 {
+			if call, ok := ast.Unparen(h.Results[0]).(*ast.CallExpr); ok {
+				fn = call.Fun
+			}
+		}
+	case *ast.ExprStmt:
+		if call, ok := ast.Unparen(h.X).(*ast.CallExpr); ok {
+			fn = call.Fun
+		}
+	case ast.Expr:
+		fn = h
+	}
+	var id *ast.Ident
+	switch fn := ast.Unparen(fn).(type) {
+	case *ast.Ident:
+		id = fn
+	case *ast.SelectorExpr:
+		id = fn.Sel
+	case *ast.FuncLit:
+		handler = fn.Type
+	}
+	if obj := info.Uses[id]; obj != nil && !isBuiltin(obj) {
+		// A branch is handled by a function of the realm, and not by,
+		// say, the ufmt.Sprintf formatting its output.
+		_, isFunc := obj.(*types.Func)
+		if route.Kind == renderroutes.Pattern || isFunc && obj.Pkg() == pkg {
+			return nil, obj, nil
+		}
+	}
+	return handler, nil, nil
+}
@@ -0,0 +1,147 @@
+package golang
+
+import (
+	"context"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+
+	"github.com/gfanton/gnopls/internal/cache/parsego"
+	"github.com/gfanton/gnopls/internal/protocol"
+)
+
+const routesSrc = `package routes
+
+import (
+	"strings"
+
+	"gno.land/p/demo/mux"
+)
+
+var router = mux.NewRouter()
+
+func init() {
+	router.HandleFunc("users/{name}", renderUser)
+	router.HandleFunc("about", func(res *mux.ResponseWriter, req *mux.Request) {})
+}
+
+func renderUser(res *mux.ResponseWriter, req *mux.Request) {}
+
+func renderPosts(path string) string { return "" }
+
+func Render(path string) string {
+	switch {
+	case strings.HasPrefix(path, "posts"):
+		return renderPosts(path)
+	case path == "help":
+		return strings.ToUpper("help")
+	case path == "empty":
+	}
+	return router.Render(path)
+}
+`
+
+const muxSrc = `package mux
+
+type ResponseWriter struct{}
+
+type Request struct{}
+
+type Router struct{}
+
+func NewRouter() *Router { return &Router{} }
+
+func (r *Router) HandleFunc(pattern string, fn func(*ResponseWriter, *Request)) {}
+
+func (r *Router) Render(path string) string { return "" }
+`
+
+// parseRoutes parses and type-checks routesSrc.
+func parseRoutes(t *testing.T) (*parsego.File, *types.Info, *types.Package) {
+	t.Helper()
+	fset := token.NewFileSet()
+	pgf, _ := parsego.Parse(context.Background(), fset, protocol.URIFromPath("/routes/routes.gno"), []byte(routesSrc), parsego.Full, false)
+	muxFile, err := parser.ParseFile(fset, "mux.gno", muxSrc, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux, err := new(types.Config).Check("gno.land/p/demo/mux", fset, []*ast.File{muxFile}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	std := importer.Default()
+	conf := types.Config{Importer: importerFunc(func(path string) (*types.Package, error) {
+		if path == mux.Path() {
+			return mux, nil
+		}
+		return std.Import(path)
+	})}
+	info := &types.Info{Uses: make(map[*ast.Ident]types.Object)}
+	pkg, err := conf.Check("gno.land/r/demo/routes", fset, []*ast.File{pgf.File}, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pgf, info, pkg
+}
+
+type importerFunc func(path string) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) { return f(path) }
+
+func TestRouteSymbols(t *testing.T) {
+	pgf, _, _ := parseRoutes(t)
+	var got []string
+	for _, sym := range routeSymbols(pgf) {
+		got = append(got, sym.Name+" "+sym.Detail)
+	}
+	want := []string{
+		`"users/{name}" route pattern`,
+		`"about" route pattern`,
+		`"posts" route prefix`,
+		`"help" route`,
+		`"empty" route`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("routeSymbols = %q, want %q", got, want)
+	}
+}
+
+func TestRouteHandler(t *testing.T) {
+	pgf, info, pkg := parseRoutes(t)
+	for _, test := range []struct {
+		route string // string literal of the route
+		want  string // name of the handler object, or source of its node
+	}{
+		{`"users/{name}"`, "renderUser"},                               // handler function
+		{`"about"`, "func(res *mux.ResponseWriter, req *mux.Request)"}, // function literal
+		{`"posts"`, "renderPosts"},                                     // called function
+		{`"help"`, `return strings.ToUpper("help")`},                   // call of another package
+		{`"empty"`, `case path == "empty":`},                           // empty branch
+	} {
+		pos := pgf.File.FileStart + token.Pos(strings.Index(routesSrc, test.route)+1)
+		node, obj, err := routeHandler(info, pkg, pgf.File, pos)
+		if err != nil {
+			t.Errorf("routeHandler(%s): %v", test.route, err)
+			continue
+		}
+		var got string
+		if obj != nil {
+			got = obj.Name()
+		} else {
+			start, end := pgf.Tok.Offset(node.Pos()), pgf.Tok.Offset(node.End())
+			got = routesSrc[start:end]
+		}
+		if got != test.want {
+			t.Errorf("routeHandler(%s) = %s, want %s", test.route, got, test.want)
+		}
+	}
+
+	pos := pgf.File.FileStart + token.Pos(strings.Index(routesSrc, "renderUser"))
+	if _, _, err := routeHandler(info, pkg, pgf.File, pos); err != errNoRoute {
+		t.Errorf("routeHandler outside of a route: got %v, want errNoRoute", err)
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/gfanton/gnopls/internal/cache/metadata"
 	"github.com/gfanton/gnopls/internal/cache/parsego"
 	"github.com/gfanton/gnopls/internal/file"
+	"github.com/gfanton/gnopls/internal/filetest"
 	"github.com/gfanton/gnopls/internal/protocol"
 	"github.com/gfanton/gnopls/internal/protocol/semtok"
 	"github.com/gfanton/gnopls/internal/util/bug"
@@ -139,12 +140,29 @@ func (tv *tokenVisitor) visit() {
 		}
 	}
 
+	// The directives of a filetest, by comment.
+	var directives map[*ast.Comment]*filetest.Directive
+	if filetest.IsFiletest(tv.pgf.URI.Path()) {
+		directives = make(map[*ast.Comment]*filetest.Directive)
+		ds, _ := filetest.Parse(f)
+		for _, d := range ds {
+			directives[d.Comment] = d
+			for _, c := range d.Content {
+				directives[c] = d
+			}
+		}
+	}
+
 	for _, cg := range f.Comments {
 		for _, c := range cg.List {
 			// Only look at the comment that overlap the range.
 			if c.End() <= tv.start || c.Pos() >= tv.end {
 				continue
 			}
+			if d, ok := directives[c]; ok {
+				tv.filetestDirective(c, d)
+				continue
+			}
 			tv.comment(c, importByName)
 		}
 	}
@@ -985,6 +1003,24 @@ func (tv *tokenVisitor) godirective(c *ast.Comment) {
 	}
 }
 
+// filetestDirective emits semantic tokens for the comment c, which is
+// part of the filetest directive d: the name of the directive stands out
+// as godirective does, while its value and the content of its block,
+// which is expected output rather than prose, are emitted as-is.
+func (tv *tokenVisitor) filetestDirective(c *ast.Comment, d *filetest.Directive) {
+	if c != d.Comment {
+		tv.token(c.Pos(), len(c.Text), semtok.TokComment, nil)
+		return
+	}
+
+	tv.token(c.Pos(), len("// "), semtok.TokComment, nil)
+	tv.token(d.NamePos(), len(d.Name), semtok.TokNamespace, nil)
+
+	if tail := c.Text[len("// ")+len(d.Name):]; len(tail) > 0 {
+		tv.token(d.NamePos()+token.Pos(len(d.Name)), len(tail), semtok.TokComment, nil)
+	}
+}
+
 // Go 1.20 strings.CutPrefix.
 func stringsCutPrefix(s, prefix string) (after string, found bool) {
 	if !strings.HasPrefix(s, prefix) {
@@ -12,6 +12,7 @@ import (
 	"go/types"
 
 	"github.com/gfanton/gnopls/internal/cache"
+	"github.com/gfanton/gnopls/internal/cache/metadata"
 	"github.com/gfanton/gnopls/internal/cache/parsego"
 	"github.com/gfanton/gnopls/internal/file"
 	"github.com/gfanton/gnopls/internal/protocol"
@@ -71,6 +72,11 @@ func DocumentSymbols(ctx context.Context, snapshot *cache.Snapshot, fh file.Hand
 			}
 		}
 	}
+
+	// Add the routes of the Render function of a realm.
+	if mp, err := NarrowestMetadataForFile(ctx, snapshot, fh.URI()); err == nil && metadata.IsRealmPath(mp.PkgPath) {
+		symbols = append(symbols, routeSymbols(pgf)...)
+	}
 	return symbols, nil
 }
 
@@ -330,6 +330,18 @@ func collectSymbols(ctx context.Context, snapshots []*cache.Snapshot, matcherTyp
 			seen[uri] = true
 			work = append(work, symbolFile{uri, meta, syms})
 		}
+
+		// The events emitted by the workspace packages.
+		eventFiles, err := eventSymbolFiles(ctx, snapshot)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range eventFiles {
+			nm := strings.TrimPrefix(filepath.ToSlash(f.uri.Path()), folder)
+			if !filterer.Disallow(nm) {
+				work = append(work, f)
+			}
+		}
 	}
 
 	// Match symbols in parallel.
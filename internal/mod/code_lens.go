@@ -21,7 +21,7 @@ import (
 // CodeLensSources returns the sources of code lenses for go.mod files.
 func CodeLensSources() map[settings.CodeLensSource]cache.CodeLensSourceFunc {
 	return map[settings.CodeLensSource]cache.CodeLensSourceFunc{
-		settings.CodeLensUpgradeDependency: upgradeLenses,   // commands: CheckUpgrades, UpgradeDependency
+		settings.CodeLensUpgradeDependency: upgradeLenses,   // commands: UpgradeDependency
 		settings.CodeLensTidy:              tidyLens,        // commands: Tidy
 		settings.CodeLensVendor:            vendorLens,      // commands: Vendor
 		settings.CodeLensRunGovulncheck:    vulncheckLenses, // commands: RunGovulncheck
@@ -45,36 +45,25 @@ func upgradeLenses(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle
 		// Nothing to upgrade.
 		return lenses, nil
 	}
+	// Gno packages are not versioned: an upgrade fetches their latest
+	// version again.
 	var requires []string
 	for _, req := range pm.File.Require {
-		requires = append(requires, req.Mod.Path)
+		requires = append(requires, req.Mod.Path+"@latest")
 	}
-	checkUpgrade := command.NewCheckUpgradesCommand("Check for upgrades", command.CheckUpgradesArgs{
-		URI:     uri,
-		Modules: requires,
-	})
-	upgradeTransitive := command.NewUpgradeDependencyCommand("Upgrade transitive dependencies", command.DependencyArgs{
-		URI:        uri,
-		AddRequire: false,
-		GoCmdArgs:  []string{"-d", "-u", "-t", "./..."},
-	})
-	upgradeDirect := command.NewUpgradeDependencyCommand("Upgrade direct dependencies", command.DependencyArgs{
+	upgrade := command.NewUpgradeDependencyCommand("Upgrade dependencies", command.DependencyArgs{
 		URI:        uri,
 		AddRequire: false,
-		GoCmdArgs:  append([]string{"-d"}, requires...),
+		GoCmdArgs:  requires,
 	})
 
-	// Put the upgrade code lenses above the first require block or statement.
+	// Put the upgrade code lens above the first require block or statement.
 	rng, err := firstRequireRange(fh, pm)
 	if err != nil {
 		return nil, err
 	}
 
-	return append(lenses, []protocol.CodeLens{
-		{Range: rng, Command: checkUpgrade},
-		{Range: rng, Command: upgradeTransitive},
-		{Range: rng, Command: upgradeDirect},
-	}...), nil
+	return append(lenses, protocol.CodeLens{Range: rng, Command: upgrade}), nil
 }
 
 func tidyLens(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle) ([]protocol.CodeLens, error) {
@@ -94,7 +94,8 @@ func collectDiagnostics(ctx context.Context, snapshot *cache.Snapshot, diagFn fu
 	return reports, nil
 }
 
-// ModParseDiagnostics reports diagnostics from parsing the mod file.
+// ModParseDiagnostics reports diagnostics from parsing and validating the
+// mod file.
 func ModParseDiagnostics(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle) (diagnostics []*cache.Diagnostic, err error) {
 	pm, err := snapshot.ParseMod(ctx, fh)
 	if err != nil {
@@ -103,7 +104,7 @@ func ModParseDiagnostics(ctx context.Context, snapshot *cache.Snapshot, fh file.
 		}
 		return pm.ParseErrors, nil
 	}
-	return nil, nil
+	return pm.ValidationErrors, nil
 }
 
 // ModTidyDiagnostics reports diagnostics from running go mod tidy.
@@ -0,0 +1,276 @@
+package mod
+
+// This file implements the operations on gno.mod files of the module
+// commands (Tidy, AddDependency, RemoveDependency, UpgradeDependency and
+// GoGetPackage), which are performed without the go command.
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gfanton/gnopls/internal/cache"
+	"github.com/gfanton/gnopls/internal/cache/parsego"
+	"github.com/gfanton/gnopls/internal/protocol"
+	"github.com/gfanton/gnopls/pkg/resolver"
+	"github.com/gnolang/gno/gnovm/pkg/gnomod"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+)
+
+// LatestVersion is the version of the requirements added to gno.mod
+// files: packages on chain have no version, so Gno requires them all at
+// this pseudo-version.
+const LatestVersion = "v0.0.0-latest"
+
+// Tidy returns the content of the gno.mod file pm with exactly one
+// requirement for each package imported by the packages of its module,
+// as "gno mod tidy" would: unused requirements are dropped, and missing
+// ones are added at LatestVersion. The versions of the requirements that
+// are kept, and the replace directives, are left unchanged.
+//
+// The packages of the module are those whose files are in the directory
+// of pm or a subdirectory without its own gno.mod file. Imports of the
+// standard libraries and of the packages of the module are not required.
+func Tidy(ctx context.Context, snapshot *cache.Snapshot, pm *cache.ParsedModule) ([]byte, error) {
+	if pm.File == nil || pm.File.Module == nil {
+		return nil, fmt.Errorf("%s has no module statement", pm.URI.Path())
+	}
+	mps, err := snapshot.AllMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// The imports are read from the files, as those the resolver can't
+	// find are missing from the metadata.
+	modDir := pm.URI.Dir()
+	var imports []string
+	seen := make(map[protocol.DocumentURI]bool)
+	for _, mp := range mps {
+		if len(mp.CompiledGoFiles) == 0 || !modDir.Encloses(mp.CompiledGoFiles[0]) {
+			continue
+		}
+		modURI, err := snapshot.GnoModForFile(ctx, mp.CompiledGoFiles[0])
+		if err != nil {
+			return nil, err
+		}
+		if modURI != pm.URI {
+			continue // a nested module
+		}
+		for _, uri := range mp.CompiledGoFiles {
+			if seen[uri] {
+				continue // e.g. in a test variant
+			}
+			seen[uri] = true
+			fh, err := snapshot.ReadFile(ctx, uri)
+			if err != nil {
+				return nil, err
+			}
+			pgf, err := snapshot.ParseGo(ctx, fh, parsego.Header)
+			if err != nil {
+				return nil, err
+			}
+			for _, spec := range pgf.File.Imports {
+				if path, err := strconv.Unquote(spec.Path.Value); err == nil {
+					imports = append(imports, path)
+				}
+			}
+		}
+	}
+	return tidyRequires(pm, imports)
+}
+
+// tidyRequires returns the content of the gno.mod file pm with exactly
+// one requirement for each of the imports that are neither standard
+// libraries nor packages of its module.
+func tidyRequires(pm *cache.ParsedModule, imports []string) ([]byte, error) {
+	modPath := pm.File.Module.Mod.Path
+	needed := make(map[string]bool)
+	for _, imp := range imports {
+		if isStdlib(imp) || imp == modPath || strings.HasPrefix(imp, modPath+"/") {
+			continue
+		}
+		needed[imp] = true
+	}
+
+	return editRequires(pm, func(f *gnomod.File) error {
+		required := make(map[string]bool)
+		for _, req := range f.Require {
+			required[req.Mod.Path] = true
+		}
+		for path := range required {
+			if !needed[path] {
+				if err := f.DropRequire(path); err != nil {
+					return err
+				}
+			}
+		}
+		var missing []string
+		for path := range needed {
+			if !required[path] {
+				missing = append(missing, path)
+			}
+		}
+		sort.Strings(missing)
+		for _, path := range missing {
+			if err := f.AddRequire(path, LatestVersion); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// AddRequires returns the content of the gno.mod file pm with a
+// requirement for each of the given packages, each in the form
+// "path@version" or "path", in which case it is required at
+// LatestVersion. The version of an existing requirement is updated.
+func AddRequires(pm *cache.ParsedModule, pkgs []string) ([]byte, error) {
+	return editRequires(pm, func(f *gnomod.File) error {
+		for _, pkg := range pkgs {
+			path, version, ok := strings.Cut(pkg, "@")
+			if !ok || version == "latest" {
+				version = LatestVersion
+			}
+			if err := module.CheckImportPath(path); err != nil {
+				return err
+			}
+			if err := f.AddRequire(path, version); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DropRequire returns the content of the gno.mod file pm without the
+// requirement of the package path.
+func DropRequire(pm *cache.ParsedModule, path string) ([]byte, error) {
+	return editRequires(pm, func(f *gnomod.File) error {
+		return f.DropRequire(path)
+	})
+}
+
+// editRequires returns the content of the gno.mod file pm after edit is
+// applied to a private copy of it.
+func editRequires(pm *cache.ParsedModule, edit func(*gnomod.File) error) ([]byte, error) {
+	// pm.File is shared by the snapshots: parse a copy to modify.
+	copied, err := gnomod.Parse(pm.URI.Path(), pm.Mapper.Content)
+	if err != nil {
+		return nil, err
+	}
+	if err := edit(copied); err != nil {
+		return nil, err
+	}
+	copied.Syntax.Cleanup()
+	return modfile.Format(copied.Syntax), nil
+}
+
+// isStdlib reports whether the import path denotes a standard library:
+// unlike other packages, their first path element has no dot.
+func isStdlib(importPath string) bool {
+	first, _, _ := strings.Cut(importPath, "/")
+	return !strings.Contains(first, ".")
+}
+
+// Get downloads the package pkgPath to the module cache of rc, where the
+// resolver finds the packages required by gno.mod files, and returns its
+// directory.
+//
+// The package is copied from the first of the package roots and the
+// examples of the Gno root directory of rc that has it, in the order the
+// resolver searches them, and fetched from the chain at the RPC address
+// remote otherwise. Get fails if no root has the package and remote is
+// empty.
+func Get(ctx context.Context, rc resolver.Config, remote, pkgPath string) (string, error) {
+	if err := module.CheckImportPath(pkgPath); err != nil {
+		return "", err
+	}
+	modCache := rc.ModCacheDir()
+	dst := gnomod.PackageDir(modCache, module.Version{Path: pkgPath})
+	// The requirements of the package may be downloaded too.
+	defer resolver.Invalidate(modCache)
+
+	if src := rootPackageDir(rc, pkgPath); src != "" {
+		return dst, copyPackage(dst, src)
+	}
+	if remote == "" {
+		return "", fmt.Errorf("package %s is not in the package roots nor in the examples, and no package source is configured (see the %q setting)", pkgPath, "packageSource")
+	}
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	// FetchDeps downloads the requirements of a gno.mod file, and
+	// theirs: fetch those of a file requiring only pkgPath.
+	mf := &gnomod.File{
+		Require: []*modfile.Require{{Mod: module.Version{Path: pkgPath, Version: LatestVersion}}},
+	}
+	if err := mf.FetchDeps(modCache, remote, false); err != nil {
+		return "", fmt.Errorf("fetching %s from %s: %w", pkgPath, remote, err)
+	}
+	return dst, nil
+}
+
+// rootPackageDir returns the directory of the package pkgPath in the
+// package roots or the examples of rc, or "" if none has it.
+func rootPackageDir(rc resolver.Config, pkgPath string) string {
+	for _, root := range rc.Roots() {
+		if root.Kind != resolver.PackageRoot && root.Kind != resolver.ExamplesRoot {
+			continue
+		}
+		pkgs, err := resolver.ListPkgs(root.Dir)
+		if err != nil {
+			continue // e.g. a root that doesn't exist
+		}
+		for _, pkg := range pkgs {
+			if pkg.Name == pkgPath {
+				return pkg.Dir
+			}
+		}
+	}
+	return ""
+}
+
+// copyPackage copies the files of the package directory src, but not
+// its subdirectories, which are other packages, to dst.
+func copyPackage(dst, src string) error {
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dst, 0o755); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		if err := copyFile(filepath.Join(dst, entry.Name()), filepath.Join(src, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func copyFile(dst, src string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,149 @@
+package mod
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gfanton/gnopls/internal/cache"
+	"github.com/gfanton/gnopls/internal/protocol"
+	"github.com/gfanton/gnopls/pkg/resolver"
+	"github.com/gnolang/gno/gnovm/pkg/gnomod"
+)
+
+func parsedModule(t *testing.T, content string) *cache.ParsedModule {
+	t.Helper()
+	uri := protocol.URIFromPath("/src/gno.mod")
+	f, err := gnomod.Parse(uri.Path(), []byte(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &cache.ParsedModule{URI: uri, File: f, Mapper: protocol.NewMapper(uri, []byte(content))}
+}
+
+func TestTidyRequires(t *testing.T) {
+	pm := parsedModule(t, `module gno.land/r/demo/foo
+
+require (
+	gno.land/p/demo/avl v0.0.0-latest
+	gno.land/p/demo/unused v0.0.0-latest
+)
+`)
+	imports := []string{
+		"std",
+		"strings",
+		"gno.land/p/demo/avl",
+		"gno.land/p/demo/ufmt",
+		"gno.land/r/demo/foo/internal",
+		"gno.land/p/demo/ufmt",
+	}
+	got, err := tidyRequires(pm, imports)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `module gno.land/r/demo/foo
+
+require (
+	gno.land/p/demo/avl v0.0.0-latest
+	gno.land/p/demo/ufmt v0.0.0-latest
+)
+`
+	if string(got) != want {
+		t.Errorf("tidyRequires() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestAddAndDropRequire(t *testing.T) {
+	pm := parsedModule(t, "module gno.land/r/demo/foo\n\nrequire gno.land/p/demo/avl v0.0.0-latest\n")
+
+	got, err := AddRequires(pm, []string{"gno.land/p/demo/ufmt", "gno.land/p/demo/avl@v1.0.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `module gno.land/r/demo/foo
+
+require (
+	gno.land/p/demo/avl v1.0.0
+	gno.land/p/demo/ufmt v0.0.0-latest
+)
+`
+	if string(got) != want {
+		t.Errorf("AddRequires() =\n%s\nwant:\n%s", got, want)
+	}
+
+	got, err = DropRequire(pm, "gno.land/p/demo/avl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "module gno.land/r/demo/foo\n"; string(got) != want {
+		t.Errorf("DropRequire() = %q, want %q", got, want)
+	}
+}
+
+func TestUpgradeRequires(t *testing.T) {
+	pm := parsedModule(t, "module gno.land/r/demo/foo\n\nrequire gno.land/p/demo/avl v1.0.0\n")
+
+	// The arguments of the upgrade code lens.
+	got, err := AddRequires(pm, []string{"gno.land/p/demo/avl@latest"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "module gno.land/r/demo/foo\n\nrequire gno.land/p/demo/avl v0.0.0-latest\n"; string(got) != want {
+		t.Errorf("AddRequires() = %q, want %q", got, want)
+	}
+
+	// Flags of the go command are not package paths.
+	if _, err := AddRequires(pm, []string{"-d", "gno.land/p/demo/avl"}); err == nil {
+		t.Errorf("AddRequires(-d) succeeded, want an error")
+	}
+}
+
+func TestGet(t *testing.T) {
+	dir := t.TempDir()
+	addPackage := func(dir, modPath, file string) {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "gno.mod"), []byte("module "+modPath+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, file), []byte("package "+filepath.Base(modPath)+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	gnoRoot := filepath.Join(dir, "gno")
+	pkgRoot := filepath.Join(dir, "pkgs")
+	addPackage(filepath.Join(gnoRoot, "examples", "gno.land", "p", "demo", "avl"), "gno.land/p/demo/avl", "example.gno")
+	addPackage(filepath.Join(gnoRoot, "examples", "gno.land", "p", "demo", "ufmt"), "gno.land/p/demo/ufmt", "example.gno")
+	// The directories of a package root need not match the package paths.
+	addPackage(filepath.Join(pkgRoot, "avl"), "gno.land/p/demo/avl", "root.gno")
+	rc := resolver.Config{
+		GnoRoot:      gnoRoot,
+		PackageRoots: []string{pkgRoot},
+		ModCache:     filepath.Join(dir, "modcache"),
+	}
+
+	for _, test := range []struct {
+		pkgPath, file string // the file the downloaded package must have
+	}{
+		{"gno.land/p/demo/avl", "root.gno"}, // the package root comes first
+		{"gno.land/p/demo/ufmt", "example.gno"},
+	} {
+		got, err := Get(context.Background(), rc, "", test.pkgPath)
+		if err != nil {
+			t.Errorf("Get(%s): %v", test.pkgPath, err)
+			continue
+		}
+		if want := filepath.Join(rc.ModCache, filepath.FromSlash(test.pkgPath)); got != want {
+			t.Errorf("Get(%s) = %s, want %s", test.pkgPath, got, want)
+		}
+		if _, err := os.Stat(filepath.Join(got, test.file)); err != nil {
+			t.Errorf("Get(%s): %v", test.pkgPath, err)
+		}
+	}
+
+	if _, err := Get(context.Background(), rc, "", "gno.land/p/demo/missing"); err == nil {
+		t.Errorf("Get of a missing package without a package source succeeded")
+	}
+}
@@ -7,6 +7,7 @@ package mod
 import (
 	"context"
 
+	"golang.org/x/mod/modfile"
 	"github.com/gfanton/gnopls/internal/cache"
 	"github.com/gfanton/gnopls/internal/file"
 	"github.com/gfanton/gnopls/internal/protocol"
@@ -22,10 +23,7 @@ func Format(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle) ([]pr
 	if err != nil {
 		return nil, err
 	}
-	formatted, err := pm.File.Format()
-	if err != nil {
-		return nil, err
-	}
+	formatted := modfile.Format(pm.File.Syntax)
 	// Calculate the edits to be made due to the change.
 	diffs := diff.Bytes(pm.Mapper.Content, formatted)
 	return protocol.EditsFromDiffEdits(pm.Mapper, diffs)
@@ -8,13 +8,17 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 
+	"github.com/gnolang/gno/gnovm/pkg/gnomod"
 	"golang.org/x/mod/modfile"
 	"golang.org/x/mod/semver"
 	"github.com/gfanton/gnopls/internal/cache"
 	"github.com/gfanton/gnopls/internal/file"
+	"github.com/gfanton/gnopls/internal/filetest"
 	"github.com/gfanton/gnopls/internal/protocol"
 	"github.com/gfanton/gnopls/internal/settings"
 	"github.com/gfanton/gnopls/internal/vulncheck"
@@ -96,16 +100,6 @@ func hoverOnRequireStatement(ctx context.Context, pm *cache.ParsedModule, offset
 	}
 	affecting, nonaffecting, osvs := lookupVulns(vs, req.Mod.Path, req.Mod.Version)
 
-	// Get the `go mod why` results for the given file.
-	why, err := snapshot.ModWhy(ctx, fh)
-	if err != nil {
-		return nil, err
-	}
-	explanation, ok := why[req.Mod.Path]
-	if !ok {
-		return nil, nil
-	}
-
 	// Get the range to highlight for the hover.
 	// TODO(hyangah): adjust the hover range to include the version number
 	// to match the diagnostics' range.
@@ -114,15 +108,14 @@ func hoverOnRequireStatement(ctx context.Context, pm *cache.ParsedModule, offset
 		return nil, err
 	}
 	options := snapshot.Options()
-	isPrivate := snapshot.IsGoPrivatePath(req.Mod.Path)
 	header := formatHeader(req.Mod.Path, options)
-	explanation = formatExplanation(explanation, req, options, isPrivate)
+	location := formatPackageDir(requireDir(ctx, snapshot, pm, req), options)
 	vulns := formatVulnerabilities(affecting, nonaffecting, osvs, options, fromGovulncheck)
 
 	return &protocol.Hover{
 		Contents: protocol.MarkupContent{
 			Kind:  options.PreferredContentFormat,
-			Value: header + vulns + explanation,
+			Value: header + location + vulns,
 		},
 		Range: rng,
 	}, nil
@@ -327,54 +320,48 @@ func vulnerablePkgsInfo(findings []*govulncheck.Finding, useMarkdown bool) strin
 	return b.String()
 }
 
-func formatExplanation(text string, req *modfile.Require, options *settings.Options, isPrivate bool) string {
-	text = strings.TrimSuffix(text, "\n")
-	splt := strings.Split(text, "\n")
-	length := len(splt)
-
-	var b strings.Builder
-
-	// If the explanation is 2 lines, then it is of the form:
-	// # golang.org/x/text/encoding
-	// (main module does not need package golang.org/x/text/encoding)
-	if length == 2 {
-		b.WriteString(splt[1])
-		return b.String()
+// requireDir returns the local directory of the package required by
+// req in the gno.mod file pm, or "" if it cannot be found. The package
+// is looked up, in order, as the target directory of a replace
+// directive, as a package loaded from the workspace or the Gno root, and
+// as a package downloaded to the module cache.
+func requireDir(ctx context.Context, snapshot *cache.Snapshot, pm *cache.ParsedModule, req *modfile.Require) string {
+	mod := pm.File.Resolve(req)
+	if modfile.IsDirectoryPath(mod.Path) {
+		dir := filepath.FromSlash(mod.Path)
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(filepath.Dir(pm.URI.Path()), dir)
+		}
+		return filepath.Clean(dir)
 	}
 
-	imp := splt[length-1] // import path
-	reference := imp
-	// See golang/go#36998: don't link to modules matching GOPRIVATE.
-	if !isPrivate && options.PreferredContentFormat == protocol.Markdown {
-		target := imp
-		if strings.ToLower(options.LinkTarget) == "pkg.go.dev" {
-			target = strings.Replace(target, req.Mod.Path, req.Mod.String(), 1)
+	if mps, err := snapshot.AllMetadata(ctx); err == nil {
+		for _, mp := range mps {
+			// Skip test variants and filetests, whose package path
+			// may be that of the package they test.
+			if string(mp.PkgPath) != mod.Path || mp.ForTest != "" || filetest.IsFiletest(string(mp.ID)) {
+				continue
+			}
+			if len(mp.CompiledGoFiles) > 0 {
+				return mp.CompiledGoFiles[0].Dir().Path()
+			}
 		}
-		reference = fmt.Sprintf("[%s](%s)", imp, cache.BuildLink(options.LinkTarget, target, ""))
 	}
-	b.WriteString("This module is necessary because " + reference + " is imported in")
-
-	// If the explanation is 3 lines, then it is of the form:
-	// # golang.org/x/tools
-	// modtest
-	// github.com/gfanton/gnopls/internal/packages
-	if length == 3 {
-		msg := fmt.Sprintf(" `%s`.", splt[1])
-		b.WriteString(msg)
-		return b.String()
+
+	if dir := gnomod.PackageDir("", mod); dir != "" {
+		if _, err := os.Stat(dir); err == nil {
+			return dir
+		}
 	}
+	return ""
+}
 
-	// If the explanation is more than 3 lines, then it is of the form:
-	// # golang.org/x/text/language
-	// rsc.io/quote
-	// rsc.io/sampler
-	// golang.org/x/text/language
-	b.WriteString(":\n```text")
-	dash := ""
-	for _, imp := range splt[1 : length-1] {
-		dash += "-"
-		b.WriteString("\n" + dash + " " + imp)
+func formatPackageDir(dir string, options *settings.Options) string {
+	if dir == "" {
+		return "Package not found locally.\n"
 	}
-	b.WriteString("\n```")
-	return b.String()
+	if options.PreferredContentFormat == protocol.Markdown {
+		return fmt.Sprintf("Package directory: [%s](%s)\n", dir, protocol.URIFromPath(dir))
+	}
+	return fmt.Sprintf("Package directory: %s\n", dir)
 }
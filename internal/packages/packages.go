@@ -557,6 +557,9 @@ func init() {
 	packagesinternal.GetForTest = func(p interface{}) string {
 		return p.(*Package).forTest
 	}
+	packagesinternal.SetForTest = func(p interface{}, forTest string) {
+		p.(*Package).forTest = forTest
+	}
 	packagesinternal.GetDepsErrors = func(p interface{}) []*packagesinternal.PackageError {
 		return p.(*Package).depsErrors
 	}
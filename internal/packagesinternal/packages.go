@@ -6,6 +6,7 @@
 package packagesinternal
 
 var GetForTest = func(p interface{}) string { return "" }
+var SetForTest = func(p interface{}, forTest string) {}
 var GetDepsErrors = func(p interface{}) []*PackageError { return nil }
 
 type PackageError struct {
@@ -35,6 +35,7 @@ const (
 	DiagnoseFiles           Command = "gnopls.diagnose_files"
 	Doc                     Command = "gnopls.doc"
 	EditGoDirective         Command = "gnopls.edit_go_directive"
+	Estimate                Command = "gnopls.estimate"
 	ExtractToNewFile        Command = "gnopls.extract_to_new_file"
 	FetchVulncheckResult    Command = "gnopls.fetch_vulncheck_result"
 	FreeSymbols             Command = "gnopls.free_symbols"
@@ -43,12 +44,15 @@ const (
 	GoGetPackage            Command = "gnopls.go_get_package"
 	ListImports             Command = "gnopls.list_imports"
 	ListKnownPackages       Command = "gnopls.list_known_packages"
+	MakeTx                  Command = "gnopls.make_tx"
 	MaybePromptForTelemetry Command = "gnopls.maybe_prompt_for_telemetry"
 	MemStats                Command = "gnopls.mem_stats"
 	Modules                 Command = "gnopls.modules"
 	Packages                Command = "gnopls.packages"
+	RealmState              Command = "gnopls.realm_state"
 	RegenerateCgo           Command = "gnopls.regenerate_cgo"
 	RemoveDependency        Command = "gnopls.remove_dependency"
+	RenderPreview           Command = "gnopls.render_preview"
 	ResetGoModDiagnostics   Command = "gnopls.reset_go_mod_diagnostics"
 	RunGoWorkCommand        Command = "gnopls.run_go_work_command"
 	RunGovulncheck          Command = "gnopls.run_govulncheck"
@@ -60,6 +64,7 @@ const (
 	Test                    Command = "gnopls.test"
 	Tidy                    Command = "gnopls.tidy"
 	ToggleGCDetails         Command = "gnopls.toggle_gc_details"
+	UpdateFiletest          Command = "gnopls.update_filetest"
 	UpdateGoSum             Command = "gnopls.update_go_sum"
 	UpgradeDependency       Command = "gnopls.upgrade_dependency"
 	Vendor                  Command = "gnopls.vendor"
@@ -79,6 +84,7 @@ var Commands = []Command{
 	DiagnoseFiles,
 	Doc,
 	EditGoDirective,
+	Estimate,
 	ExtractToNewFile,
 	FetchVulncheckResult,
 	FreeSymbols,
@@ -87,12 +93,15 @@ var Commands = []Command{
 	GoGetPackage,
 	ListImports,
 	ListKnownPackages,
+	MakeTx,
 	MaybePromptForTelemetry,
 	MemStats,
 	Modules,
 	Packages,
+	RealmState,
 	RegenerateCgo,
 	RemoveDependency,
+	RenderPreview,
 	ResetGoModDiagnostics,
 	RunGoWorkCommand,
 	RunGovulncheck,
@@ -104,6 +113,7 @@ var Commands = []Command{
 	Test,
 	Tidy,
 	ToggleGCDetails,
+	UpdateFiletest,
 	UpdateGoSum,
 	UpgradeDependency,
 	Vendor,
@@ -181,6 +191,12 @@ func Dispatch(ctx context.Context, params *protocol.ExecuteCommandParams, s Inte
 			return nil, err
 		}
 		return nil, s.EditGoDirective(ctx, a0)
+	case Estimate:
+		var a0 EstimateArgs
+		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
+			return nil, err
+		}
+		return nil, s.Estimate(ctx, a0)
 	case ExtractToNewFile:
 		var a0 protocol.Location
 		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
@@ -230,6 +246,12 @@ func Dispatch(ctx context.Context, params *protocol.ExecuteCommandParams, s Inte
 			return nil, err
 		}
 		return s.ListKnownPackages(ctx, a0)
+	case MakeTx:
+		var a0 MakeTxArgs
+		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
+			return nil, err
+		}
+		return nil, s.MakeTx(ctx, a0)
 	case MaybePromptForTelemetry:
 		return nil, s.MaybePromptForTelemetry(ctx)
 	case MemStats:
@@ -246,6 +268,13 @@ func Dispatch(ctx context.Context, params *protocol.ExecuteCommandParams, s Inte
 			return nil, err
 		}
 		return s.Packages(ctx, a0)
+	case RealmState:
+		var a0 string
+		var a1 string
+		if err := UnmarshalArgs(params.Arguments, &a0, &a1); err != nil {
+			return nil, err
+		}
+		return nil, s.RealmState(ctx, a0, a1)
 	case RegenerateCgo:
 		var a0 URIArg
 		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
@@ -258,6 +287,13 @@ func Dispatch(ctx context.Context, params *protocol.ExecuteCommandParams, s Inte
 			return nil, err
 		}
 		return nil, s.RemoveDependency(ctx, a0)
+	case RenderPreview:
+		var a0 string
+		var a1 string
+		if err := UnmarshalArgs(params.Arguments, &a0, &a1); err != nil {
+			return nil, err
+		}
+		return nil, s.RenderPreview(ctx, a0, a1)
 	case ResetGoModDiagnostics:
 		var a0 ResetGoModDiagnosticsArgs
 		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
@@ -322,6 +358,12 @@ func Dispatch(ctx context.Context, params *protocol.ExecuteCommandParams, s Inte
 			return nil, err
 		}
 		return nil, s.ToggleGCDetails(ctx, a0)
+	case UpdateFiletest:
+		var a0 URIArg
+		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
+			return nil, err
+		}
+		return nil, s.UpdateFiletest(ctx, a0)
 	case UpdateGoSum:
 		var a0 URIArgs
 		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
@@ -436,6 +478,14 @@ func NewEditGoDirectiveCommand(title string, a0 EditGoDirectiveArgs) *protocol.C
 	}
 }
 
+func NewEstimateCommand(title string, a0 EstimateArgs) *protocol.Command {
+	return &protocol.Command{
+		Title:     title,
+		Command:   Estimate.String(),
+		Arguments: MustMarshalArgs(a0),
+	}
+}
+
 func NewExtractToNewFileCommand(title string, a0 protocol.Location) *protocol.Command {
 	return &protocol.Command{
 		Title:     title,
@@ -500,6 +550,14 @@ func NewListKnownPackagesCommand(title string, a0 URIArg) *protocol.Command {
 	}
 }
 
+func NewMakeTxCommand(title string, a0 MakeTxArgs) *protocol.Command {
+	return &protocol.Command{
+		Title:     title,
+		Command:   MakeTx.String(),
+		Arguments: MustMarshalArgs(a0),
+	}
+}
+
 func NewMaybePromptForTelemetryCommand(title string) *protocol.Command {
 	return &protocol.Command{
 		Title:     title,
@@ -532,6 +590,14 @@ func NewPackagesCommand(title string, a0 PackagesArgs) *protocol.Command {
 	}
 }
 
+func NewRealmStateCommand(title string, a0 string, a1 string) *protocol.Command {
+	return &protocol.Command{
+		Title:     title,
+		Command:   RealmState.String(),
+		Arguments: MustMarshalArgs(a0, a1),
+	}
+}
+
 func NewRegenerateCgoCommand(title string, a0 URIArg) *protocol.Command {
 	return &protocol.Command{
 		Title:     title,
@@ -548,6 +614,14 @@ func NewRemoveDependencyCommand(title string, a0 RemoveDependencyArgs) *protocol
 	}
 }
 
+func NewRenderPreviewCommand(title string, a0 string, a1 string) *protocol.Command {
+	return &protocol.Command{
+		Title:     title,
+		Command:   RenderPreview.String(),
+		Arguments: MustMarshalArgs(a0, a1),
+	}
+}
+
 func NewResetGoModDiagnosticsCommand(title string, a0 ResetGoModDiagnosticsArgs) *protocol.Command {
 	return &protocol.Command{
 		Title:     title,
@@ -636,6 +710,14 @@ func NewToggleGCDetailsCommand(title string, a0 URIArg) *protocol.Command {
 	}
 }
 
+func NewUpdateFiletestCommand(title string, a0 URIArg) *protocol.Command {
+	return &protocol.Command{
+		Title:     title,
+		Command:   UpdateFiletest.String(),
+		Arguments: MustMarshalArgs(a0),
+	}
+}
+
 func NewUpdateGoSumCommand(title string, a0 URIArgs) *protocol.Command {
 	return &protocol.Command{
 		Title:     title,
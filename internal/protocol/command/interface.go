@@ -47,7 +47,7 @@ type Interface interface {
 
 	// Test: Run test(s) (legacy)
 	//
-	// Runs `go test` for a specific set of test or benchmark functions.
+	// Runs `gno test` for a specific set of test functions.
 	//
 	// This command is asynchronous; wait for the 'end' progress notification.
 	//
@@ -59,11 +59,20 @@ type Interface interface {
 
 	// Test: Run test(s)
 	//
-	// Runs `go test` for a specific set of test or benchmark functions.
+	// Runs `gno test` for a specific set of test functions, in-process.
+	// Benchmarks are not supported. Failures are reported as diagnostics
+	// on the failing test functions.
 	//
 	// This command is asynchronous; clients must wait for the 'end' progress notification.
 	RunTests(context.Context, RunTestsArgs) error
 
+	// UpdateFiletest: Update filetest expectations
+	//
+	// Runs a `_filetest.gno` file and rewrites the expected blocks of
+	// its directives, such as `// Output:` or `// Realm:`, with the
+	// actual results of the run, like `gno test -update-golden-tests`.
+	UpdateFiletest(context.Context, URIArg) error
+
 	// Generate: Run go generate
 	//
 	// Runs `go generate` for a given directory.
@@ -80,9 +89,10 @@ type Interface interface {
 	// Regenerates cgo definitions.
 	RegenerateCgo(context.Context, URIArg) error
 
-	// Tidy: Run go mod tidy
+	// Tidy: Tidy gno.mod
 	//
-	// Runs `go mod tidy` for a module.
+	// Updates the requirements of the gno.mod file of a module to the
+	// packages imported by the module, as `gno mod tidy` does.
 	Tidy(context.Context, URIArgs) error
 
 	// Vendor: Run go mod vendor
@@ -107,17 +117,17 @@ type Interface interface {
 
 	// AddDependency: Add a dependency
 	//
-	// Adds a dependency to the go.mod file for a module.
+	// Adds a dependency to the gno.mod file for a module.
 	AddDependency(context.Context, DependencyArgs) error
 
 	// UpgradeDependency: Upgrade a dependency
 	//
-	// Upgrades a dependency in the go.mod file for a module.
+	// Upgrades a dependency in the gno.mod file for a module.
 	UpgradeDependency(context.Context, DependencyArgs) error
 
 	// RemoveDependency: Remove a dependency
 	//
-	// Removes a dependency from the go.mod file of a module.
+	// Removes a dependency from the gno.mod file of a module.
 	RemoveDependency(context.Context, RemoveDependencyArgs) error
 
 	// ResetGoModDiagnostics: Reset go.mod diagnostics
@@ -125,9 +135,11 @@ type Interface interface {
 	// Reset diagnostics in the go.mod file of a module.
 	ResetGoModDiagnostics(context.Context, ResetGoModDiagnosticsArgs) error
 
-	// GoGetPackage: 'go get' a package
+	// GoGetPackage: Fetch a package
 	//
-	// Runs `go get` to fetch a package.
+	// Downloads a package to the module cache, from the package roots, the
+	// examples of the Gno root directory or the configured package source, and
+	// optionally requires it in the gno.mod file of the module.
 	GoGetPackage(context.Context, GoGetPackageArgs) error
 
 	// GCDetails: Toggle gc_details
@@ -265,6 +277,43 @@ type Interface interface {
 	// The machine architecture is determined by the view.
 	Assembly(_ context.Context, viewID, packageID, symbol string) error
 
+	// RenderPreview: Browse the Render output of a realm in a browser.
+	//
+	// This command opens a web page showing the Markdown returned by
+	// the Render function of the specified realm package, as gnoweb
+	// would display it. The realm is deployed to an in-memory gnovm
+	// from the current editor buffers, and rendered again each time a
+	// file is saved.
+	RenderPreview(_ context.Context, viewID, packageID string) error
+
+	// RealmState: Browse the persisted state of a realm in a browser.
+	//
+	// This command opens a web page showing the value of each
+	// package-level variable of the specified realm package, as
+	// persisted by the chain. The values are read from the node or
+	// the state file of the realmState setting.
+	RealmState(_ context.Context, viewID, packageID string) error
+
+	// Estimate: Estimate the costs of calling a realm function.
+	//
+	// This command executes a call of an exported function of a realm
+	// in an in-memory gnovm, from the current editor buffers, and
+	// reports the gas it uses and the bytes it allocates and persists,
+	// with their changes since the last estimate of the same call. The
+	// mock context of the call is set by the estimateCaller,
+	// estimateSend and estimateHeight settings.
+	Estimate(context.Context, EstimateArgs) error
+
+	// MakeTx: Generate a gnokey transaction calling a realm function.
+	//
+	// This command generates a transaction calling the specified
+	// exported function of a realm, with placeholders for its
+	// arguments, and opens it in the editor: either the `gnokey maketx
+	// call` command line, the MsgCall transaction it prints, or, for
+	// functions whose parameters are not all of primitive types, a
+	// `gnokey maketx run` script whose main function calls it.
+	MakeTx(context.Context, MakeTxArgs) error
+
 	// ClientOpenURL: Request that the client open a URL in a browser.
 	ClientOpenURL(_ context.Context, url string) error
 
@@ -331,6 +380,30 @@ type ApplyFixArgs struct {
 	ResolveEdits bool
 }
 
+type EstimateArgs struct {
+	// A file of the realm.
+	URI protocol.DocumentURI
+
+	// The name of the function to call.
+	Function string
+
+	// The arguments of the call, as Gno expressions.
+	Args []string
+}
+
+type MakeTxArgs struct {
+	// A file of the realm.
+	URI protocol.DocumentURI
+
+	// The name of the function to call.
+	Function string
+
+	// The kind of transaction: "call" for a gnokey maketx call command
+	// line, "json" for a MsgCall transaction, or "run" for a gnokey
+	// maketx run script.
+	Kind string
+}
+
 type URIArg struct {
 	// The file URI.
 	URI protocol.DocumentURI
@@ -349,22 +422,21 @@ type CheckUpgradesArgs struct {
 }
 
 type DependencyArgs struct {
-	// The go.mod file URI.
+	// The gno.mod file URI.
 	URI protocol.DocumentURI
-	// Additional args to pass to the go command.
+	// The packages to require, as "path" or "path@version".
 	GoCmdArgs []string
 	// Whether to add a require directive.
 	AddRequire bool
 }
 
 type RemoveDependencyArgs struct {
-	// The go.mod file URI.
+	// The gno.mod file URI.
 	URI protocol.DocumentURI
 	// The module path to remove.
 	ModulePath string
-	// If the module is tidied apart from the one unused diagnostic, we can
-	// run `go get module@none`, and then run `go mod tidy`. Otherwise, we
-	// must make textual edits.
+	// Whether the module is tidied apart from the one unused diagnostic.
+	// The requirement is removed by an edit of the gno.mod file either way.
 	OnlyDiagnostic bool
 }
 
@@ -378,7 +450,7 @@ type EditGoDirectiveArgs struct {
 type GoGetPackageArgs struct {
 	// Any document URI within the relevant module.
 	URI protocol.DocumentURI
-	// The package to go get.
+	// The package to fetch.
 	Pkg        string
 	AddRequire bool
 }
@@ -545,6 +617,7 @@ type ViewStats struct {
 	AllPackages       PackageStats // package info for all packages (incl. dependencies)
 	WorkspacePackages PackageStats // package info for workspace packages
 	Diagnostics       int          // total number of diagnostics in the workspace
+	Roots             []RootStats  // packages of the workspace and of each package root
 }
 
 // PackageStats holds information about a collection of packages.
@@ -555,6 +628,12 @@ type PackageStats struct {
 	Modules         int // total number of unique modules
 }
 
+// RootStats lists the packages that were resolved from a package root.
+type RootStats struct {
+	PackageRoot
+	Packages []string // sorted paths of the packages of the root
+}
+
 type RunGoWorkArgs struct {
 	ViewID    string   // ID of the view to run the command from
 	InitFirst bool     // Whether to run `go work init` first
@@ -588,6 +667,13 @@ type View struct {
 	Root       protocol.DocumentURI // root dir of the view (e.g. containing go.mod or go.work)
 	Folder     protocol.DocumentURI // workspace folder associated with the view
 	EnvOverlay []string             // environment variable overrides
+	Roots      []PackageRoot        // package roots, in the order imports are resolved against them
+}
+
+// A PackageRoot is a directory in which imports are resolved.
+type PackageRoot struct {
+	Kind string               // "workspace", "stdlibs", "root", "examples" or "modcache"
+	Dir  protocol.DocumentURI // root directory
 }
 
 // PackagesArgs holds arguments for the Packages command.
@@ -647,7 +733,7 @@ type Package struct {
 	// from the TestFile's file name.
 
 	// TestFiles contains the subset of the files of the package
-	// whose name ends with "_test.go".
+	// whose name ends with "_test.gno".
 	// They are ordered deterministically as determined
 	// by the underlying build system.
 	TestFiles []TestFile
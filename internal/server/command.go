@@ -10,8 +10,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"go/types"
 	"io"
 	"log"
+	"maps"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -29,8 +31,11 @@ import (
 	"github.com/gfanton/gnopls/internal/diff"
 	"github.com/gfanton/gnopls/internal/event"
 	"github.com/gfanton/gnopls/internal/file"
+	"github.com/gfanton/gnopls/internal/filetest"
+	"github.com/gfanton/gnopls/internal/gnotest"
 	"github.com/gfanton/gnopls/internal/gocommand"
 	"github.com/gfanton/gnopls/internal/golang"
+	"github.com/gfanton/gnopls/internal/mod"
 	"github.com/gfanton/gnopls/internal/progress"
 	"github.com/gfanton/gnopls/internal/protocol"
 	"github.com/gfanton/gnopls/internal/protocol/command"
@@ -40,7 +45,6 @@ import (
 	"github.com/gfanton/gnopls/internal/vulncheck"
 	"github.com/gfanton/gnopls/internal/vulncheck/scan"
 	"github.com/gfanton/gnopls/internal/xcontext"
-	"golang.org/x/mod/modfile"
 	"golang.org/x/telemetry/counter"
 	"golang.org/x/tools/go/ast/astutil"
 )
@@ -478,11 +482,34 @@ func (c *commandHandler) CheckUpgrades(ctx context.Context, args command.CheckUp
 }
 
 func (c *commandHandler) AddDependency(ctx context.Context, args command.DependencyArgs) error {
-	return c.GoGetModule(ctx, args)
+	return c.run(ctx, commandConfig{
+		progress: "Adding dependency",
+		forURI:   args.URI,
+	}, func(ctx context.Context, deps commandDeps) error {
+		return c.updateGnoMod(ctx, deps.snapshot, args.URI, func(pm *cache.ParsedModule) ([]byte, error) {
+			return mod.AddRequires(pm, args.GoCmdArgs)
+		})
+	})
 }
 
 func (c *commandHandler) UpgradeDependency(ctx context.Context, args command.DependencyArgs) error {
-	return c.GoGetModule(ctx, args)
+	return c.run(ctx, commandConfig{
+		progress: "Upgrading dependency",
+		forURI:   args.URI,
+	}, func(ctx context.Context, deps commandDeps) error {
+		remote := deps.snapshot.Options().PackageSource
+		for _, pkg := range args.GoCmdArgs {
+			path, _, _ := strings.Cut(pkg, "@")
+			dir, err := mod.Get(ctx, deps.snapshot.View().ResolverConfig(), remote, path)
+			if err != nil {
+				return err
+			}
+			event.Log(ctx, fmt.Sprintf("fetched %s to %s", path, dir))
+		}
+		return c.updateGnoMod(ctx, deps.snapshot, args.URI, func(pm *cache.ParsedModule) ([]byte, error) {
+			return mod.AddRequires(pm, args.GoCmdArgs)
+		})
+	})
 }
 
 func (c *commandHandler) ResetGoModDiagnostics(ctx context.Context, args command.ResetGoModDiagnosticsArgs) error {
@@ -537,7 +564,7 @@ func (c *commandHandler) UpdateGoSum(ctx context.Context, args command.URIArgs)
 
 func (c *commandHandler) Tidy(ctx context.Context, args command.URIArgs) error {
 	return c.run(ctx, commandConfig{
-		progress: "Running go mod tidy",
+		progress: "Tidying gno.mod",
 	}, func(ctx context.Context, _ commandDeps) error {
 		for _, uri := range args.URIs {
 			fh, snapshot, release, err := c.s.fileOf(ctx, uri)
@@ -545,9 +572,8 @@ func (c *commandHandler) Tidy(ctx context.Context, args command.URIArgs) error {
 				return err
 			}
 			defer release()
-			if err := c.s.runGoModUpdateCommands(ctx, snapshot, fh.URI(), func(invoke func(...string) (*bytes.Buffer, error)) error {
-				_, err := invoke("mod", "tidy")
-				return err
+			if err := c.updateGnoMod(ctx, snapshot, fh.URI(), func(pm *cache.ParsedModule) ([]byte, error) {
+				return mod.Tidy(ctx, snapshot, pm)
 			}); err != nil {
 				return err
 			}
@@ -613,61 +639,47 @@ func (c *commandHandler) RemoveDependency(ctx context.Context, args command.Remo
 		progress: "Removing dependency",
 		forURI:   args.URI,
 	}, func(ctx context.Context, deps commandDeps) error {
-		// See the documentation for OnlyDiagnostic.
-		//
-		// TODO(rfindley): In Go 1.17+, we will be able to use the go command
-		// without checking if the module is tidy.
-		if args.OnlyDiagnostic {
-			return c.s.runGoModUpdateCommands(ctx, deps.snapshot, args.URI, func(invoke func(...string) (*bytes.Buffer, error)) error {
-				if err := runGoGetModule(invoke, false, []string{args.ModulePath + "@none"}); err != nil {
-					return err
-				}
-				_, err := invoke("mod", "tidy")
-				return err
-			})
-		}
-		pm, err := deps.snapshot.ParseMod(ctx, deps.fh)
-		if err != nil {
-			return err
-		}
-		edits, err := dropDependency(pm, args.ModulePath)
-		if err != nil {
-			return err
-		}
-		response, err := c.s.client.ApplyEdit(ctx, &protocol.ApplyWorkspaceEditParams{
-			Edit: *protocol.NewWorkspaceEdit(
-				protocol.DocumentChangeEdit(deps.fh, edits)),
+		// The requirement is removed by an edit whether or not it is the
+		// only difference with the tidied gno.mod file (see
+		// OnlyDiagnostic): unlike "go get", editing doesn't require the
+		// rest of the file to be tidy.
+		return c.updateGnoMod(ctx, deps.snapshot, args.URI, func(pm *cache.ParsedModule) ([]byte, error) {
+			return mod.DropRequire(pm, args.ModulePath)
 		})
-		if err != nil {
-			return err
-		}
-		if !response.Applied {
-			return fmt.Errorf("edits not applied because of %s", response.FailureReason)
-		}
-		return nil
 	})
 }
 
-// dropDependency returns the edits to remove the given require from the go.mod
-// file.
-func dropDependency(pm *cache.ParsedModule, modulePath string) ([]protocol.TextEdit, error) {
-	// We need a private copy of the parsed go.mod file, since we're going to
-	// modify it.
-	copied, err := modfile.Parse("", pm.Mapper.Content, nil)
+// updateGnoMod replaces the content of the gno.mod file of the package
+// containing uri, which may be the gno.mod file itself, by the result of
+// update, through a workspace edit applied by the client.
+func (c *commandHandler) updateGnoMod(ctx context.Context, snapshot *cache.Snapshot, uri protocol.DocumentURI, update func(*cache.ParsedModule) ([]byte, error)) error {
+	modURI, err := snapshot.GnoModForFile(ctx, uri)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	if err := copied.DropRequire(modulePath); err != nil {
-		return nil, err
+	if modURI == "" {
+		return fmt.Errorf("no gno.mod file found for %s", uri.Path())
 	}
-	copied.Cleanup()
-	newContent, err := copied.Format()
+	fh, err := snapshot.ReadFile(ctx, modURI)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	// Calculate the edits to be made due to the change.
-	diff := diff.Bytes(pm.Mapper.Content, newContent)
-	return protocol.EditsFromDiffEdits(pm.Mapper, diff)
+	pm, err := snapshot.ParseMod(ctx, fh)
+	if err != nil {
+		return err
+	}
+	newContent, err := update(pm)
+	if err != nil {
+		return err
+	}
+	edits, err := protocol.EditsFromDiffEdits(pm.Mapper, diff.Bytes(pm.Mapper.Content, newContent))
+	if err != nil {
+		return err
+	}
+	if len(edits) == 0 {
+		return nil
+	}
+	return applyChanges(ctx, c.s.client, []protocol.DocumentChange{protocol.DocumentChangeEdit(fh, edits)})
 }
 
 // Test is an alias for RunTests (with splayed arguments).
@@ -713,8 +725,8 @@ func (c *commandHandler) Doc(ctx context.Context, loc protocol.Location) error {
 
 func (c *commandHandler) RunTests(ctx context.Context, args command.RunTestsArgs) error {
 	return c.run(ctx, commandConfig{
-		progress:    "Running go test", // (asynchronous)
-		requireSave: true,              // go test honors overlays, but tests themselves cannot
+		progress:    "Running gno test", // (asynchronous)
+		requireSave: true,               // tests are run against the files on disk
 		forURI:      args.URI,
 	}, func(ctx context.Context, deps commandDeps) error {
 		return c.runTests(ctx, deps.snapshot, deps.work, args.URI, args.Tests, args.Benchmarks)
@@ -728,82 +740,109 @@ func (c *commandHandler) runTests(ctx context.Context, snapshot *cache.Snapshot,
 		return err
 	}
 	pkgPath := string(meta.ForTest)
+	if pkgPath == "" {
+		pkgPath = string(meta.PkgPath)
+	}
+
+	if len(tests) == 0 {
+		if len(benchmarks) > 0 {
+			return errors.New("benchmarks are not supported by gno test")
+		}
+		return errors.New("No functions were provided")
+	}
 
 	// create output
 	buf := &bytes.Buffer{}
 	ew := progress.NewEventWriter(ctx, "test")
 	out := io.MultiWriter(ew, progress.NewWorkDoneWriter(ctx, work), buf)
 
-	// Run `go test -run Func` on each test.
-	var failedTests int
-	for _, funcName := range tests {
-		inv, cleanupInvocation, err := snapshot.GoCommandInvocation(false, &gocommand.Invocation{
-			Verb:       "test",
-			Args:       []string{pkgPath, "-v", "-count=1", fmt.Sprintf("-run=^%s$", regexp.QuoteMeta(funcName))},
-			WorkingDir: filepath.Dir(uri.Path()),
-		})
-		if err != nil {
+	// Run the equivalent of `gno test -v -run ^(Func...)$` in-process.
+	run := make([]string, len(tests))
+	for i, funcName := range tests {
+		run[i] = regexp.QuoteMeta(funcName)
+	}
+	results, err := gnotest.Run(ctx, filepath.Dir(uri.Path()), pkgPath, gnotest.Options{
+		Run:     fmt.Sprintf("^(%s)$", strings.Join(run, "|")),
+		Verbose: true,
+		Output:  out,
+	})
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
 			return err
 		}
-		defer cleanupInvocation()
-		if err := snapshot.View().GoCommandRunner().RunPiped(ctx, *inv, out, out); err != nil {
-			if errors.Is(err, context.Canceled) {
-				return err
-			}
-			failedTests++
-		}
+		showMessage(ctx, c.s.client, protocol.Error, fmt.Sprintf("running tests: %v\n%s", err, buf.String()))
+		return fmt.Errorf("running tests: %w", err)
 	}
 
-	// Run `go test -run=^$ -bench Func` on each test.
-	var failedBenchmarks int
-	for _, funcName := range benchmarks {
-		inv, cleanupInvocation, err := snapshot.GoCommandInvocation(false, &gocommand.Invocation{
-			Verb:       "test",
-			Args:       []string{pkgPath, "-v", "-run=^$", fmt.Sprintf("-bench=^%s$", regexp.QuoteMeta(funcName))},
-			WorkingDir: filepath.Dir(uri.Path()),
-		})
-		if err != nil {
-			return err
+	// Publish test failures as diagnostics on the failing test functions.
+	if err := c.modifyState(ctx, FromRunTests, func() (*cache.Snapshot, func(), error) {
+		if err := c.s.updateTestFailures(ctx, snapshot, pkgPath, results); err != nil {
+			return nil, nil, err
 		}
-		defer cleanupInvocation()
-		if err := snapshot.View().GoCommandRunner().RunPiped(ctx, *inv, out, out); err != nil {
-			if errors.Is(err, context.Canceled) {
-				return err
-			}
-			failedBenchmarks++
+		return snapshot.View().Snapshot()
+	}); err != nil {
+		return err
+	}
+
+	var failedTests int
+	for _, res := range results {
+		if res.Failed {
+			failedTests++
 		}
 	}
 
-	var title string
-	if len(tests) > 0 && len(benchmarks) > 0 {
-		title = "tests and benchmarks"
-	} else if len(tests) > 0 {
-		title = "tests"
-	} else if len(benchmarks) > 0 {
-		title = "benchmarks"
-	} else {
-		return errors.New("No functions were provided")
+	message := "all tests passed"
+	if failedTests > 0 {
+		message = fmt.Sprintf("%d / %d tests failed", failedTests, len(results))
+	} else if len(results) == 0 {
+		message = "no tests to run"
 	}
-	message := fmt.Sprintf("all %s passed", title)
-	if failedTests > 0 && failedBenchmarks > 0 {
-		message = fmt.Sprintf("%d / %d tests failed and %d / %d benchmarks failed", failedTests, len(tests), failedBenchmarks, len(benchmarks))
-	} else if failedTests > 0 {
-		message = fmt.Sprintf("%d / %d tests failed", failedTests, len(tests))
-	} else if failedBenchmarks > 0 {
-		message = fmt.Sprintf("%d / %d benchmarks failed", failedBenchmarks, len(benchmarks))
+	if len(benchmarks) > 0 {
+		message += " (benchmarks are not supported by gno test)"
 	}
-	if failedTests > 0 || failedBenchmarks > 0 {
+	if failedTests > 0 {
 		message += "\n" + buf.String()
 	}
 
 	showMessage(ctx, c.s.client, protocol.Info, message)
 
-	if failedTests > 0 || failedBenchmarks > 0 {
+	if failedTests > 0 {
 		return errors.New("gopls.test command failed")
 	}
 	return nil
 }
 
+func (c *commandHandler) UpdateFiletest(ctx context.Context, args command.URIArg) error {
+	return c.run(ctx, commandConfig{
+		progress: "Updating filetest",
+		forURI:   args.URI,
+	}, func(ctx context.Context, deps commandDeps) error {
+		if !filetest.IsFiletest(args.URI.Path()) {
+			return fmt.Errorf("%s is not a filetest", args.URI.Path())
+		}
+		content, err := deps.fh.Content()
+		if err != nil {
+			return err
+		}
+		// The filetest is run on a copy of the buffer, so unsaved
+		// changes are taken into account.
+		newContent, err := gnotest.UpdateFiletest("", args.URI.Path(), content)
+		if err != nil {
+			showMessage(ctx, c.s.client, protocol.Error, fmt.Sprintf("running filetest: %v", err))
+			return err
+		}
+		change, err := computeEditChange(ctx, deps.snapshot, args.URI, newContent)
+		if err != nil {
+			return err
+		}
+		if !change.Valid() {
+			showMessage(ctx, c.s.client, protocol.Info, "filetest is up to date")
+			return nil
+		}
+		return applyChanges(ctx, c.s.client, []protocol.DocumentChange{change})
+	})
+}
+
 func (c *commandHandler) Generate(ctx context.Context, args command.GenerateArgs) error {
 	title := "Running go generate ."
 	if args.Recursive {
@@ -840,42 +879,19 @@ func (c *commandHandler) Generate(ctx context.Context, args command.GenerateArgs
 func (c *commandHandler) GoGetPackage(ctx context.Context, args command.GoGetPackageArgs) error {
 	return c.run(ctx, commandConfig{
 		forURI:   args.URI,
-		progress: "Running go get",
+		progress: "Fetching package",
 	}, func(ctx context.Context, deps commandDeps) error {
-		snapshot := deps.snapshot
-		modURI := snapshot.GoModForFile(args.URI)
-		if modURI == "" {
-			return fmt.Errorf("no go.mod file found for %s", args.URI)
-		}
-		tempDir, cleanupModDir, err := cache.TempModDir(ctx, snapshot, modURI)
-		if err != nil {
-			return fmt.Errorf("creating a temp go.mod: %v", err)
-		}
-		defer cleanupModDir()
-
-		inv, cleanupInvocation, err := snapshot.GoCommandInvocation(true, &gocommand.Invocation{
-			Verb:       "list",
-			Args:       []string{"-f", "{{.Module.Path}}@{{.Module.Version}}", "-mod=mod", "-modfile=" + filepath.Join(tempDir, "go.mod"), args.Pkg},
-			Env:        []string{"GOWORK=off"},
-			WorkingDir: modURI.Dir().Path(),
-		})
+		remote := deps.snapshot.Options().PackageSource
+		dir, err := mod.Get(ctx, deps.snapshot.View().ResolverConfig(), remote, args.Pkg)
 		if err != nil {
 			return err
 		}
-		defer cleanupInvocation()
-		stdout, err := snapshot.View().GoCommandRunner().Run(ctx, *inv)
-		if err != nil {
-			return err
+		event.Log(ctx, fmt.Sprintf("fetched %s to %s", args.Pkg, dir))
+		if !args.AddRequire {
+			return nil
 		}
-		ver := strings.TrimSpace(stdout.String())
-		return c.s.runGoModUpdateCommands(ctx, snapshot, args.URI, func(invoke func(...string) (*bytes.Buffer, error)) error {
-			if args.AddRequire {
-				if err := addModuleRequire(invoke, []string{ver}); err != nil {
-					return err
-				}
-			}
-			_, err := invoke(append([]string{"get", "-d"}, args.Pkg)...)
-			return err
+		return c.updateGnoMod(ctx, deps.snapshot, args.URI, func(pm *cache.ParsedModule) ([]byte, error) {
+			return mod.AddRequires(pm, []string{args.Pkg})
 		})
 	})
 }
@@ -1366,6 +1382,7 @@ func collectViewStats(ctx context.Context, view *cache.View) (command.ViewStats,
 		AllPackages:       allPackages,
 		WorkspacePackages: workspacePackages,
 		Diagnostics:       ndiags,
+		Roots:             collectRootStats(view, allMD, wsMD),
 	}, nil
 }
 
@@ -1613,11 +1630,63 @@ func (c *commandHandler) Views(ctx context.Context) ([]command.View, error) {
 			Root:       view.Root(),
 			Folder:     view.Folder().Dir,
 			EnvOverlay: view.EnvOverlay(),
+			Roots:      viewRoots(view),
 		})
 	}
 	return summaries, nil
 }
 
+// workspaceRoot is the kind of the root of the workspace packages.
+const workspaceRoot = "workspace"
+
+// viewRoots returns the package roots of the view, in the order imports
+// are resolved against them: the workspace first, then the roots of its
+// resolver.
+func viewRoots(view *cache.View) []command.PackageRoot {
+	roots := []command.PackageRoot{{Kind: workspaceRoot, Dir: view.Root()}}
+	for _, root := range view.ResolverConfig().Roots() {
+		roots = append(roots, command.PackageRoot{Kind: root.Kind, Dir: protocol.URIFromPath(root.Dir)})
+	}
+	return roots
+}
+
+// collectRootStats returns the packages of each package root of the view,
+// given all its packages and its workspace packages.
+func collectRootStats(view *cache.View, allMD, wsMD []*metadata.Package) []command.RootStats {
+	workspace := make(map[golang.PackageID]bool)
+	for _, mp := range wsMD {
+		workspace[mp.ID] = true
+	}
+	rc := view.ResolverConfig()
+	roots := viewRoots(view)
+	paths := make([]map[string]bool, len(roots))
+	for _, mp := range allMD {
+		if len(mp.CompiledGoFiles) == 0 {
+			continue
+		}
+		var root command.PackageRoot
+		if workspace[mp.ID] {
+			root = roots[0]
+		} else if r, ok := rc.RootOf(mp.CompiledGoFiles[0].Dir().Path()); ok {
+			root = command.PackageRoot{Kind: r.Kind, Dir: protocol.URIFromPath(r.Dir)}
+		} else {
+			continue // neither in the workspace nor in a root
+		}
+		i := slices.Index(roots, root)
+		if paths[i] == nil {
+			paths[i] = make(map[string]bool)
+		}
+		paths[i][string(mp.PkgPath)] = true
+	}
+
+	var stats []command.RootStats
+	for i, root := range roots {
+		pkgs := slices.Sorted(maps.Keys(paths[i]))
+		stats = append(stats, command.RootStats{PackageRoot: root, Packages: pkgs})
+	}
+	return stats
+}
+
 func (c *commandHandler) FreeSymbols(ctx context.Context, viewID string, loc protocol.Location) error {
 	web, err := c.s.getWeb()
 	if err != nil {
@@ -1638,6 +1707,88 @@ func (c *commandHandler) Assembly(ctx context.Context, viewID, packageID, symbol
 	return nil
 }
 
+func (c *commandHandler) RenderPreview(ctx context.Context, viewID, packageID string) error {
+	web, err := c.s.getWeb()
+	if err != nil {
+		return err
+	}
+	url := web.renderURL(viewID, packageID)
+	openClientBrowser(ctx, c.s.client, url)
+	return nil
+}
+
+func (c *commandHandler) RealmState(ctx context.Context, viewID, packageID string) error {
+	web, err := c.s.getWeb()
+	if err != nil {
+		return err
+	}
+	url := web.realmStateURL(viewID, packageID)
+	openClientBrowser(ctx, c.s.client, url)
+	return nil
+}
+
+func (c *commandHandler) Estimate(ctx context.Context, args command.EstimateArgs) error {
+	return c.run(ctx, commandConfig{
+		progress: "Estimating " + args.Function,
+		forURI:   args.URI,
+	}, func(ctx context.Context, deps commandDeps) error {
+		mp, err := golang.NarrowestMetadataForFile(ctx, deps.snapshot, args.URI)
+		if err != nil {
+			return err
+		}
+		if !metadata.IsRealmPath(mp.PkgPath) {
+			return fmt.Errorf("%s is not a realm", mp.PkgPath)
+		}
+		msg, regressed, err := golang.Estimate(ctx, deps.snapshot, mp, args.Function, args.Args)
+		if err != nil {
+			showMessage(ctx, c.s.client, protocol.Error, fmt.Sprintf("estimating %s: %v", args.Function, err))
+			return err
+		}
+		typ := protocol.Info
+		if regressed {
+			typ = protocol.Warning
+		}
+		showMessage(ctx, c.s.client, typ, msg)
+		return nil
+	})
+}
+
+func (c *commandHandler) MakeTx(ctx context.Context, args command.MakeTxArgs) error {
+	return c.run(ctx, commandConfig{
+		forURI: args.URI,
+	}, func(ctx context.Context, deps commandDeps) error {
+		if c.s.tempDir == "" {
+			return errors.New("no temporary directory to write the transaction to")
+		}
+		pkg, _, err := golang.NarrowestPackageForFile(ctx, deps.snapshot, args.URI)
+		if err != nil {
+			return err
+		}
+		fn, ok := pkg.Types().Scope().Lookup(args.Function).(*types.Func)
+		if !ok {
+			return fmt.Errorf("no function %s in %s", args.Function, pkg.Metadata().PkgPath)
+		}
+
+		// e.g. $TMPDIR/gopls-123.1/maketx/gno.land/r/demo/foo/Transfer.sh
+		dir := filepath.Join(c.s.tempDir, "maketx", filepath.FromSlash(string(pkg.Metadata().PkgPath)))
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return err
+		}
+		base := filepath.Join(dir, args.Function)
+		opts := deps.snapshot.Options()
+		content, ext, err := golang.MakeTx(pkg.Types(), fn, args.Kind, opts.MakeTxRemote, opts.MakeTxChainID, base+".gno")
+		if err != nil {
+			return err
+		}
+		filename := base + ext
+		if err := os.WriteFile(filename, content, 0600); err != nil {
+			return err
+		}
+		openClientEditor(ctx, c.s.client, protocol.Location{URI: protocol.URIFromPath(filename)})
+		return nil
+	})
+}
+
 func (c *commandHandler) ClientOpenURL(ctx context.Context, url string) error {
 	openClientBrowser(ctx, c.s.client, url)
 	return nil
@@ -20,6 +20,7 @@ import (
 	"github.com/gfanton/gnopls/internal/cache"
 	"github.com/gfanton/gnopls/internal/cache/metadata"
 	"github.com/gfanton/gnopls/internal/file"
+	"github.com/gfanton/gnopls/internal/gnotest"
 	"github.com/gfanton/gnopls/internal/golang"
 	"github.com/gfanton/gnopls/internal/label"
 	"github.com/gfanton/gnopls/internal/mod"
@@ -409,6 +410,13 @@ func (s *server) diagnose(ctx context.Context, snapshot *cache.Snapshot) (diagMa
 	// (but this will be gone soon anyway).
 	store("diagnosing templates", tmplReports, nil)
 
+	// Diagnose failures reported by the last test runs.
+	testReports, testErr := s.testFailureDiagnostics(ctx, snapshot)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	store("collecting test failures", testReports, testErr)
+
 	// If there are no workspace packages, there is nothing to diagnose and
 	// there are no orphaned files.
 	if len(workspacePkgs) == 0 {
@@ -563,6 +571,117 @@ func (s *server) gcDetailsDiagnostics(ctx context.Context, snapshot *cache.Snaps
 	return diagnostics, nil
 }
 
+// A testKey identifies a test function: the in-package and the external
+// test packages of a package may declare tests of the same name.
+type testKey struct {
+	pkg  metadata.PackageID
+	name string
+}
+
+// A testFailure records the failure of a test function, as reported by
+// the last run of the test.
+type testFailure struct {
+	hash file.Hash         // content of the test file when the test was run
+	diag *cache.Diagnostic // diagnostic on the test function
+}
+
+// updateTestFailures records the results of a run of the tests of the
+// package pkgPath, replacing the results of previous runs of the same
+// tests.
+func (s *server) updateTestFailures(ctx context.Context, snapshot *cache.Snapshot, pkgPath string, results []gnotest.Result) error {
+	// Locate the test functions in the test packages of pkgPath.
+	workspace, err := snapshot.WorkspaceMetadata(ctx)
+	if err != nil {
+		return err
+	}
+	var ids []metadata.PackageID
+	testPkgs := make(map[metadata.PackagePath]metadata.PackageID)
+	for _, mp := range workspace {
+		if string(mp.ForTest) == pkgPath {
+			ids = append(ids, mp.ID)
+			testPkgs[mp.PkgPath] = mp.ID
+		}
+	}
+	indexes, err := snapshot.Tests(ctx, ids...)
+	if err != nil {
+		return err
+	}
+	locs := make(map[testKey]protocol.Location)
+	for i, index := range indexes {
+		for _, test := range index.All() {
+			locs[testKey{ids[i], test.Name}] = test.Location
+		}
+	}
+
+	s.testFailuresMu.Lock()
+	defer s.testFailuresMu.Unlock()
+
+	if s.testFailures == nil {
+		s.testFailures = make(map[protocol.DocumentURI]map[testKey]testFailure)
+	}
+	for _, res := range results {
+		key := testKey{testPkgs[metadata.PackagePath(res.PkgPath)], res.Name}
+		loc, ok := locs[key]
+		if !ok {
+			continue
+		}
+		failures := s.testFailures[loc.URI]
+		delete(failures, key)
+		if !res.Failed {
+			continue
+		}
+
+		fh, err := snapshot.ReadFile(ctx, loc.URI)
+		if err != nil {
+			return err
+		}
+		if failures == nil {
+			failures = make(map[testKey]testFailure)
+			s.testFailures[loc.URI] = failures
+		}
+		// Report the failure on the "func TestXxx" part of the declaration.
+		rng := loc.Range
+		rng.End = rng.Start
+		rng.End.Character += uint32(len("func " + res.Name))
+		failures[key] = testFailure{
+			hash: fh.Identity().Hash,
+			diag: &cache.Diagnostic{
+				URI:      loc.URI,
+				Range:    rng,
+				Severity: protocol.SeverityError,
+				Source:   cache.TestFailure,
+				Message:  fmt.Sprintf("%s failed\n%s", res.Name, strings.TrimSpace(res.Output)),
+			},
+		}
+	}
+	return nil
+}
+
+// testFailureDiagnostics returns the diagnostics of the test failures
+// recorded by updateTestFailures, omitting those of the files that
+// changed since the tests were run.
+func (s *server) testFailureDiagnostics(ctx context.Context, snapshot *cache.Snapshot) (diagMap, error) {
+	s.testFailuresMu.Lock()
+	defer s.testFailuresMu.Unlock()
+
+	diagnostics := make(diagMap)
+	for uri, failures := range s.testFailures {
+		if len(failures) == 0 {
+			continue
+		}
+		fh, err := snapshot.ReadFile(ctx, uri)
+		if err != nil {
+			return nil, err
+		}
+		for _, failure := range failures {
+			if failure.hash == fh.Identity().Hash {
+				diagnostics[uri] = append(diagnostics[uri], failure.diag)
+			}
+		}
+	}
+	return diagnostics, nil
+}
+
 // combineDiagnostics combines and filters list/parse/type diagnostics from
 // tdiags with adiags, and appends the two lists to *outT and *outA,
 // respectively.
@@ -0,0 +1,212 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gfanton/gnopls/internal/cache"
+	"github.com/gfanton/gnopls/internal/file"
+	"github.com/gfanton/gnopls/internal/gnotest"
+	"github.com/gfanton/gnopls/internal/protocol"
+	"github.com/gfanton/gnopls/internal/settings"
+)
+
+const fooTestSrc = `package foo
+
+import "testing"
+
+func TestPass(t *testing.T) {
+	if Foo() != 1 {
+		t.Errorf("Foo() = %d, want 1", Foo())
+	}
+}
+
+func TestFail(t *testing.T) {
+	if Foo() != 2 {
+		t.Errorf("Foo() = %d, want 2", Foo())
+	}
+}
+`
+
+// fooXTestSrc declares tests of the same names as fooTestSrc in the
+// external test package.
+const fooXTestSrc = `package foo_test
+
+import "testing"
+
+func TestFail(t *testing.T) {}
+`
+
+// testFailuresSession returns a session of a gno.work with the package
+// gno.land/p/demo/foo, whose in-package test TestPass passes and TestFail
+// fails, and whose external test TestFail passes, and the directory of
+// the package.
+func testFailuresSession(t *testing.T) (*cache.Session, string) {
+	t.Helper()
+	ctx := context.Background()
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"gno.work":           "go 1.22\n\nuse ./foo\n",
+		"foo/gno.mod":        "module gno.land/p/demo/foo\n",
+		"foo/foo.gno":        "package foo\n\nfunc Foo() int { return 1 }\n",
+		"foo/foo_test.gno":   fooTestSrc,
+		"foo/foo_x_test.gno": fooXTestSrc,
+		// The view resolves the testing package of a Gno root of its own,
+		// whatever the Gno root the tests are run with.
+		"gno/gnovm/stdlibs/testing/testing.gno": "package testing\n\ntype T struct{}\n\nfunc (t *T) Errorf(format string, args ...any) {}\n",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	opts := settings.DefaultOptions()
+	opts.GnoRoot = filepath.Join(dir, "gno")
+	opts.GnoModCache = filepath.Join(dir, "modcache")
+
+	session := cache.NewSession(ctx, cache.New(nil))
+	t.Cleanup(func() { session.Shutdown(ctx) })
+	_, _, release, err := session.NewView(ctx, &cache.Folder{
+		Dir:     protocol.URIFromPath(dir),
+		Name:    "tests",
+		Options: opts,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+	return session, filepath.Join(dir, "foo")
+}
+
+// testFailures records the results of a run of the tests of foo, and
+// returns the messages of the published diagnostics of its test file
+// name, all of which must be on the line of TestFail.
+func testFailures(t *testing.T, s *server, session *cache.Session, dir, name string, results []gnotest.Result) []string {
+	t.Helper()
+	ctx := context.Background()
+	uri := protocol.URIFromPath(filepath.Join(dir, name))
+	snapshot, release, err := session.SnapshotOf(ctx, uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+	if err := s.updateTestFailures(ctx, snapshot, "gno.land/p/demo/foo", results); err != nil {
+		t.Fatal(err)
+	}
+	diags, err := s.testFailureDiagnostics(ctx, snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := os.ReadFile(uri.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	line := strings.Count(string(src[:strings.Index(string(src), "func TestFail")]), "\n")
+	var msgs []string
+	for _, diag := range diags[uri] {
+		if diag.Source != cache.TestFailure || int(diag.Range.Start.Line) != line {
+			t.Errorf("diagnostic %q from %s at %s:%d, want %s at line %d", diag.Message, diag.Source, name, diag.Range.Start.Line+1, cache.TestFailure, line+1)
+		}
+		msgs = append(msgs, diag.Message)
+	}
+	return msgs
+}
+
+// TestRunTestFailures runs the tests of a package with the gno test
+// runner, and checks the diagnostics of the failing test. It needs the
+// standard libraries of the Gno root directory $GNOROOT.
+func TestRunTestFailures(t *testing.T) {
+	rootDir := os.Getenv("GNOROOT")
+	if _, err := os.Stat(filepath.Join(rootDir, "gnovm", "stdlibs", "testing")); rootDir == "" || err != nil {
+		t.Skip("no Gno standard libraries in $GNOROOT")
+	}
+	session, dir := testFailuresSession(t)
+	results, err := gnotest.Run(context.Background(), dir, "gno.land/p/demo/foo", gnotest.Options{RootDir: rootDir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	failed := make(map[string]bool)
+	for _, res := range results {
+		failed[res.PkgPath+"."+res.Name] = res.Failed
+	}
+	if len(results) != 3 || failed["gno.land/p/demo/foo.TestPass"] || !failed["gno.land/p/demo/foo.TestFail"] || failed["gno.land/p/demo/foo_test.TestFail"] {
+		t.Fatalf("Run() = %+v, want only the in-package TestFail failing", results)
+	}
+
+	msgs := testFailures(t, new(server), session, dir, "foo_test.gno", results)
+	if len(msgs) != 1 || !strings.HasPrefix(msgs[0], "TestFail failed") || !strings.Contains(msgs[0], "Foo() = 1, want 2") {
+		t.Errorf("test failure diagnostics = %q, want the output of TestFail", msgs)
+	}
+}
+
+func TestTestFailureDiagnostics(t *testing.T) {
+	ctx := context.Background()
+	session, dir := testFailuresSession(t)
+	s := new(server)
+	const (
+		pkgPath  = "gno.land/p/demo/foo"
+		xpkgPath = "gno.land/p/demo/foo_test"
+	)
+
+	// A failing test is reported, a passing one is not.
+	msgs := testFailures(t, s, session, dir, "foo_test.gno", []gnotest.Result{
+		{Name: "TestPass", PkgPath: pkgPath},
+		{Name: "TestFail", PkgPath: pkgPath, Failed: true, Output: "Foo() = 1, want 2\n"},
+		{Name: "TestFail", PkgPath: xpkgPath},
+	})
+	if want := "TestFail failed\nFoo() = 1, want 2"; len(msgs) != 1 || msgs[0] != want {
+		t.Errorf("test failure diagnostics = %q, want %q", msgs, want)
+	}
+	if msgs := testFailures(t, s, session, dir, "foo_x_test.gno", nil); len(msgs) != 0 {
+		t.Errorf("test failure diagnostics of the external test = %q, want none", msgs)
+	}
+
+	// A run of another test keeps the failure, even of the same name in
+	// the external test package.
+	msgs = testFailures(t, s, session, dir, "foo_test.gno", []gnotest.Result{
+		{Name: "TestPass", PkgPath: pkgPath},
+		{Name: "TestFail", PkgPath: xpkgPath, Failed: true, Output: "external\n"},
+	})
+	if len(msgs) != 1 {
+		t.Errorf("test failure diagnostics after another run = %q, want that of TestFail", msgs)
+	}
+	if msgs, want := testFailures(t, s, session, dir, "foo_x_test.gno", nil), "TestFail failed\nexternal"; len(msgs) != 1 || msgs[0] != want {
+		t.Errorf("test failure diagnostics of the external test = %q, want %q", msgs, want)
+	}
+
+	// A change of the test file hides it.
+	uri := protocol.URIFromPath(filepath.Join(dir, "foo_test.gno"))
+	if _, err := session.DidModifyFiles(ctx, []file.Modification{{
+		URI:        uri,
+		Action:     file.Open,
+		Version:    1,
+		Text:       []byte(fooTestSrc + "\n// changed\n"),
+		LanguageID: "gno",
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	snapshot, release, err := session.SnapshotOf(ctx, uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	diags, err := s.testFailureDiagnostics(ctx, snapshot)
+	release()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diags[uri]) != 0 {
+		t.Errorf("test failure diagnostics of a changed file = %v, want none", diags[uri])
+	}
+
+	// A passing run of the test clears it.
+	msgs = testFailures(t, s, session, dir, "foo_test.gno", []gnotest.Result{{Name: "TestFail", PkgPath: pkgPath}})
+	if len(msgs) != 0 {
+		t.Errorf("test failure diagnostics after a passing run = %q, want none", msgs)
+	}
+}
@@ -75,7 +75,12 @@ func modLinks(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle) ([]
 		}
 		// Shift the start position to the location of the
 		// dependency within the require statement.
-		target := cache.BuildLink(snapshot.Options().LinkTarget, "mod/"+req.Mod.String(), "")
+		var target protocol.URI
+		if opts := snapshot.Options(); !opts.GnowebLinks {
+			target = cache.BuildLink(opts.LinkTarget, "mod/"+req.Mod.String(), "")
+		} else {
+			target = cache.DocLink(true, opts.LinkTarget, req.Mod.Path, "", cache.RealmRender)
+		}
 		l, err := toProtocolLink(pm.Mapper, target, start+i, start+i+len(dep))
 		if err != nil {
 			return nil, err
@@ -120,10 +125,11 @@ func goLinks(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle) ([]p
 	// Create links for import specs.
 	if snapshot.Options().ImportShortcut.ShowLinks() {
 
-		// If links are to pkg.go.dev, append module version suffixes.
-		// This requires the import map from the package metadata. Ignore errors.
+		// If links are to a Go documentation host such as pkg.go.dev, append
+		// module version suffixes. This requires the import map from the
+		// package metadata. Ignore errors.
 		var depsByImpPath map[golang.ImportPath]golang.PackageID
-		if strings.ToLower(snapshot.Options().LinkTarget) == "pkg.go.dev" {
+		if !snapshot.Options().GnowebLinks {
 			if meta, err := golang.NarrowestMetadataForFile(ctx, snapshot, fh.URI()); err == nil {
 				depsByImpPath = meta.DepsByImpPath
 			}
@@ -150,7 +156,7 @@ func goLinks(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle) ([]p
 			if err != nil {
 				return nil, err
 			}
-			targetURL := cache.BuildLink(snapshot.Options().LinkTarget, urlPath, "")
+			targetURL := cache.DocLink(snapshot.Options().GnowebLinks, snapshot.Options().LinkTarget, urlPath, "", cache.RealmRender)
 			// Account for the quotation marks in the positions.
 			l, err := toProtocolLink(pgf.Mapper, targetURL, start+len(`"`), end-len(`"`))
 			if err != nil {
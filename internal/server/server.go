@@ -24,6 +24,7 @@ import (
 
 	"github.com/gfanton/gnopls/internal/cache"
 	"github.com/gfanton/gnopls/internal/cache/metadata"
+	"github.com/gfanton/gnopls/internal/gnostate"
 	"github.com/gfanton/gnopls/internal/golang"
 	"github.com/gfanton/gnopls/internal/progress"
 	"github.com/gfanton/gnopls/internal/protocol"
@@ -112,6 +113,11 @@ type server struct {
 	// expensive.
 	diagnosticsSema chan unit
 
+	// testFailures records the failures reported by the last run of each
+	// test function, by file and test. See updateTestFailures.
+	testFailuresMu sync.Mutex
+	testFailures   map[protocol.DocumentURI]map[testKey]testFailure
+
 	progress *progress.Tracker
 
 	// When the workspace fails to load, we show its status through a progress
@@ -141,6 +147,12 @@ type server struct {
 	web     *web
 	webErr  error
 
+	// saved is closed, and replaced, each time a file is saved.
+	// Web pages that depend on the source, such as the Render
+	// preview, wait on it to refresh themselves.
+	savedMu sync.Mutex
+	saved   chan unit
+
 	// # Modification tracking and diagnostics
 	//
 	// For the purpose of tracking diagnostics, we need a monotonically
@@ -207,6 +219,8 @@ func (s *server) WorkDoneProgressCancel(ctx context.Context, params *protocol.Wo
 //	pkg/PKGPATH?view=%s               - show doc for package in a given view
 //	assembly?pkg=%s&view=%s&symbol=%s - show assembly of specified func symbol
 //	freesymbols?file=%s&range=%d:%d:%d:%d:&view=%s - show report of free symbols
+//	render?view=%s&pkg=%s&path=%s     - show Render output of a realm
+//	render/wait                       - wait until a file is saved
 type web struct {
 	server *http.Server
 	addr   url.URL // "http://127.0.0.1:PORT/gopls/SECRET"
@@ -455,9 +469,135 @@ func (s *server) initWeb() (*web, error) {
 		w.Write(html)
 	})
 
+	// The /render?view=...&pkg=...&path=... handler shows the
+	// output of the Render function of a realm.
+	webMux.HandleFunc("/render", func(w http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+		if err := req.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// Get parameters.
+		var (
+			viewID = req.Form.Get("view")
+			pkgID  = metadata.PackageID(req.Form.Get("pkg"))
+			path   = req.Form.Get("path")
+		)
+		if viewID == "" || pkgID == "" {
+			http.Error(w, "/render requires view, pkg", http.StatusBadRequest)
+			return
+		}
+
+		// Get snapshot of specified view.
+		view, err := s.session.View(viewID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		snapshot, release, err := view.Snapshot()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer release()
+
+		mp := snapshot.Metadata(pkgID)
+		if mp == nil {
+			http.Error(w, "package not found", http.StatusNotFound)
+			return
+		}
+
+		// Produce report.
+		html, err := golang.RenderHTML(ctx, snapshot, viewID, mp, path)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Write(html)
+	})
+
+	// The /realmstate?view=...&pkg=... handler shows the values of
+	// the package-level variables of a realm.
+	webMux.HandleFunc("/realmstate", func(w http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+		if err := req.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// Get parameters.
+		var (
+			viewID = req.Form.Get("view")
+			pkgID  = metadata.PackageID(req.Form.Get("pkg"))
+		)
+		if viewID == "" || pkgID == "" {
+			http.Error(w, "/realmstate requires view, pkg", http.StatusBadRequest)
+			return
+		}
+
+		// Get snapshot of specified view.
+		view, err := s.session.View(viewID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		snapshot, release, err := view.Snapshot()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer release()
+
+		pkgs, err := snapshot.TypeCheck(ctx, pkgID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		// Produce report.
+		src := gnostate.Source(snapshot.Options().RealmState)
+		html, err := golang.RealmStateHTML(ctx, viewID, pkgs[0], src, web)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Write(html)
+	})
+
+	// The /render/wait handler hangs until a file is saved, or the
+	// request is cancelled. It is used by JS to refresh the Render
+	// preview.
+	webMux.HandleFunc("/render/wait", func(w http.ResponseWriter, req *http.Request) {
+		select {
+		case <-s.savedChan():
+		case <-req.Context().Done():
+		}
+	})
+
 	return web, nil
 }
 
+// savedChan returns a channel that is closed when a file is next saved.
+func (s *server) savedChan() <-chan unit {
+	s.savedMu.Lock()
+	defer s.savedMu.Unlock()
+	if s.saved == nil {
+		s.saved = make(chan unit)
+	}
+	return s.saved
+}
+
+// notifySaved wakes up the waiters of savedChan.
+func (s *server) notifySaved() {
+	s.savedMu.Lock()
+	defer s.savedMu.Unlock()
+	if s.saved != nil {
+		close(s.saved)
+		s.saved = nil
+	}
+}
+
 // assets holds our static web server content.
 //
 //go:embed assets/*
@@ -512,6 +652,28 @@ func (w *web) assemblyURL(viewID, packageID, symbol string) protocol.URI {
 		"")
 }
 
+// renderURL returns the URL of the Render preview of the specified
+// realm package.
+func (w *web) renderURL(viewID, packageID string) protocol.URI {
+	return w.url(
+		"render",
+		fmt.Sprintf("view=%s&pkg=%s",
+			url.QueryEscape(viewID),
+			url.QueryEscape(packageID)),
+		"")
+}
+
+// realmStateURL returns the URL of the state page of the specified
+// realm package.
+func (w *web) realmStateURL(viewID, packageID string) protocol.URI {
+	return w.url(
+		"realmstate",
+		fmt.Sprintf("view=%s&pkg=%s",
+			url.QueryEscape(viewID),
+			url.QueryEscape(packageID)),
+		"")
+}
+
 // url returns a URL by joining a relative path, an (encoded) query,
 // and an (unencoded) fragment onto the authenticated base URL of the
 // web server.
@@ -64,6 +64,10 @@ const (
 	// FromToggleGCDetails refers to state changes resulting from toggling
 	// gc_details on or off for a package.
 	FromToggleGCDetails
+
+	// FromRunTests refers to state changes resulting from running tests,
+	// which report test failures as diagnostics.
+	FromRunTests
 )
 
 func (m ModificationSource) String() string {
@@ -86,6 +90,8 @@ func (m ModificationSource) String() string {
 		return "from check upgrades"
 	case FromResetGoModDiagnostics:
 		return "from resetting go.mod diagnostics"
+	case FromRunTests:
+		return "from running tests"
 	default:
 		return "unknown file modification"
 	}
@@ -200,7 +206,11 @@ func (s *server) DidSave(ctx context.Context, params *protocol.DidSaveTextDocume
 	if params.Text != nil {
 		c.Text = []byte(*params.Text)
 	}
-	return s.didModifyFiles(ctx, []file.Modification{c}, FromDidSave)
+	if err := s.didModifyFiles(ctx, []file.Modification{c}, FromDidSave); err != nil {
+		return err
+	}
+	s.notifySaved()
+	return nil
 }
 
 func (s *server) DidClose(ctx context.Context, params *protocol.DidCloseTextDocumentParams) error {
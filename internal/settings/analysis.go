@@ -45,8 +45,11 @@ import (
 	"golang.org/x/tools/go/analysis/passes/unsafeptr"
 	"golang.org/x/tools/go/analysis/passes/unusedresult"
 	"golang.org/x/tools/go/analysis/passes/unusedwrite"
+	"github.com/gfanton/gnopls/internal/analysis/crossrealm"
+	"github.com/gfanton/gnopls/internal/analysis/renderroutes"
 	"github.com/gfanton/gnopls/internal/analysis/deprecated"
 	"github.com/gfanton/gnopls/internal/analysis/embeddirective"
+	"github.com/gfanton/gnopls/internal/analysis/filetestdirective"
 	"github.com/gfanton/gnopls/internal/analysis/fillreturns"
 	"github.com/gfanton/gnopls/internal/analysis/infertypeargs"
 	"github.com/gfanton/gnopls/internal/analysis/nonewvars"
@@ -181,6 +184,9 @@ func init() {
 		{analyzer: nilness.Analyzer, enabled: true}, // uses go/ssa
 		{analyzer: sortslice.Analyzer, enabled: true},
 		{analyzer: embeddirective.Analyzer, enabled: true},
+		{analyzer: filetestdirective.Analyzer, enabled: true},
+		{analyzer: crossrealm.Analyzer, enabled: true},
+		{analyzer: renderroutes.Analyzer, enabled: true},
 
 		// disabled due to high false positives
 		{analyzer: shadow.Analyzer, enabled: false}, // very noisy
@@ -68,10 +68,11 @@ import "github.com/gfanton/gnopls/internal/protocol"
 // is not VS Code's default behavior; see editor.codeActionsOnSave.)
 const (
 	// source
-	GoAssembly    protocol.CodeActionKind = "source.assembly"
-	GoDoc         protocol.CodeActionKind = "source.doc"
-	GoFreeSymbols protocol.CodeActionKind = "source.freesymbols"
-	GoTest        protocol.CodeActionKind = "source.test"
+	GoAssembly       protocol.CodeActionKind = "source.assembly"
+	GoDoc            protocol.CodeActionKind = "source.doc"
+	GoFreeSymbols    protocol.CodeActionKind = "source.freesymbols"
+	GoTest           protocol.CodeActionKind = "source.test"
+	GoUpdateFiletest protocol.CodeActionKind = "source.updateFiletest"
 
 	// gopls
 	GoplsDocFeatures protocol.CodeActionKind = "gopls.doc.features"
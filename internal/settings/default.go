@@ -52,6 +52,7 @@ func DefaultOptions(overrides ...func(*Options)) *Options {
 						GoAssembly:                       false,
 						GoDoc:                            true,
 						GoFreeSymbols:                    false,
+						GoUpdateFiletest:                 true,
 						GoplsDocFeatures:                 true,
 						RefactorRewriteChangeQuote:       true,
 						RefactorRewriteFillStruct:        true,
@@ -100,7 +101,8 @@ func DefaultOptions(overrides ...func(*Options)) *Options {
 					InlayHintOptions: InlayHintOptions{},
 					DocumentationOptions: DocumentationOptions{
 						HoverKind:    FullDocumentation,
-						LinkTarget:   "pkg.go.dev",
+						LinkTarget:   "gno.land",
+						GnowebLinks:  true,
 						LinksInHover: LinksInHover_LinkTarget,
 					},
 					NavigationOptions: NavigationOptions{
@@ -118,6 +120,10 @@ func DefaultOptions(overrides ...func(*Options)) *Options {
 					Codelenses: map[CodeLensSource]bool{
 						CodeLensGenerate:          true,
 						CodeLensRegenerateCgo:     true,
+						CodeLensRender:            true,
+						CodeLensRealmState:        false,
+						CodeLensEstimate:          false,
+						CodeLensMakeTx:            false,
 						CodeLensTidy:              true,
 						CodeLensGCDetails:         false,
 						CodeLensUpgradeDependency: true,
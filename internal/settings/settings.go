@@ -5,10 +5,13 @@
 package settings
 
 import (
+	"errors"
 	"fmt"
 	"maps"
 	"path/filepath"
+	"slices"
 	"strings"
+	"text/template"
 	"time"
 
 	"github.com/gfanton/gnopls/internal/file"
@@ -159,6 +162,63 @@ type BuildOptions struct {
 	//
 	// This setting is only supported when gopls is built with Go 1.16 or later.
 	StandaloneTags []string
+
+	// PackageSource is the RPC address of a gno.land node, such as
+	// `https://rpc.gno.land:443`, from which the `gopls.go_get_package`
+	// command downloads the packages that are neither in the package roots
+	// nor among the examples of the Gno root directory.
+	PackageSource string `status:"experimental"`
+
+	// MakeTxRemote is the RPC address of the gno.land node to which the
+	// transactions generated by the `maketx` code lens are sent. If
+	// empty, it is that of a local gnodev node, `127.0.0.1:26657`.
+	MakeTxRemote string `status:"experimental"`
+
+	// MakeTxChainID is the chain ID of the transactions generated by the
+	// `maketx` code lens, that of the `makeTxRemote` node. If empty, it
+	// is that of a local gnodev node, `dev`.
+	MakeTxChainID string `status:"experimental"`
+
+	// GnoRoot is the root directory of the Gno repository, whose standard
+	// libraries and examples imports are resolved against. If empty, it
+	// is the GNOROOT environment variable, or guessed from the location
+	// of the gno installation.
+	GnoRoot string `status:"experimental"`
+
+	// PackageRoots are additional directories of Gno packages, such as the
+	// examples of another Gno repository. Imports are resolved against
+	// the packages of the workspace first, then against those of each
+	// package root, in order, then against the examples of the Gno root,
+	// and last against the packages downloaded to the module cache.
+	PackageRoots []string `status:"experimental"`
+
+	// GnoModCache is the directory of the packages downloaded by
+	// `gno mod download` and the `gopls.go_get_package` command. If
+	// empty, it is the `pkg/mod` directory of GNOHOME.
+	GnoModCache string `status:"experimental"`
+
+	// RealmState is where the values of the package-level variables of
+	// realms are read from, to show them in hovers and in the realm state
+	// page: either the RPC address of a gno.land node, such as
+	// `http://127.0.0.1:26657`, whose `vm/qeval` query evaluates them, or
+	// the path of a JSON state file mapping the path of each realm to an
+	// object mapping the names of its variables to their values. If
+	// empty, no values are shown.
+	RealmState string `status:"experimental"`
+
+	// EstimateCaller is the bech32 address of the caller of the calls
+	// executed by the `estimate` code lens. If empty, it is the caller of
+	// the test context of the gnovm.
+	EstimateCaller string `status:"experimental"`
+
+	// EstimateSend are the coins sent to the realm with the calls
+	// executed by the `estimate` code lens, such as `1000000ugnot`.
+	EstimateSend string `status:"experimental"`
+
+	// EstimateHeight is the block height of the calls executed by the
+	// `estimate` code lens. If zero, it is that of the test context of
+	// the gnovm.
+	EstimateHeight int64 `status:"experimental"`
 }
 
 // Note: UIOptions must be comparable with reflect.DeepEqual.
@@ -253,6 +313,52 @@ const (
 	// the import, or in C header files included by it.
 	CodeLensRegenerateCgo CodeLensSource = "regenerate_cgo"
 
+	// Preview the Render output of a realm
+	//
+	// This codelens source annotates the `Render` function of a
+	// realm with a command to open its output in a browser, as
+	// [gnoweb](https://gno.land) would display it.
+	//
+	// The realm is executed in an in-memory gnovm, from the current
+	// editor buffers, and the page is refreshed each time a file is
+	// saved.
+	CodeLensRender CodeLensSource = "render"
+
+	// Inspect the state of a realm
+	//
+	// This codelens source annotates the package clause of a realm
+	// with a command to open, in a browser, the values its
+	// package-level variables have on chain, as read from the
+	// `realmState` source.
+	CodeLensRealmState CodeLensSource = "realm_state"
+
+	// Estimate the costs of calling a realm function
+	//
+	// This codelens source annotates each exported function of a
+	// realm with a command to execute it in an in-memory gnovm, with
+	// the zero values of its parameters as arguments, and report the
+	// gas it uses and the bytes it allocates and persists.
+	//
+	// The mock context of the call is set by the `estimateCaller`,
+	// `estimateSend` and `estimateHeight` settings. The costs of each
+	// call are recorded, so that the next estimate of the call reports
+	// their changes, and warns of any increase.
+	CodeLensEstimate CodeLensSource = "estimate"
+
+	// Generate a gnokey transaction calling a realm function
+	//
+	// This codelens source annotates each exported function of a
+	// realm with commands to generate, with placeholders for its
+	// arguments, the `gnokey maketx call` command line calling it and
+	// the MsgCall transaction that command prints. For functions whose
+	// parameters are not all of primitive types, which `gnokey maketx
+	// call` can't pass, it generates instead a `gnokey maketx run`
+	// script whose main function calls it.
+	//
+	// The transactions are sent to the `makeTxRemote` node, with the
+	// `makeTxChainID` chain ID.
+	CodeLensMakeTx CodeLensSource = "maketx"
+
 	// Run govulncheck
 	//
 	// This codelens source annotates the `module` directive in a
@@ -289,12 +395,10 @@ const (
 
 	// Update dependencies
 	//
-	// This codelens source annotates the `module` directive in a
-	// go.mod file with commands to:
-	//
-	// - check for available upgrades,
-	// - upgrade direct dependencies, and
-	// - upgrade all dependencies transitively.
+	// This codelens source annotates the `require` directives of a
+	// gno.mod file with a command to fetch the latest version of each
+	// required package again, from the package roots, the examples of
+	// the Gno root directory, or the `packageSource` chain.
 	CodeLensUpgradeDependency CodeLensSource = "upgrade_dependency"
 
 	// Update vendor directory
@@ -329,6 +433,20 @@ type CompletionOptions struct {
 	// such as "someSlice.sort!".
 	ExperimentalPostfixCompletions bool `status:"experimental"`
 
+	// PostfixSnippets are additional postfix snippets, enabled with
+	// experimentalPostfixCompletions: it maps the label of each one, such
+	// as "ownable", to its template. The templates have the facilities of
+	// the built-in snippets: for instance, the template
+	//
+	//	{{if .StmtOK}}{{.Import "std"}}.Emit("{{.Cursor}}", "value", {{.X}}){{end}}
+	//
+	// emits an event with the selected expression as an attribute, and
+	// `{{.IsNamed "std" "Address"}}` reports whether it is an address.
+	// A snippet is offered where its template expands to some text. The
+	// snippets whose templates don't parse are reported with the setting,
+	// and ignored.
+	PostfixSnippets map[string]string `status:"experimental"`
+
 	// CompleteFunctionCalls enables function call completion.
 	//
 	// When completing a statement, or when a function return type matches the
@@ -343,22 +461,28 @@ type DocumentationOptions struct {
 	// SingleLine and Structured are intended for use only by authors of editor plugins.
 	HoverKind HoverKind
 
-	// LinkTarget is the base URL for links to Go package
-	// documentation returned by LSP operations such as Hover and
-	// DocumentLinks and in the CodeDescription field of each
-	// Diagnostic.
-	//
-	// It might be one of:
+	// LinkTarget is the base URL for links to package documentation
+	// returned by LSP operations such as Hover and DocumentLinks and in
+	// the CodeDescription field of each Diagnostic.
 	//
-	// * `"godoc.org"`
-	// * `"pkg.go.dev"`
+	// With `gnowebLinks`, it is the address of a gnoweb instance, such as
+	// `"gno.land"` or `"localhost:8888"` for a local gnodev, which serves
+	// pure packages and realms: links to a realm open its rendered page,
+	// or its `$help` and `$source` views for its symbols. The Gno standard
+	// libraries link to their reference documentation on docs.gno.land.
+	// Without a scheme, the links use https, or http for a loopback host.
 	//
-	// If company chooses to use its own `godoc.org`, its address can be used as well.
+	// Otherwise it is a host of Go package documentation, such as
+	// `"pkg.go.dev"`, to which all packages link.
 	//
 	// Modules matching the GOPRIVATE environment variable will not have
 	// documentation links in hover.
 	LinkTarget string
 
+	// GnowebLinks controls whether `linkTarget` is the address of a
+	// gnoweb instance, rather than a host of Go package documentation.
+	GnowebLinks bool
+
 	// LinksInHover controls the presence of documentation links in hover markdown.
 	LinksInHover LinksInHoverEnum
 }
@@ -930,6 +1054,14 @@ func validateDirectoryFilter(ifilter string) (string, error) {
 	return strings.TrimRight(filepath.FromSlash(filter), "/"), nil
 }
 
+// ParsePostfixSnippet parses the template of a postfix snippet, with
+// the functions available to the snippets.
+func ParsePostfixSnippet(body string) (*template.Template, error) {
+	return template.New("postfix_snippet").Funcs(template.FuncMap{
+		"inc": func(i int) int { return i + 1 },
+	}).Parse(body)
+}
+
 // setOne updates a field of o based on the name and value.
 // It returns an error if the value was invalid or duplicate.
 // It is the caller's responsibility to augment the error with 'name'.
@@ -1016,6 +1148,9 @@ func (o *Options) setOne(name string, value any) error {
 	case "linkTarget":
 		return setString(&o.LinkTarget, value)
 
+	case "gnowebLinks":
+		return setBool(&o.GnowebLinks, value)
+
 	case "linksInHover":
 		switch value {
 		case false:
@@ -1110,6 +1245,28 @@ func (o *Options) setOne(name string, value any) error {
 	case "experimentalPostfixCompletions":
 		return setBool(&o.ExperimentalPostfixCompletions, value)
 
+	case "postfixSnippets":
+		snippets, ok := value.(map[string]any)
+		if !ok {
+			return fmt.Errorf("invalid type %T (want JSON object)", value)
+		}
+		// The snippets whose templates don't parse are reported here,
+		// once, and left out.
+		o.PostfixSnippets = make(map[string]string)
+		var errs []error
+		for _, label := range slices.Sorted(maps.Keys(snippets)) {
+			body, ok := snippets[label].(string)
+			if !ok {
+				return fmt.Errorf("invalid template of postfix snippet %q: %T (want string)", label, snippets[label])
+			}
+			if _, err := ParsePostfixSnippet(body); err != nil {
+				errs = append(errs, fmt.Errorf("invalid template of postfix snippet %q: %v", label, err))
+				continue
+			}
+			o.PostfixSnippets[label] = body
+		}
+		return errors.Join(errs...)
+
 	case "templateExtensions":
 		switch value := value.(type) {
 		case []any:
@@ -1140,6 +1297,36 @@ func (o *Options) setOne(name string, value any) error {
 	case "standaloneTags":
 		return setStringSlice(&o.StandaloneTags, value)
 
+	case "packageSource":
+		return setString(&o.PackageSource, value)
+
+	case "makeTxRemote":
+		return setString(&o.MakeTxRemote, value)
+
+	case "makeTxChainID":
+		return setString(&o.MakeTxChainID, value)
+
+	case "gnoRoot":
+		return setString(&o.GnoRoot, value)
+
+	case "packageRoots":
+		return setStringSlice(&o.PackageRoots, value)
+
+	case "gnoModCache":
+		return setString(&o.GnoModCache, value)
+
+	case "realmState":
+		return setString(&o.RealmState, value)
+
+	case "estimateCaller":
+		return setString(&o.EstimateCaller, value)
+
+	case "estimateSend":
+		return setString(&o.EstimateSend, value)
+
+	case "estimateHeight":
+		return setInt64(&o.EstimateHeight, value)
+
 	case "subdirWatchPatterns":
 		return setEnum(&o.SubdirWatchPatterns, value,
 			SubdirWatchPatternsOn,
@@ -1303,6 +1490,18 @@ func setDuration(dest *time.Duration, value any) error {
 	return nil
 }
 
+func setInt64(dest *int64, value any) error {
+	f, ok := value.(float64) // JSON numbers decode as float64
+	if !ok {
+		return fmt.Errorf("invalid type %T (want number)", value)
+	}
+	if f != float64(int64(f)) {
+		return fmt.Errorf("invalid value %v (want integer)", f)
+	}
+	*dest = int64(f)
+	return nil
+}
+
 func setAnnotationMap(dest *map[Annotation]bool, value any) error {
 	all, err := asBoolMap[string](value)
 	if err != nil {
@@ -197,6 +197,18 @@ func TestOptions_Set(t *testing.T) {
 				return o.Vulncheck == ModeVulncheckImports
 			},
 		},
+		{
+			name:  "postfixSnippets",
+			value: map[string]any{"owner": `{{if .StmtOK}}{{.X}}.AssertCallerIsOwner(){{end}}`},
+			check: func(o Options) bool {
+				return len(o.PostfixSnippets) == 1 && o.PostfixSnippets["owner"] != ""
+			},
+		},
+		{
+			name:      "postfixSnippets",
+			value:     map[string]any{"broken": `{{if .StmtOK}`},
+			wantError: true,
+		},
 	}
 
 	for _, test := range tests {
@@ -5,138 +5,72 @@
 //go:build ignore
 // +build ignore
 
-// The generate command reads all the GOROOT/api/go1.*.txt files and
-// generates a single combined manifest.go file containing the Go
-// standard library API symbols along with versions.
+// The generate command reads the sources of the Gno standard libraries,
+// in $GNOROOT/gnovm/stdlibs, and generates the manifest.go file
+// containing their exported API symbols.
+//
+// Native-bound functions, which are declared without a body in the
+// .gno files and implemented in Go by the gnovm, are part of the API
+// like any other function.
+//
+// The Gno standard library is not versioned: all symbols are recorded
+// with version zero.
 package main
 
 import (
 	"bytes"
 	"cmp"
-	"errors"
 	"fmt"
+	"go/ast"
 	"go/format"
-	"go/types"
+	"go/parser"
+	"go/token"
 	"io/fs"
 	"log"
 	"os"
 	"path/filepath"
-	"regexp"
-	"runtime"
 	"slices"
 	"strings"
 
-	"github.com/gfanton/gnopls/internal/packages"
+	"github.com/gnolang/gno/gnovm/pkg/gnoenv"
 )
 
 func main() {
-	pkgs := make(map[string]map[string]symInfo) // package -> symbol -> info
-	symRE := regexp.MustCompile(`^pkg (\S+).*?, (var|func|type|const|method \([^)]*\)) ([\pL\p{Nd}_]+)(.*)`)
-
-	// parse parses symbols out of GOROOT/api/*.txt data, with the specified minor version.
-	// Errors are reported against filename.
-	parse := func(filename string, data []byte, minor int) {
-		for linenum, line := range strings.Split(string(data), "\n") {
-			if line == "" || strings.HasPrefix(line, "#") {
-				continue
-			}
-			m := symRE.FindStringSubmatch(line)
-			if m == nil {
-				log.Fatalf("invalid input: %s:%d: %s", filename, linenum+1, line)
-			}
-			path, kind, sym, rest := m[1], m[2], m[3], m[4]
-
-			if _, recv, ok := strings.Cut(kind, "method "); ok {
-				// e.g. "method (*Func) Pos() token.Pos"
-				kind = "method"
-
-				recv := removeTypeParam(recv) // (*Foo[T]) -> (*Foo)
-
-				sym = recv + "." + sym // (*T).m
-
-			} else if _, field, ok := strings.Cut(rest, " struct, "); ok && kind == "type" {
-				// e.g. "type ParenExpr struct, Lparen token.Pos"
-				kind = "field"
-				name, typ, _ := strings.Cut(field, " ")
-
-				// The api script uses the name
-				// "embedded" (ambiguously) for
-				// the name of an anonymous field.
-				if name == "embedded" {
-					// Strip "*pkg.T" down to "T".
-					typ = strings.TrimPrefix(typ, "*")
-					if _, after, ok := strings.Cut(typ, "."); ok {
-						typ = after
-					}
-					typ = removeTypeParam(typ) // embedded Foo[T] -> Foo
-					name = typ
-				}
-
-				sym += "." + name // T.f
-			}
-
-			symbols, ok := pkgs[path]
-			if !ok {
-				symbols = make(map[string]symInfo)
-				pkgs[path] = symbols
-			}
-
-			// Don't overwrite earlier entries:
-			// enums are redeclared in later versions
-			// as their encoding changes;
-			// deprecations count as updates too.
-			if _, ok := symbols[sym]; !ok {
-				symbols[sym] = symInfo{kind, minor}
-			}
-		}
+	rootDir, err := gnoenv.GuessRootDir()
+	if err != nil {
+		log.Fatalf("unable to guess gno root dir: %v", err)
 	}
+	stdlibsDir := filepath.Join(rootDir, "gnovm", "stdlibs")
 
-	// Read and parse the GOROOT/api manifests.
-	for minor := 0; ; minor++ {
-		base := "go1.txt"
-		if minor > 0 {
-			base = fmt.Sprintf("go1.%d.txt", minor)
+	pkgs := make(map[string]map[string]string) // package -> symbol -> kind
+	err = filepath.WalkDir(stdlibsDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return err
 		}
-		filename := filepath.Join(runtime.GOROOT(), "api", base)
-		data, err := os.ReadFile(filename)
+		rel, err := filepath.Rel(stdlibsDir, path)
 		if err != nil {
-			if errors.Is(err, fs.ErrNotExist) {
-				// All caught up.
-				// Synthesize one final file from any api/next/*.txt fragments.
-				// (They are consolidated into a go1.%d file some time between
-				// the freeze and the first release candidate.)
-				filenames, err := filepath.Glob(filepath.Join(runtime.GOROOT(), "api/next/*.txt"))
-				if err != nil {
-					log.Fatal(err)
-				}
-				var next bytes.Buffer
-				for _, filename := range filenames {
-					data, err := os.ReadFile(filename)
-					if err != nil {
-						log.Fatal(err)
-					}
-					next.Write(data)
-				}
-				parse(filename, next.Bytes(), minor) // (filename is a lie)
-				break
-			}
-			log.Fatal(err)
+			return err
+		}
+		pkgPath := filepath.ToSlash(rel)
+		if isInternal(pkgPath) {
+			return filepath.SkipDir
+		}
+		symbols, err := loadSymbols(path)
+		if err != nil {
+			return err
 		}
-		parse(filename, data, minor)
+		if len(symbols) > 0 {
+			pkgs[pkgPath] = symbols
+		}
+		return nil
+	})
+	if err != nil {
+		log.Fatal(err)
 	}
 
-	// The APIs of the syscall/js and unsafe packages need to be computed explicitly,
-	// because they're not included in the GOROOT/api/go1.*.txt files at this time.
-	pkgs["syscall/js"] = loadSymbols("syscall/js", "GOOS=js", "GOARCH=wasm")
-	pkgs["unsafe"] = exportedSymbols(types.Unsafe) // TODO(adonovan): set correct versions
-
 	// Write the combined manifest.
 	var buf bytes.Buffer
-	buf.WriteString(`// Copyright 2024 The Go Authors. All rights reserved.
-// Use of this source code is governed by a BSD-style
-// license that can be found in the LICENSE file.
-
-// Code generated by generate.go. DO NOT EDIT.
+	buf.WriteString(`// Code generated by generate.go. DO NOT EDIT.
 
 package stdlib
 
@@ -147,9 +81,7 @@ var PackageSymbols = map[string][]Symbol{
 		pkg := pkgs[path]
 		fmt.Fprintf(&buf, "\t%q: {\n", path)
 		for _, name := range sortedKeys(pkg) {
-			info := pkg[name]
-			fmt.Fprintf(&buf, "\t\t{%q, %s, %d},\n",
-				name, strings.Title(info.kind), info.minor)
+			fmt.Fprintf(&buf, "\t\t{%q, %s, 0},\n", name, strings.Title(pkg[name]))
 		}
 		fmt.Fprintln(&buf, "},")
 	}
@@ -163,48 +95,122 @@ var PackageSymbols = map[string][]Symbol{
 	}
 }
 
-type symInfo struct {
-	kind  string // e.g. "func"
-	minor int    // go1.%d
+// isInternal reports whether pkgPath is an internal package, which is
+// not part of the public API.
+func isInternal(pkgPath string) bool {
+	for _, elem := range strings.Split(pkgPath, "/") {
+		if elem == "internal" {
+			return true
+		}
+	}
+	return false
 }
 
-// loadSymbols computes the exported symbols in the specified package
-// by parsing and type-checking the current source.
-func loadSymbols(pkg string, extraEnv ...string) map[string]symInfo {
-	pkgs, err := packages.Load(&packages.Config{
-		Mode: packages.NeedTypes,
-		Env:  append(os.Environ(), extraEnv...),
-	}, pkg)
+// loadSymbols returns the exported symbols of the package in dir, by
+// kind, parsing its .gno files other than tests.
+func loadSymbols(dir string) (map[string]string, error) {
+	entries, err := os.ReadDir(dir)
 	if err != nil {
-		log.Fatalln(err)
-	} else if len(pkgs) != 1 {
-		log.Fatalf("got %d packages, want one package %q", len(pkgs), pkg)
+		return nil, err
+	}
+	fset := token.NewFileSet()
+	symbols := make(map[string]string)
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".gno") || strings.HasSuffix(name, "_test.gno") || strings.HasSuffix(name, "_filetest.gno") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		for _, decl := range f.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				if !decl.Name.IsExported() {
+					continue
+				}
+				if decl.Recv == nil {
+					symbols[decl.Name.Name] = "func"
+				} else if recv, ok := recvName(decl.Recv.List[0].Type); ok {
+					symbols[recv+"."+decl.Name.Name] = "method"
+				}
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					switch spec := spec.(type) {
+					case *ast.ValueSpec:
+						kind := "var"
+						if decl.Tok == token.CONST {
+							kind = "const"
+						}
+						for _, id := range spec.Names {
+							if id.IsExported() {
+								symbols[id.Name] = kind
+							}
+						}
+					case *ast.TypeSpec:
+						if !spec.Name.IsExported() {
+							continue
+						}
+						symbols[spec.Name.Name] = "type"
+						if st, ok := spec.Type.(*ast.StructType); ok {
+							for _, field := range fieldNames(st) {
+								symbols[spec.Name.Name+"."+field] = "field"
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	return symbols, nil
+}
+
+// recvName returns the receiver of a method in the form "(T)" or
+// "(*T)", without type parameters, and whether T is exported.
+func recvName(recv ast.Expr) (string, bool) {
+	ptr := ""
+	if star, ok := recv.(*ast.StarExpr); ok {
+		ptr, recv = "*", star.X
+	}
+	switch x := recv.(type) {
+	case *ast.IndexExpr:
+		recv = x.X
+	case *ast.IndexListExpr:
+		recv = x.X
+	}
+	id, ok := recv.(*ast.Ident)
+	if !ok || !id.IsExported() {
+		return "", false
 	}
-	return exportedSymbols(pkgs[0].Types)
+	return "(" + ptr + id.Name + ")", true
 }
 
-func exportedSymbols(pkg *types.Package) map[string]symInfo {
-	symbols := make(map[string]symInfo)
-	for _, name := range pkg.Scope().Names() {
-		if obj := pkg.Scope().Lookup(name); obj.Exported() {
-			var kind string
-			switch obj.(type) {
-			case *types.Func, *types.Builtin:
-				kind = "func"
-			case *types.Const:
-				kind = "const"
-			case *types.Var:
-				kind = "var"
-			case *types.TypeName:
-				kind = "type"
-				// TODO(adonovan): expand fields and methods of syscall/js.*
-			default:
-				log.Fatalf("unexpected object type: %v", obj)
+// fieldNames returns the names of the exported fields of st, using the
+// type name for embedded fields.
+func fieldNames(st *ast.StructType) []string {
+	var names []string
+	for _, field := range st.Fields.List {
+		if len(field.Names) == 0 {
+			typ := field.Type
+			if star, ok := typ.(*ast.StarExpr); ok {
+				typ = star.X
+			}
+			if sel, ok := typ.(*ast.SelectorExpr); ok {
+				typ = sel.Sel
+			}
+			if id, ok := typ.(*ast.Ident); ok && id.IsExported() {
+				names = append(names, id.Name)
+			}
+			continue
+		}
+		for _, id := range field.Names {
+			if id.IsExported() {
+				names = append(names, id.Name)
 			}
-			symbols[name] = symInfo{kind: kind, minor: 0} // pretend go1.0
 		}
 	}
-	return symbols
+	return names
 }
 
 func sortedKeys[M ~map[K]V, K cmp.Ordered, V any](m M) []K {
@@ -215,12 +221,3 @@ func sortedKeys[M ~map[K]V, K cmp.Ordered, V any](m M) []K {
 	slices.Sort(r)
 	return r
 }
-
-func removeTypeParam(s string) string {
-	i := strings.IndexByte(s, '[')
-	j := strings.LastIndexByte(s, ']')
-	if i > 0 && j > i {
-		s = s[:i] + s[j+len("["):]
-	}
-	return s
-}
@@ -4,9 +4,12 @@
 
 //go:generate go run generate.go
 
-// Package stdlib provides a table of all exported symbols in the
-// standard library, along with the version at which they first
-// appeared.
+// Package stdlib provides a table of all exported symbols in the Gno
+// standard library, as found in $GNOROOT/gnovm/stdlibs.
+//
+// The Version of the symbols, which records the Go version at which a
+// symbol of the Go standard library first appeared, is always zero: the
+// Gno standard library is not versioned.
 package stdlib
 
 import (
@@ -61,7 +64,7 @@ func init() {
 }
 
 // HasPackage reports whether the specified package path is part of
-// the standard library's public API.
+// the Gno standard library's public API.
 func HasPackage(path string) bool {
 	_, ok := PackageSymbols[path]
 	return ok
@@ -162,8 +162,7 @@ require golang.org/x/hello v1.2.3
 `
 
 	for _, commandTitle := range []string{
-		"Upgrade transitive dependencies",
-		"Upgrade direct dependencies",
+		"Upgrade dependencies",
 	} {
 		t.Run(commandTitle, func(t *testing.T) {
 			WithOptions(
@@ -23,6 +23,9 @@ func Diagnostics(ctx context.Context, snapshot *cache.Snapshot) (map[protocol.Do
 
 	reports := map[protocol.DocumentURI][]*cache.Diagnostic{}
 	uri := snapshot.View().GoWork()
+	if uri == "" {
+		uri = snapshot.View().GnoWork()
+	}
 	if uri == "" {
 		return nil, nil
 	}
@@ -55,7 +58,9 @@ func diagnoseOne(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle)
 		return pw.ParseErrors, nil
 	}
 
-	// Add diagnostic if a directory does not contain a module.
+	// Add diagnostic if a directory does not contain a module, or, in a
+	// gno.work file, whose directories need not contain one, if it does
+	// not exist.
 	var diagnostics []*cache.Diagnostic
 	for _, use := range pw.File.Use {
 		rng, err := pw.Mapper.OffsetRange(use.Syntax.Start.Byte, use.Syntax.End.Byte)
@@ -63,6 +68,19 @@ func diagnoseOne(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle)
 			return nil, err
 		}
 
+		if cache.IsGnoWork(pw.URI) {
+			if info, err := os.Stat(useDir(pw, use)); err != nil || !info.IsDir() {
+				diagnostics = append(diagnostics, &cache.Diagnostic{
+					URI:      fh.URI(),
+					Range:    rng,
+					Severity: protocol.SeverityError,
+					Source:   cache.WorkFileError,
+					Message:  fmt.Sprintf("directory %v does not exist", use.Path),
+				})
+			}
+			continue
+		}
+
 		modfh, err := snapshot.ReadFile(ctx, modFileURI(pw, use))
 		if err != nil {
 			return nil, err
@@ -81,12 +99,14 @@ func diagnoseOne(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle)
 }
 
 func modFileURI(pw *cache.ParsedWorkFile, use *modfile.Use) protocol.DocumentURI {
-	workdir := filepath.Dir(pw.URI.Path())
+	return protocol.URIFromPath(filepath.Join(useDir(pw, use), "go.mod"))
+}
 
-	modroot := filepath.FromSlash(use.Path)
-	if !filepath.IsAbs(modroot) {
-		modroot = filepath.Join(workdir, modroot)
+// useDir returns the absolute directory of the use directive.
+func useDir(pw *cache.ParsedWorkFile, use *modfile.Use) string {
+	dir := filepath.FromSlash(use.Path)
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(filepath.Dir(pw.URI.Path()), dir)
 	}
-
-	return protocol.URIFromPath(filepath.Join(modroot, "go.mod"))
+	return dir
 }
@@ -8,6 +8,8 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 
 	"golang.org/x/mod/modfile"
 	"github.com/gfanton/gnopls/internal/cache"
@@ -17,8 +19,9 @@ import (
 )
 
 func Hover(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle, position protocol.Position) (*protocol.Hover, error) {
-	// We only provide hover information for the view's go.work file.
-	if fh.URI() != snapshot.View().GoWork() {
+	// We only provide hover information for the view's go.work or
+	// gno.work file.
+	if fh.URI() != snapshot.View().GoWork() && fh.URI() != snapshot.View().GnoWork() {
 		return nil, nil
 	}
 
@@ -44,8 +47,17 @@ func Hover(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle, positi
 		return nil, nil
 	}
 
-	// Get the mod file denoted by the use.
-	modfh, err := snapshot.ReadFile(ctx, modFileURI(pw, use))
+	// Get the mod file denoted by the use. The directories of a gno.work
+	// file are package trees, whose root may be a package with a gno.mod
+	// file.
+	modURI := modFileURI(pw, use)
+	if cache.IsGnoWork(pw.URI) {
+		modURI = protocol.URIFromPath(filepath.Join(useDir(pw, use), "gno.mod"))
+		if _, err := os.Stat(modURI.Path()); err != nil {
+			return nil, nil
+		}
+	}
+	modfh, err := snapshot.ReadFile(ctx, modURI)
 	if err != nil {
 		return nil, fmt.Errorf("getting modfile handle: %w", err)
 	}
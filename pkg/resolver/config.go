@@ -0,0 +1,93 @@
+package resolver
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/gnolang/gno/gnovm/pkg/gnoenv"
+	"github.com/gnolang/gno/gnovm/pkg/gnomod"
+)
+
+// Config holds the directories the resolver finds the packages in, in
+// addition to those of the patterns of a request. The zero Config
+// resolves against the guessed Gno root directory only.
+type Config struct {
+	// GnoRoot is the root directory of the Gno repository, whose
+	// standard libraries and examples are resolved. If empty, it is
+	// guessed by gnoenv.GuessRootDir.
+	GnoRoot string
+
+	// PackageRoots are additional trees of packages, such as the
+	// examples of another Gno repository.
+	PackageRoots []string
+
+	// ModCache is the directory of the packages downloaded by
+	// "gno mod download". If empty, it is gnomod.ModCachePath.
+	ModCache string
+}
+
+// The kinds of package roots.
+const (
+	StdlibsRoot  = "stdlibs"  // the standard libraries of the Gno root
+	PackageRoot  = "root"     // an additional package root
+	ExamplesRoot = "examples" // the examples of the Gno root
+	ModCacheRoot = "modcache" // the downloaded packages
+)
+
+// A Root is a directory the resolver finds packages in.
+type Root struct {
+	Kind string // StdlibsRoot, PackageRoot, ExamplesRoot or ModCacheRoot
+	Dir  string
+}
+
+// GnoRootDir returns the Gno root directory of c.
+func (c Config) GnoRootDir() (string, error) {
+	if c.GnoRoot != "" {
+		return filepath.Clean(c.GnoRoot), nil
+	}
+	return gnoenv.GuessRootDir()
+}
+
+// ModCacheDir returns the directory of the downloaded packages of c.
+func (c Config) ModCacheDir() string {
+	if c.ModCache != "" {
+		return filepath.Clean(c.ModCache)
+	}
+	return gnomod.ModCachePath()
+}
+
+// Roots returns the package roots of c, in priority order: the first
+// package found for a path shadows those of the following roots. The
+// packages of the patterns of a request come before all of them, and
+// the standard libraries, whose paths no other package has, first.
+//
+// The roots of the Gno root directory are omitted if it is unknown.
+func (c Config) Roots() []Root {
+	var roots []Root
+	gnoRoot, _ := c.GnoRootDir()
+	if gnoRoot != "" {
+		roots = append(roots, Root{StdlibsRoot, filepath.Join(gnoRoot, "gnovm", "stdlibs")})
+	}
+	for _, dir := range c.PackageRoots {
+		roots = append(roots, Root{PackageRoot, filepath.Clean(dir)})
+	}
+	if gnoRoot != "" {
+		roots = append(roots, Root{ExamplesRoot, filepath.Join(gnoRoot, "examples")})
+	}
+	if dir := c.ModCacheDir(); dir != "" {
+		roots = append(roots, Root{ModCacheRoot, dir})
+	}
+	return roots
+}
+
+// RootOf returns the first root of c that contains the package
+// directory dir.
+func (c Config) RootOf(dir string) (Root, bool) {
+	dir = filepath.Clean(dir)
+	for _, root := range c.Roots() {
+		if dir == root.Dir || strings.HasPrefix(dir, root.Dir+string(filepath.Separator)) {
+			return root, true
+		}
+	}
+	return Root{}, false
+}
@@ -0,0 +1,42 @@
+package resolver
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestConfigRoots(t *testing.T) {
+	gnoRoot := filepath.FromSlash("/gno")
+	c := Config{
+		GnoRoot:      gnoRoot,
+		PackageRoots: []string{filepath.FromSlash("/src/other/examples/")},
+		ModCache:     filepath.FromSlash("/home/gno/pkg/mod"),
+	}
+	want := []Root{
+		{StdlibsRoot, filepath.FromSlash("/gno/gnovm/stdlibs")},
+		{PackageRoot, filepath.FromSlash("/src/other/examples")},
+		{ExamplesRoot, filepath.FromSlash("/gno/examples")},
+		{ModCacheRoot, filepath.FromSlash("/home/gno/pkg/mod")},
+	}
+	if got := c.Roots(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Roots() = %v, want %v", got, want)
+	}
+
+	for _, test := range []struct {
+		dir  string
+		want string // root kind, or "" if none
+	}{
+		{"/gno/gnovm/stdlibs/strings", StdlibsRoot},
+		{"/gno/examples/gno.land/p/demo/avl", ExamplesRoot},
+		{"/gno/examples2/gno.land/p/demo/avl", ""},
+		{"/src/other/examples/gno.land/r/demo/foo", PackageRoot},
+		{"/home/gno/pkg/mod/gno.land/p/demo/ufmt", ModCacheRoot},
+		{"/src/mine", ""},
+	} {
+		root, ok := c.RootOf(filepath.FromSlash(test.dir))
+		if got := root.Kind; got != test.want || ok != (test.want != "") {
+			t.Errorf("RootOf(%q) = %v, %v, want kind %q", test.dir, root, ok, test.want)
+		}
+	}
+}
@@ -2,84 +2,95 @@ package resolver
 
 import (
 	"fmt"
-	"io/fs"
 	"log/slog"
+	"maps"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/gfanton/gnopls/internal/packages"
 	"github.com/gfanton/gnopls/pkg/eventlogger"
-	"github.com/gnolang/gno/gnovm/pkg/gnoenv"
 	"github.com/gnolang/gno/gnovm/pkg/gnomod"
 )
 
+// Resolve resolves the packages of the patterns with the zero Config.
 func Resolve(req *packages.DriverRequest, patterns ...string) (*packages.DriverResponse, error) {
+	return Config{}.Resolve(req, patterns...)
+}
+
+// Resolve resolves the packages of the patterns, and those of the roots
+// of c they import.
+func (c Config) Resolve(req *packages.DriverRequest, patterns ...string) (*packages.DriverResponse, error) {
 	logger := eventlogger.EventLoggerWrapper()
 
 	logger.Info("unmarshalled request",
 		"mode", req.Mode.String(),
 		"tests", req.Tests,
 		"build-flags", req.BuildFlags,
-		"overlay", req.Overlay,
+		"overlay", len(req.Overlay),
 	)
 
-	// Inject examples
+	ov := newOverlay(req.Overlay)
+
+	// Inject package roots, in priority order
 
-	gnoRoot, err := gnoenv.GuessRootDir()
-	if err != nil {
+	if _, err := c.GnoRootDir(); err != nil {
 		logger.Warn("can't find gno root, examples and std packages are ignored", slog.String("error", err.Error()))
 	}
 
 	targets := patterns
 
-	if gnoRoot != "" {
-		targets = append(targets, filepath.Join(gnoRoot, "examples", "..."))
+	libsRoot := ""
+	for _, root := range c.Roots() {
+		if root.Kind == StdlibsRoot {
+			libsRoot = root.Dir
+			continue
+		}
+		if _, err := os.Stat(root.Dir); err != nil {
+			logger.Warn("ignoring package root", slog.String("kind", root.Kind), slog.String("error", err.Error()))
+			continue
+		}
+		targets = append(targets, filepath.Join(root.Dir, "..."))
 	}
 
 	pkgsCache := map[string]*packages.Package{}
+	summaries := map[string]*fileSummary{} // by file, for the errors of the packages
 	res := packages.DriverResponse{}
 
 	// Inject stdlibs
 
-	if gnoRoot != "" {
-		libsRoot := filepath.Join(gnoRoot, "gnovm", "stdlibs")
-		if err := fs.WalkDir(os.DirFS(libsRoot), ".", func(path string, d fs.DirEntry, err error) error {
+	if libsRoot != "" {
+		tree, err := index.walk(libsRoot)
+		if err != nil {
+			logger.Warn("failed to inject all stdlibs", slog.String("error", err.Error()))
+			tree = new(dirTree)
+		}
+		for _, pkgDir := range tree.dirs {
+			rel, err := filepath.Rel(libsRoot, pkgDir)
 			if err != nil {
-				return nil
-			}
-
-			if !d.IsDir() {
-				return nil
+				continue
 			}
+			path := filepath.ToSlash(rel)
 
-			pkgDir := filepath.Join(libsRoot, path)
-			entries, err := os.ReadDir(pkgDir)
+			files, err := readPkgFiles(pkgDir, ov)
 			if err != nil {
-				return fmt.Errorf("failed to read dir %q: %w", path, err)
-			}
-
-			gnoFiles := []string{}
-			for _, e := range entries {
-				if e.IsDir() || !strings.HasSuffix(e.Name(), ".gno") {
-					continue
-				}
-				if strings.HasSuffix(e.Name(), "_test.gno") || strings.HasSuffix(e.Name(), "_filetest.gno") {
-					continue
-				}
-				gnoFiles = append(gnoFiles, filepath.Join(pkgDir, e.Name()))
+				logger.Warn("failed to inject all stdlibs", slog.String("error", fmt.Sprintf("failed to read dir %q: %v", path, err)))
+				break
 			}
 
+			gnoFiles := files.GnoFiles
 			if len(gnoFiles) == 0 {
-				return nil
+				continue
 			}
 
-			name, imports, err := resolveNameAndImports(gnoFiles, logger)
+			name, imports, err := resolveNameAndImports(files, gnoFiles, logger)
 			if err != nil {
-				return fmt.Errorf("failed to resolve name and imports for %q: %w", path, err)
+				logger.Warn("failed to inject all stdlibs", slog.String("error", fmt.Sprintf("failed to resolve name and imports for %q: %v", path, err)))
+				break
 			}
 
 			logger.Info("injecting stdlib", slog.String("path", path), slog.String("name", name))
+			maps.Copy(summaries, files.Summaries)
 
 			pkg := &packages.Package{
 				ID:              path,
@@ -92,9 +103,14 @@ func Resolve(req *packages.DriverRequest, patterns ...string) (*packages.DriverR
 			pkgsCache[path] = pkg
 			res.Packages = append(res.Packages, pkg)
 
-			return nil
-		}); err != nil {
-			logger.Warn("failed to inject all stdlibs", slog.String("error", err.Error()))
+			if req.Tests {
+				testPkgs, err := testPackages(pkg, files, logger)
+				if err != nil {
+					logger.Warn("failed to load stdlib tests", slog.String("path", path), slog.String("error", err.Error()))
+					continue
+				}
+				res.Packages = append(res.Packages, testPkgs...)
+			}
 		}
 	}
 
@@ -104,7 +120,7 @@ func Resolve(req *packages.DriverRequest, patterns ...string) (*packages.DriverR
 	for _, target := range targets {
 		dir, file := filepath.Split(target)
 		if file == "..." {
-			pkgQueryRes, err := ListPkgs(dir)
+			pkgQueryRes, err := listPkgs(dir, ov)
 			if err != nil {
 				logger.Error("failed to get pkg list", slog.String("error", err.Error()))
 				return nil, err
@@ -112,7 +128,7 @@ func Resolve(req *packages.DriverRequest, patterns ...string) (*packages.DriverR
 			pkgs = append(pkgs, pkgQueryRes...)
 		} else if strings.HasPrefix(target, "file=") {
 			dir = strings.TrimPrefix(dir, "file=")
-			pkgQueryRes, err := ListPkgs(dir)
+			pkgQueryRes, err := listPkgs(dir, ov)
 			if err != nil {
 				logger.Error("failed to get pkg", slog.String("error", err.Error()))
 				return nil, err
@@ -131,35 +147,64 @@ func Resolve(req *packages.DriverRequest, patterns ...string) (*packages.DriverR
 	logger.Info("discovered packages", slog.Int("count", len(pkgs)))
 
 	// Convert packages
-
+	//
+	// The packages of the patterns, such as the package trees of a
+	// gno.work file, come before those of the roots: the first package
+	// found for a path shadows the others, so that workspace packages
+	// resolve against each other rather than against their copies in the
+	// examples.
+
+	discovered := map[string]bool{}
 	for _, pkg := range pkgs {
-		pkg, err := gnoPkgToGo(&pkg, logger)
+		pkg, files, err := gnoPkgToGo(&pkg, ov, logger)
 		if err != nil {
 			logger.Error("failed to convert gno pkg to go pkg", slog.String("error", err.Error()))
 			continue
 		}
+		if discovered[pkg.PkgPath] {
+			logger.Info("shadowed package", slog.String("path", pkg.PkgPath), slog.Any("files", pkg.GoFiles))
+			continue
+		}
+		discovered[pkg.PkgPath] = true
+		maps.Copy(summaries, files.Summaries)
 		pkgsCache[pkg.PkgPath] = pkg
 		res.Packages = append(res.Packages, pkg)
 		res.Roots = append(res.Roots, pkg.ID)
+
+		if !req.Tests {
+			continue
+		}
+
+		testPkgs, err := testPackages(pkg, files, logger)
+		if err != nil {
+			logger.Error("failed to load test packages", slog.String("error", err.Error()))
+			continue
+		}
+		for _, testPkg := range testPkgs {
+			res.Packages = append(res.Packages, testPkg)
+			res.Roots = append(res.Roots, testPkg.ID)
+		}
 	}
 
 	// Resolve imports
 
 	for _, pkg := range res.Packages {
-		toDelete := []string{}
-		for importPath := range pkg.Imports {
+		for _, importPath := range sortedKeys(pkg.Imports) {
+			if pkg.Imports[importPath] != nil {
+				// Already resolved, e.g. the package under test
+				// imported by an external test package.
+				continue
+			}
 			imp, ok := pkgsCache[importPath]
 			if ok {
 				pkg.Imports[importPath] = imp
 				logger.Info("found import", slog.String("path", importPath))
 			} else {
 				logger.Info("missed import", slog.String("path", importPath))
-				toDelete = append(toDelete, importPath)
+				delete(pkg.Imports, importPath)
+				pkg.Errors = append(pkg.Errors, importErrors(pkg, importPath, summaries)...)
 			}
 		}
-		for _, toDel := range toDelete {
-			delete(pkg.Imports, toDel)
-		}
 		logger.Info("converted package", slog.Any("pkg", pkg))
 	}
 
@@ -0,0 +1,90 @@
+package resolver
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/gfanton/gnopls/internal/packages"
+	"golang.org/x/mod/modfile"
+)
+
+// The resolver reports the problems it finds in a package as errors of
+// the package, in the form of go list errors: the position of an error
+// is "file:line:col", and its message that of the go command or the
+// type checker, so that gnopls attaches the same quick fixes to them.
+
+// gnoModError returns the error of the package whose gno.mod file, at
+// path, is invalid. The error is positioned at the first syntax error of
+// the file, if any, and at the start of the file otherwise.
+func gnoModError(path string, err error) packages.Error {
+	var errList modfile.ErrorList
+	if errors.As(err, &errList) && len(errList) > 0 {
+		e := errList[0]
+		return packages.Error{
+			Pos:  fmt.Sprintf("%s:%d:%d", e.Filename, e.Pos.Line, e.Pos.LineRune),
+			Msg:  e.Err.Error(),
+			Kind: packages.ListError,
+		}
+	}
+	return packages.Error{
+		Pos:  path + ":1:1",
+		Msg:  err.Error(),
+		Kind: packages.ListError,
+	}
+}
+
+// nameConflictErrors returns an error at the package clause of each of
+// the .gno files that doesn't declare the package name.
+func nameConflictErrors(files *pkgFiles, gnoFiles []string, name string) []packages.Error {
+	var errs []packages.Error
+	for _, fpath := range gnoFiles {
+		sum := files.Summaries[fpath]
+		if sum == nil || sum.Err != "" || sum.Name == name {
+			continue
+		}
+		errs = append(errs, packages.Error{
+			Pos:  fpath + ":" + sum.NamePos,
+			Msg:  fmt.Sprintf("package %s; expected package %s", sum.Name, name),
+			Kind: packages.ListError,
+		})
+	}
+	return errs
+}
+
+// importErrors returns an error at each import of the package path by the
+// files of pkg, for a package the resolver could not find.
+func importErrors(pkg *packages.Package, path string, summaries map[string]*fileSummary) []packages.Error {
+	msg := fmt.Sprintf("could not import %s (cannot find package %q in the workspace or GNOROOT)", path, path)
+	var errs []packages.Error
+	for _, fpath := range pkg.CompiledGoFiles {
+		sum := summaries[fpath]
+		if sum == nil {
+			continue
+		}
+		for i, imp := range sum.Imports {
+			if imp == path && i < len(sum.ImportPos) {
+				errs = append(errs, packages.Error{
+					Pos:  fpath + ":" + sum.ImportPos[i],
+					Msg:  msg,
+					Kind: packages.ListError,
+				})
+			}
+		}
+	}
+	if len(errs) == 0 {
+		// No position: the error applies to all the files.
+		errs = append(errs, packages.Error{Msg: msg, Kind: packages.ListError})
+	}
+	return errs
+}
+
+// sortedKeys returns the sorted keys of m.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
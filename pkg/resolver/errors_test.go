@@ -0,0 +1,58 @@
+package resolver
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/gfanton/gnopls/internal/packages"
+	"github.com/gfanton/gnopls/pkg/eventlogger"
+	"github.com/gnolang/gno/gnovm/pkg/gnomod"
+)
+
+func TestPackageErrors(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"gno.mod": "module gno.land/p/demo/foo\n\nrequire (\n",
+		"a.gno":   "package foo\n\nimport (\n\t\"strings\"\n\t\"gno.land/p/demo/avl\"\n)\n",
+		"b.gno":   "package foo\n",
+		"c.gno":   "package bar\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pkg, files, err := gnoPkgToGo(&gnomod.Pkg{Dir: dir}, nil, eventlogger.EventLoggerWrapper())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pkg.PkgPath != dir {
+		t.Errorf("package with an invalid gno.mod has path %q, want its directory", pkg.PkgPath)
+	}
+	if len(pkg.Errors) != 2 {
+		t.Fatalf("got errors %v, want a gno.mod error and a name conflict", pkg.Errors)
+	}
+	if got, want := pkg.Errors[0].Pos, filepath.Join(dir, "gno.mod")+":4:1"; got != want {
+		t.Errorf("gno.mod error at %q, want %q", got, want)
+	}
+	want := packages.Error{
+		Pos:  filepath.Join(dir, "c.gno") + ":1:9",
+		Msg:  "package bar; expected package foo",
+		Kind: packages.ListError,
+	}
+	if !reflect.DeepEqual(pkg.Errors[1], want) {
+		t.Errorf("name conflict error = %+v, want %+v", pkg.Errors[1], want)
+	}
+
+	got := importErrors(pkg, "gno.land/p/demo/avl", files.Summaries)
+	want = packages.Error{
+		Pos:  filepath.Join(dir, "a.gno") + ":5:2",
+		Msg:  `could not import gno.land/p/demo/avl (cannot find package "gno.land/p/demo/avl" in the workspace or GNOROOT)`,
+		Kind: packages.ListError,
+	}
+	if !reflect.DeepEqual(got, []packages.Error{want}) {
+		t.Errorf("importErrors() = %+v, want [%+v]", got, want)
+	}
+}
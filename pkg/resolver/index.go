@@ -0,0 +1,303 @@
+package resolver
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/gfanton/gnopls/internal/filecache"
+	"github.com/gnolang/gno/gnovm/pkg/gnomod"
+)
+
+// The resolver keeps an index of the package directories it has read, so
+// that reloading a workspace doesn't parse again the files of the
+// thousands of packages of the standard libraries and the examples.
+//
+// Each directory is keyed by its modification time, the content of its
+// gno.mod file, and the name, size and modification time of each of its
+// files: editing, adding or removing a file changes the key of its
+// directory only, so that only the affected package is parsed again.
+// Its importers are linked to it again by Resolve, which doesn't
+// require parsing.
+//
+// The index is kept in memory for the lifetime of the process, and in
+// the filecache, shared across sessions and processes. The directories
+// in memory, and the directory trees walked to discover the packages,
+// are not read again until a change on disk invalidates them: see
+// Invalidate. Changes outside of the files watched by the client, such
+// as those of a Gno root directory, are only seen by a new process.
+
+// indexKind is the filecache kind of package directory entries. It must
+// change whenever the encoding of pkgFiles does.
+const indexKind = "gnoresolve-v2"
+
+// dirIndex is the in-memory index of package directories.
+type dirIndex struct {
+	mu      sync.Mutex
+	entries map[string]indexEntry // by directory
+	trees   map[string]*dirTree   // by root
+	gen     int                   // incremented by each invalidation
+}
+
+type indexEntry struct {
+	key   [32]byte
+	files *pkgFiles
+}
+
+// dirTree is a directory tree on disk, as walked by the resolver.
+type dirTree struct {
+	dirs []string              // in walk order, root first
+	pkgs map[string]gnomod.Pkg // by directory, of the gno.mod files on disk
+}
+
+var index = newDirIndex()
+
+func newDirIndex() *dirIndex {
+	return &dirIndex{
+		entries: make(map[string]indexEntry),
+		trees:   make(map[string]*dirTree),
+	}
+}
+
+// Invalidate forgets the package directories and the directory trees
+// affected by a change on disk of the file or directory path, so that
+// they are read again by the next resolution.
+func Invalidate(path string) {
+	index.invalidate(filepath.Clean(path))
+}
+
+func (idx *dirIndex) invalidate(path string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.gen++
+	for dir := range idx.entries {
+		if dir == filepath.Dir(path) || within(dir, path) {
+			delete(idx.entries, dir)
+		}
+	}
+	for root, tree := range idx.trees {
+		if !within(path, root) && !within(root, path) {
+			continue
+		}
+		// A .gno file of a known directory changes neither the
+		// directories of the tree nor its gno.mod files.
+		if strings.HasSuffix(path, ".gno") && slices.Contains(tree.dirs, filepath.Dir(path)) {
+			continue
+		}
+		delete(idx.trees, root)
+	}
+}
+
+// within reports whether path is dir or is in its tree.
+func within(path, dir string) bool {
+	return path == dir || strings.HasPrefix(path, dir+string(filepath.Separator))
+}
+
+// fileSummary is the information the resolver needs from a .gno file.
+type fileSummary struct {
+	Name      string   // package name
+	NamePos   string   // "line:col" of the package name
+	Imports   []string // import paths
+	ImportPos []string // "line:col" of each import path
+	PkgPath   string   // value of the PKGPATH directive of a filetest, if any
+	Err       string   // parse error, if any
+}
+
+// load returns the files of the package directory pkgDir, from the
+// index if the directory wasn't invalidated since it was last read, and
+// from the filecache if it didn't change since.
+func (idx *dirIndex) load(pkgDir string) (*pkgFiles, error) {
+	idx.mu.Lock()
+	entry, ok := idx.entries[pkgDir]
+	gen := idx.gen
+	idx.mu.Unlock()
+	if ok {
+		return entry.files, nil
+	}
+
+	key, err := dirKey(pkgDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files *pkgFiles
+	if data, err := filecache.Get(indexKind, key); err == nil {
+		files = new(pkgFiles)
+		if err := json.Unmarshal(data, files); err != nil {
+			files = nil // corrupt entry: read the directory again
+		}
+	}
+	if files == nil {
+		files, err = scanPkgFiles(pkgDir)
+		if err != nil {
+			return nil, err
+		}
+		if data, err := json.Marshal(files); err == nil {
+			// Errors are not fatal: the entry is only an optimization.
+			_ = filecache.Set(indexKind, key, data)
+		}
+	}
+
+	idx.mu.Lock()
+	if idx.gen == gen { // not invalidated while reading
+		idx.entries[pkgDir] = indexEntry{key: key, files: files}
+	}
+	idx.mu.Unlock()
+	return files, nil
+}
+
+// walk returns the directory tree root, walking it on disk unless it was
+// walked since its last invalidation.
+func (idx *dirIndex) walk(root string) (*dirTree, error) {
+	idx.mu.Lock()
+	tree, ok := idx.trees[root]
+	gen := idx.gen
+	idx.mu.Unlock()
+	if ok {
+		return tree, nil
+	}
+
+	tree = &dirTree{pkgs: make(map[string]gnomod.Pkg)}
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		tree.dirs = append(tree.dirs, path)
+		pkg, ok, err := readPkg(path, nil)
+		if ok {
+			tree.pkgs[path] = pkg
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	idx.mu.Lock()
+	if idx.gen == gen {
+		idx.trees[root] = tree
+	}
+	idx.mu.Unlock()
+	return tree, nil
+}
+
+// dirKey returns the index key of the package directory pkgDir.
+func dirKey(pkgDir string) ([32]byte, error) {
+	info, err := os.Stat(pkgDir)
+	if err != nil {
+		return [32]byte{}, fmt.Errorf("failed to stat pkg dir %q: %w", pkgDir, err)
+	}
+	dirEntries, err := os.ReadDir(pkgDir)
+	if err != nil {
+		return [32]byte{}, fmt.Errorf("failed to read pkg dir %q: %w", pkgDir, err)
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%d\n", pkgDir, info.ModTime().UnixNano())
+	if gnomod, err := os.ReadFile(filepath.Join(pkgDir, "gno.mod")); err == nil {
+		fmt.Fprintf(h, "gno.mod %d\n", len(gnomod))
+		h.Write(gnomod)
+	}
+	for _, entry := range dirEntries {
+		if entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue // removed since ReadDir
+		}
+		fmt.Fprintf(h, "%s %d %d\n", entry.Name(), info.Size(), info.ModTime().UnixNano())
+	}
+	var key [32]byte
+	h.Sum(key[:0])
+	return key, nil
+}
+
+// scanPkgFiles reads the package directory pkgDir, and parses the
+// header of each of its .gno files.
+func scanPkgFiles(pkgDir string) (*pkgFiles, error) {
+	dirEntries, err := os.ReadDir(pkgDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pkg dir %q: %w", pkgDir, err)
+	}
+
+	files := &pkgFiles{Summaries: make(map[string]*fileSummary)}
+	for _, entry := range dirEntries {
+		if entry.IsDir() {
+			continue
+		}
+		fpath := filepath.Join(pkgDir, entry.Name())
+		if files.add(fpath) {
+			files.Summaries[fpath] = summarizeFile(fpath)
+		}
+	}
+	return files, nil
+}
+
+// add adds the file fpath to the list of its kind, and reports whether
+// it is a .gno file, which must be summarized.
+func (files *pkgFiles) add(fpath string) bool {
+	switch {
+	case strings.HasSuffix(fpath, "_filetest.gno"):
+		files.FiletestFiles = append(files.FiletestFiles, fpath)
+	case strings.HasSuffix(fpath, "_test.gno"):
+		files.TestFiles = append(files.TestFiles, fpath)
+	case strings.HasSuffix(fpath, ".gno"):
+		files.GnoFiles = append(files.GnoFiles, fpath)
+	default:
+		// TODO: should we really include all other files?
+		files.OtherFiles = append(files.OtherFiles, fpath)
+		return false
+	}
+	return true
+}
+
+// summarizeFile parses the header of the .gno file fpath. Errors are
+// recorded in the summary, so that they are reported, and cached, along
+// with the package.
+func summarizeFile(fpath string) *fileSummary {
+	src, err := os.ReadFile(fpath)
+	if err != nil {
+		return &fileSummary{Err: fmt.Sprintf("failed to read file %q: %v", fpath, err)}
+	}
+	return summarizeSource(fpath, src)
+}
+
+// summarizeSource parses the header of the source src of the .gno file
+// fpath.
+func summarizeSource(fpath string, src []byte) *fileSummary {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, fpath, src, parser.SkipObjectResolution|parser.ImportsOnly)
+	if err != nil {
+		return &fileSummary{Err: fmt.Sprintf("parse: %v", err)}
+	}
+	lineCol := func(pos token.Pos) string {
+		posn := fset.Position(pos)
+		return fmt.Sprintf("%d:%d", posn.Line, posn.Column)
+	}
+
+	sum := &fileSummary{Name: f.Name.String(), NamePos: lineCol(f.Name.Pos())}
+	for _, imp := range f.Imports {
+		importPath := imp.Path.Value
+		if len(importPath) >= 2 {
+			importPath = importPath[1 : len(importPath)-1]
+		}
+		sum.Imports = append(sum.Imports, importPath)
+		sum.ImportPos = append(sum.ImportPos, lineCol(imp.Path.Pos()))
+	}
+	if strings.HasSuffix(fpath, "_filetest.gno") {
+		sum.PkgPath = filetestPkgPath(src)
+	}
+	return sum
+}
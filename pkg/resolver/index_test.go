@@ -0,0 +1,145 @@
+package resolver
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIndex(t *testing.T) {
+	root := t.TempDir()
+	write := func(path, content string, mtime time.Time) {
+		t.Helper()
+		path = filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t0 := time.Now().Add(-time.Hour)
+	write("foo/gno.mod", "module gno.land/p/demo/foo\n", t0)
+	write("foo/foo.gno", "package foo\n\nimport \"strings\"\n", t0)
+	write("bar/gno.mod", "module gno.land/p/demo/bar\n", t0)
+	write("bar/bar.gno", "package bar\n", t0)
+
+	idx := newDirIndex()
+	load := func(dir string) *pkgFiles {
+		t.Helper()
+		files, err := idx.load(filepath.Join(root, dir))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return files
+	}
+	foo, bar := load("foo"), load("bar")
+	if got := foo.Summaries[filepath.Join(root, "foo", "foo.gno")].Imports; !reflect.DeepEqual(got, []string{"strings"}) {
+		t.Fatalf("foo imports %q, want [strings]", got)
+	}
+
+	// Unchanged directories are not read again.
+	if load("foo") != foo || load("bar") != bar {
+		t.Errorf("unchanged directories were read again")
+	}
+
+	// The index doesn't see a change until it is invalidated, and then
+	// only reads the directory of the file again.
+	write("foo/foo.gno", "package foo\n\nimport \"errors\"\n", t0.Add(time.Minute))
+	if load("foo") != foo {
+		t.Errorf("foo was read again without an invalidation")
+	}
+	idx.invalidate(filepath.Join(root, "foo", "foo.gno"))
+	if got := load("foo").Summaries[filepath.Join(root, "foo", "foo.gno")].Imports; !reflect.DeepEqual(got, []string{"errors"}) {
+		t.Errorf("after edit, foo imports %q, want [errors]", got)
+	}
+	if load("bar") != bar {
+		t.Errorf("bar was read again after an edit of foo")
+	}
+
+	// So does adding a file.
+	write("bar/bar_test.gno", "package bar\n\nimport \"testing\"\n", t0)
+	idx.invalidate(filepath.Join(root, "bar", "bar_test.gno"))
+	if got := load("bar").TestFiles; len(got) != 1 {
+		t.Errorf("after adding a test file, bar has test files %q, want 1", got)
+	}
+
+	// A new index, as in another session, reuses the filecache.
+	idx = newDirIndex()
+	if got := load("foo").Summaries[filepath.Join(root, "foo", "foo.gno")].Imports; !reflect.DeepEqual(got, []string{"errors"}) {
+		t.Errorf("in a new index, foo imports %q, want [errors]", got)
+	}
+}
+
+func TestListPkgsCache(t *testing.T) {
+	root := t.TempDir()
+	write := func(path, content string) {
+		t.Helper()
+		path = filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("foo/gno.mod", "module gno.land/p/demo/foo\n")
+	write("foo/foo.gno", "package foo\n")
+	t.Cleanup(func() { Invalidate(root) })
+
+	list := func(ov overlay) string {
+		t.Helper()
+		pkgs, err := listPkgs(root, ov)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, pkg := range pkgs {
+			names = append(names, pkg.Name)
+		}
+		return strings.Join(names, " ")
+	}
+	if got, want := list(nil), "gno.land/p/demo/foo"; got != want {
+		t.Fatalf("listPkgs() = %q, want %q", got, want)
+	}
+
+	// A new package is listed once its gno.mod file is invalidated,
+	// and not by the invalidation of a .gno file of a known directory.
+	write("bar/gno.mod", "module gno.land/p/demo/bar\n")
+	Invalidate(filepath.Join(root, "foo", "foo.gno"))
+	if got, want := list(nil), "gno.land/p/demo/foo"; got != want {
+		t.Errorf("listPkgs() before the invalidation of bar = %q, want %q", got, want)
+	}
+	Invalidate(filepath.Join(root, "bar", "gno.mod"))
+	if got, want := list(nil), "gno.land/p/demo/bar gno.land/p/demo/foo"; got != want {
+		t.Errorf("listPkgs() after the invalidation of bar = %q, want %q", got, want)
+	}
+
+	// The gno.mod files of the overlay replace those of the disk, and
+	// add packages.
+	ov := newOverlay(map[string][]byte{
+		filepath.Join(root, "foo", "gno.mod"): []byte("module gno.land/p/demo/foo2\n"),
+		filepath.Join(root, "baz", "gno.mod"): []byte("module gno.land/p/demo/baz\n"),
+	})
+	if got, want := list(ov), "gno.land/p/demo/bar gno.land/p/demo/foo2 gno.land/p/demo/baz"; got != want {
+		t.Errorf("listPkgs(overlay) = %q, want %q", got, want)
+	}
+	if got, want := list(nil), "gno.land/p/demo/bar gno.land/p/demo/foo"; got != want {
+		t.Errorf("listPkgs() after the overlay = %q, want %q", got, want)
+	}
+
+	// Removing a directory removes its package.
+	if err := os.RemoveAll(filepath.Join(root, "bar")); err != nil {
+		t.Fatal(err)
+	}
+	Invalidate(filepath.Join(root, "bar"))
+	if got, want := list(nil), "gno.land/p/demo/foo"; got != want {
+		t.Errorf("listPkgs() after removing bar = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,108 @@
+package resolver
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// overlay maps the absolute paths of the unsaved editor buffers to their
+// content, as in the Overlay field of the driver request. The resolver
+// reads files through it, so that package metadata follows the buffers:
+// new files, and new package directories whose gno.mod file was not
+// saved yet, are resolved along with the files on disk.
+//
+// A nil overlay reads the disk only.
+type overlay map[string][]byte
+
+// newOverlay returns the overlay of the driver request overlay m, whose
+// paths are cleaned.
+func newOverlay(m map[string][]byte) overlay {
+	if len(m) == 0 {
+		return nil
+	}
+	ov := make(overlay, len(m))
+	for path, content := range m {
+		ov[filepath.Clean(path)] = content
+	}
+	return ov
+}
+
+// readFile returns the content of the file path, from the overlay if it
+// has a buffer for it, and from the disk otherwise.
+func (ov overlay) readFile(path string) ([]byte, error) {
+	if content, ok := ov[filepath.Clean(path)]; ok {
+		return content, nil
+	}
+	return os.ReadFile(path)
+}
+
+// dirFiles returns the sorted paths of the overlay files that are
+// directly in dir.
+func (ov overlay) dirFiles(dir string) []string {
+	dir = filepath.Clean(dir)
+	var paths []string
+	for path := range ov {
+		if filepath.Dir(path) == dir {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// gnoModDirs returns the sorted directories under root, inclusive, of
+// the gno.mod files of the overlay.
+func (ov overlay) gnoModDirs(root string) []string {
+	root = filepath.Clean(root)
+	var dirs []string
+	for path := range ov {
+		if filepath.Base(path) != "gno.mod" {
+			continue
+		}
+		dir := filepath.Dir(path)
+		if dir == root || strings.HasPrefix(dir, root+string(filepath.Separator)) {
+			dirs = append(dirs, dir)
+		}
+	}
+	sort.Strings(dirs)
+	return dirs
+}
+
+// apply returns the files of the package directory pkgDir, as read from
+// the disk into files (nil if the directory doesn't exist), updated with
+// the buffers of the overlay in that directory. files is not modified:
+// the files of the disk are shared by the index.
+func (ov overlay) apply(pkgDir string, files *pkgFiles) *pkgFiles {
+	paths := ov.dirFiles(pkgDir)
+	if len(paths) == 0 && files != nil {
+		return files
+	}
+
+	res := &pkgFiles{Summaries: make(map[string]*fileSummary)}
+	if files != nil {
+		res.GnoFiles = append(res.GnoFiles, files.GnoFiles...)
+		res.TestFiles = append(res.TestFiles, files.TestFiles...)
+		res.FiletestFiles = append(res.FiletestFiles, files.FiletestFiles...)
+		res.OtherFiles = append(res.OtherFiles, files.OtherFiles...)
+		for path, sum := range files.Summaries {
+			res.Summaries[path] = sum
+		}
+	}
+	for _, path := range paths {
+		_, isGno := res.Summaries[path]
+		if !isGno && !slices.Contains(res.OtherFiles, path) {
+			isGno = res.add(path) // a new file
+		}
+		if isGno {
+			res.Summaries[path] = summarizeSource(path, ov[path])
+		}
+	}
+	sort.Strings(res.GnoFiles)
+	sort.Strings(res.TestFiles)
+	sort.Strings(res.FiletestFiles)
+	sort.Strings(res.OtherFiles)
+	return res
+}
@@ -0,0 +1,78 @@
+package resolver
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestOverlay(t *testing.T) {
+	root := t.TempDir()
+	for path, content := range map[string]string{
+		"foo/gno.mod": "module gno.land/p/demo/foo\n",
+		"foo/foo.gno": "package foo\n\nimport \"strings\"\n",
+	} {
+		path = filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fooDir, barDir := filepath.Join(root, "foo"), filepath.Join(root, "bar")
+	ov := newOverlay(map[string][]byte{
+		// An edited file, and a new one.
+		filepath.Join(fooDir, "foo.gno"):    []byte("package foo\n\nimport \"errors\"\n"),
+		filepath.Join(fooDir, "new.gno"):    []byte("package foo\n\nimport \"gno.land/p/demo/bar\"\n"),
+		filepath.Join(fooDir, "README.md"):  []byte("# foo\n"),
+		filepath.Join(barDir, "gno.mod"):    []byte("module gno.land/p/demo/bar\n"),
+		filepath.Join(barDir, "bar.gno"):    []byte("package bar\n"),
+		filepath.Join(barDir, "x_test.gno"): []byte("package bar\n"),
+	})
+
+	pkgs, err := listPkgs(root, ov)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, pkg := range pkgs {
+		paths = append(paths, pkg.Name)
+	}
+	if want := []string{"gno.land/p/demo/foo", "gno.land/p/demo/bar"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("listPkgs() = %q, want %q", paths, want)
+	}
+
+	foo, err := readPkgFiles(fooDir, ov)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{filepath.Join(fooDir, "foo.gno"), filepath.Join(fooDir, "new.gno")}
+	if !reflect.DeepEqual(foo.GnoFiles, want) {
+		t.Errorf("foo has files %q, want %q", foo.GnoFiles, want)
+	}
+	if got := foo.Summaries[filepath.Join(fooDir, "foo.gno")].Imports; !reflect.DeepEqual(got, []string{"errors"}) {
+		t.Errorf("foo.gno imports %q, want [errors]", got)
+	}
+	if got, want := foo.OtherFiles, []string{filepath.Join(fooDir, "README.md"), filepath.Join(fooDir, "gno.mod")}; !reflect.DeepEqual(got, want) {
+		t.Errorf("foo has other files %q, want %q", got, want)
+	}
+
+	// The files of the disk, shared by the index, are left unchanged.
+	disk, err := readPkgFiles(fooDir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := disk.Summaries[filepath.Join(fooDir, "foo.gno")].Imports; !reflect.DeepEqual(got, []string{"strings"}) {
+		t.Errorf("on disk, foo.gno imports %q, want [strings]", got)
+	}
+
+	bar, err := readPkgFiles(barDir, ov)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bar.GnoFiles) != 1 || len(bar.TestFiles) != 1 {
+		t.Errorf("bar has files %q and test files %q, want 1 of each", bar.GnoFiles, bar.TestFiles)
+	}
+}
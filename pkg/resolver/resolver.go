@@ -1,94 +1,115 @@
 package resolver
 
 import (
+	"errors"
 	"fmt"
-	"go/parser"
-	"go/token"
 	"io/fs"
 	"log/slog"
 	"os"
 	"path/filepath"
-	"strings"
+	"slices"
 
 	"github.com/gfanton/gnopls/internal/packages"
 	"github.com/gnolang/gno/gnovm/pkg/gnomod"
 )
 
-func gnoPkgToGo(gnoPkg *gnomod.Pkg, logger *slog.Logger) (*packages.Package, error) {
-	// TODO: support subpkgs
-	gnomodFile, err := gnomod.ParseAt(gnoPkg.Dir)
+// pkgFiles lists the files of a package directory, grouped by kind.
+type pkgFiles struct {
+	GnoFiles      []string // regular .gno files
+	TestFiles     []string // _test.gno files, in-package and external
+	FiletestFiles []string // _filetest.gno files
+	OtherFiles    []string // everything else
+
+	// Summaries holds the parsed header of each .gno file, by path.
+	Summaries map[string]*fileSummary
+}
+
+// readPkgFiles returns the files of the package directory pkgDir, as
+// seen through the overlay ov. The files of the disk are only read and
+// parsed again if the directory was invalidated since the last call;
+// see dirIndex. The directory need not exist on disk if ov has files in it.
+func readPkgFiles(pkgDir string, ov overlay) (*pkgFiles, error) {
+	files, err := index.load(pkgDir)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse gno module at %q: %w", gnoPkg.Dir, err)
+		if len(ov.dirFiles(pkgDir)) == 0 {
+			return nil, err
+		}
+		files = nil // a directory of the overlay only
 	}
+	return ov.apply(pkgDir, files), nil
+}
 
+func gnoPkgToGo(gnoPkg *gnomod.Pkg, ov overlay, logger *slog.Logger) (*packages.Package, *pkgFiles, error) {
 	pkgDir := filepath.Clean(gnoPkg.Dir)
 
-	gnoFiles := []string{}
-	otherFiles := []string{}
-	dirEntries, err := os.ReadDir(pkgDir)
+	// A malformed gno.mod file is reported as an error of the package,
+	// whose path is then that of its directory, which nothing imports.
+	var errs []packages.Error
+	pkgPath := pkgDir
+	gnoModPath := filepath.Join(pkgDir, "gno.mod")
+	// TODO: support subpkgs
+	gnomodFile, err := parseGnoMod(gnoModPath, ov)
+	if err == nil {
+		gnomodFile.Sanitize()
+		err = gnomodFile.Validate()
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to read pkg dir %q: %w", pkgDir, err)
+		logger.Warn("invalid gno.mod", slog.String("path", gnoModPath), slog.String("error", err.Error()))
+		errs = append(errs, gnoModError(gnoModPath, err))
+	} else {
+		pkgPath = gnomodFile.Module.Mod.Path
 	}
-	for _, entry := range dirEntries {
-		if entry.IsDir() {
-			continue
-		}
-		fpath := filepath.Join(pkgDir, entry.Name())
-		if strings.HasSuffix(fpath, ".gno") {
-			if !strings.HasSuffix(fpath, "_test.gno") && !strings.HasSuffix(fpath, "_filetest.gno") {
-				gnoFiles = append(gnoFiles, fpath)
-			}
-		} else {
-			// TODO: should we really include all other files?
-			otherFiles = append(otherFiles, fpath)
-		}
+
+	files, err := readPkgFiles(pkgDir, ov)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	bestName, imports, err := resolveNameAndImports(gnoFiles, logger)
+	bestName, imports, err := resolveNameAndImports(files, files.GnoFiles, logger)
 	if err != nil {
-		return nil, fmt.Errorf("failed to resolve name and imports: %w", err)
+		return nil, nil, fmt.Errorf("failed to resolve name and imports: %w", err)
 	}
+	errs = append(errs, nameConflictErrors(files, files.GnoFiles, bestName)...)
 
 	return &packages.Package{
 		// Always required
 		ID:     pkgDir,
-		Errors: nil, // TODO
+		Errors: errs,
 
 		// NeedName
 		Name:    bestName,
-		PkgPath: gnomodFile.Module.Mod.Path,
+		PkgPath: pkgPath,
 
 		// NeedFiles
-		GoFiles:    gnoFiles,
-		OtherFiles: otherFiles,
+		GoFiles:    files.GnoFiles,
+		OtherFiles: files.OtherFiles,
 
 		// NeedCompiledGoFiles
-		CompiledGoFiles: gnoFiles, // TODO: check if enough
+		CompiledGoFiles: files.GnoFiles, // TODO: check if enough
 
 		// NeedImports
 		// if not NeedDeps, only ID filled
 		Imports: imports,
-	}, nil
+	}, files, nil
 }
 
-func resolveNameAndImports(gnoFiles []string, logger *slog.Logger) (string, map[string]*packages.Package, error) {
+// resolveNameAndImports returns the most common package name of the
+// given .gno files of the package, and the union of their imports.
+func resolveNameAndImports(files *pkgFiles, gnoFiles []string, logger *slog.Logger) (string, map[string]*packages.Package, error) {
 	names := map[string]int{}
 	imports := map[string]*packages.Package{}
 	bestName := ""
 	bestNameCount := 0
 	for _, srcPath := range gnoFiles {
-		src, err := os.ReadFile(srcPath)
-		if err != nil {
-			return "", nil, fmt.Errorf("failed to read file %q: %w", srcPath, err)
+		sum, ok := files.Summaries[srcPath]
+		if !ok {
+			return "", nil, fmt.Errorf("unknown file %q", srcPath)
 		}
-
-		fset := token.NewFileSet()
-		f, err := parser.ParseFile(fset, srcPath, src, parser.SkipObjectResolution|parser.ImportsOnly)
-		if err != nil {
-			return "", nil, fmt.Errorf("parse: %w", err)
+		if sum.Err != "" {
+			return "", nil, errors.New(sum.Err)
 		}
 
-		name := f.Name.String()
+		name := sum.Name
 		names[name] += 1
 		count := names[name]
 		if count > bestNameCount {
@@ -96,11 +117,7 @@ func resolveNameAndImports(gnoFiles []string, logger *slog.Logger) (string, map[
 			bestNameCount = count
 		}
 
-		for _, imp := range f.Imports {
-			importPath := imp.Path.Value
-			if len(importPath) >= 2 {
-				importPath = importPath[1 : len(importPath)-1]
-			}
+		for _, importPath := range sum.Imports {
 			imports[importPath] = nil
 		}
 	}
@@ -109,51 +126,102 @@ func resolveNameAndImports(gnoFiles []string, logger *slog.Logger) (string, map[
 	return bestName, imports, nil
 }
 
+// ListPkgs returns the packages of the directory tree root, that is the
+// directories with a valid gno.mod file.
 func ListPkgs(root string) (gnomod.PkgList, error) {
-	var pkgs []gnomod.Pkg
+	return listPkgs(root, nil)
+}
 
-	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-		if !d.IsDir() {
-			return nil
+// listPkgs is like ListPkgs, but reads the gno.mod files through the
+// overlay ov, whose gno.mod files add packages to the tree even if their
+// directory doesn't exist on disk. The tree on disk is walked once, until
+// it is invalidated; see dirIndex.
+func listPkgs(root string, ov overlay) (gnomod.PkgList, error) {
+	root = filepath.Clean(root)
+	ovDirs := ov.gnoModDirs(root)
+
+	tree, err := index.walk(root)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) || len(ovDirs) == 0 {
+			return nil, err
 		}
-		gnoModPath := filepath.Join(path, "gno.mod")
-		data, err := os.ReadFile(gnoModPath)
-		if os.IsNotExist(err) {
-			return nil
+		tree = new(dirTree) // a tree of the overlay only
+	}
+
+	var pkgs []gnomod.Pkg
+	visited := make(map[string]bool)
+	for _, dir := range tree.dirs {
+		visited[dir] = true
+		if _, ok := slices.BinarySearch(ovDirs, dir); !ok {
+			if pkg, ok := tree.pkgs[dir]; ok {
+				pkgs = append(pkgs, pkg)
+			}
+			continue
 		}
+		pkg, ok, err := readPkg(dir, ov)
 		if err != nil {
-			return err
+			return nil, err
 		}
-
-		gnoMod, err := gnomod.Parse(gnoModPath, data)
+		if ok {
+			pkgs = append(pkgs, pkg)
+		}
+	}
+	for _, dir := range ovDirs {
+		if visited[dir] {
+			continue
+		}
+		pkg, ok, err := readPkg(dir, ov)
 		if err != nil {
-			return nil
+			return nil, err
 		}
-		gnoMod.Sanitize()
-		if err := gnoMod.Validate(); err != nil {
-			return nil
+		if ok {
+			pkgs = append(pkgs, pkg)
 		}
+	}
+
+	return pkgs, nil
+}
 
-		pkgs = append(pkgs, gnomod.Pkg{
-			Dir:   path,
-			Name:  gnoMod.Module.Mod.Path,
-			Draft: gnoMod.Draft,
-			Requires: func() []string {
-				var reqs []string
-				for _, req := range gnoMod.Require {
-					reqs = append(reqs, req.Mod.Path)
-				}
-				return reqs
-			}(),
-		})
-		return nil
-	})
+// readPkg returns the package of the directory dir, and whether it has
+// a gno.mod file, read through the overlay ov.
+func readPkg(dir string, ov overlay) (gnomod.Pkg, bool, error) {
+	gnoModPath := filepath.Join(dir, "gno.mod")
+	data, err := ov.readFile(gnoModPath)
+	if os.IsNotExist(err) {
+		return gnomod.Pkg{}, false, nil
+	}
 	if err != nil {
-		return nil, err
+		return gnomod.Pkg{}, false, err
 	}
 
-	return pkgs, nil
+	// A package whose gno.mod file is invalid is listed without a
+	// name: gnoPkgToGo reports the errors of its gno.mod file.
+	gnoMod, err := gnomod.Parse(gnoModPath, data)
+	if err != nil {
+		return gnomod.Pkg{Dir: dir}, true, nil
+	}
+	gnoMod.Sanitize()
+	if err := gnoMod.Validate(); err != nil {
+		return gnomod.Pkg{Dir: dir}, true, nil
+	}
+
+	var reqs []string
+	for _, req := range gnoMod.Require {
+		reqs = append(reqs, req.Mod.Path)
+	}
+	return gnomod.Pkg{
+		Dir:      dir,
+		Name:     gnoMod.Module.Mod.Path,
+		Draft:    gnoMod.Draft,
+		Requires: reqs,
+	}, true, nil
+}
+
+// parseGnoMod parses the gno.mod file path, read through the overlay ov.
+func parseGnoMod(path string, ov overlay) (*gnomod.File, error) {
+	data, err := ov.readFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return gnomod.Parse(path, data)
 }
@@ -0,0 +1,150 @@
+package resolver
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/gfanton/gnopls/internal/packages"
+	"github.com/gfanton/gnopls/internal/packagesinternal"
+)
+
+// testPackages returns the test packages of pkg, in the manner of
+// "go list -test":
+//
+//   - the in-package test variant "p [p.test]", made of the package
+//     files and its in-package _test.gno files;
+//   - the external test package "p_test [p.test]", made of the
+//     _test.gno files declaring package p_test;
+//   - one single-file main package per _filetest.gno file.
+//
+// The returned packages are not added to the import cache: nothing
+// but the external test package may import a test variant, and that
+// edge is resolved here.
+func testPackages(pkg *packages.Package, files *pkgFiles, logger *slog.Logger) ([]*packages.Package, error) {
+	var res []*packages.Package
+
+	inpkgFiles, xtestFiles, err := splitTestFiles(pkg.Name, files)
+	if err != nil {
+		return nil, fmt.Errorf("failed to split test files of %q: %w", pkg.PkgPath, err)
+	}
+
+	// The package under test, as seen by the external test package.
+	underTest := pkg
+
+	if len(inpkgFiles) > 0 {
+		gnoFiles := append(append([]string{}, pkg.GoFiles...), inpkgFiles...)
+		_, imports, err := resolveNameAndImports(files, gnoFiles, logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve name and imports: %w", err)
+		}
+
+		testPkg := &packages.Package{
+			ID:              fmt.Sprintf("%s [%s.test]", pkg.ID, pkg.PkgPath),
+			Name:            pkg.Name,
+			PkgPath:         pkg.PkgPath,
+			GoFiles:         gnoFiles,
+			OtherFiles:      pkg.OtherFiles,
+			CompiledGoFiles: gnoFiles,
+			Imports:         imports,
+		}
+		packagesinternal.SetForTest(testPkg, pkg.PkgPath)
+		res = append(res, testPkg)
+		underTest = testPkg
+	}
+
+	if len(xtestFiles) > 0 {
+		name, imports, err := resolveNameAndImports(files, xtestFiles, logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve name and imports: %w", err)
+		}
+		if _, ok := imports[pkg.PkgPath]; ok {
+			imports[pkg.PkgPath] = underTest
+		}
+
+		xtestPkg := &packages.Package{
+			ID:              fmt.Sprintf("%s_test [%s.test]", pkg.ID, pkg.PkgPath),
+			Name:            name,
+			PkgPath:         pkg.PkgPath + "_test",
+			GoFiles:         xtestFiles,
+			CompiledGoFiles: xtestFiles,
+			Imports:         imports,
+		}
+		packagesinternal.SetForTest(xtestPkg, pkg.PkgPath)
+		res = append(res, xtestPkg)
+	}
+
+	for _, fpath := range files.FiletestFiles {
+		filetestPkg, err := filetestPackage(files, fpath, logger)
+		if err != nil {
+			logger.Warn("failed to load filetest",
+				slog.String("path", fpath),
+				slog.String("error", err.Error()),
+			)
+			continue
+		}
+		res = append(res, filetestPkg)
+	}
+
+	return res, nil
+}
+
+// splitTestFiles separates in-package test files from the external
+// test files, which declare the package name suffixed by "_test".
+func splitTestFiles(pkgName string, files *pkgFiles) (inpkg, xtest []string, err error) {
+	for _, fpath := range files.TestFiles {
+		sum := files.Summaries[fpath]
+		if sum.Err != "" {
+			return nil, nil, errors.New(sum.Err)
+		}
+
+		name := sum.Name
+		if name != pkgName && strings.HasSuffix(name, "_test") {
+			xtest = append(xtest, fpath)
+		} else {
+			inpkg = append(inpkg, fpath)
+		}
+	}
+	return inpkg, xtest, nil
+}
+
+// filetestPackage returns the single-file package of a _filetest.gno
+// file. Its package path is taken from the PKGPATH directive of the
+// file, if any, and defaults to "main".
+func filetestPackage(files *pkgFiles, fpath string, logger *slog.Logger) (*packages.Package, error) {
+	name, imports, err := resolveNameAndImports(files, []string{fpath}, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve name and imports: %w", err)
+	}
+
+	pkgPath := files.Summaries[fpath].PkgPath
+	if pkgPath == "" {
+		pkgPath = "main"
+	}
+
+	return &packages.Package{
+		ID:              fpath,
+		Name:            name,
+		PkgPath:         pkgPath,
+		GoFiles:         []string{fpath},
+		CompiledGoFiles: []string{fpath},
+		Imports:         imports,
+	}, nil
+}
+
+// filetestPkgPath returns the value of the "// PKGPATH:" directive of
+// a filetest, or "" if there is none.
+func filetestPkgPath(src []byte) string {
+	const directive = "// PKGPATH:"
+	scanner := bufio.NewScanner(bytes.NewReader(src))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if rest, ok := strings.CutPrefix(line, directive); ok {
+			return strings.TrimSpace(rest)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,73 @@
+package resolver
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gfanton/gnopls/internal/packages"
+	"github.com/gfanton/gnopls/internal/packagesinternal"
+	"github.com/gfanton/gnopls/pkg/eventlogger"
+)
+
+func TestTestPackages(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"foo.gno":          "package foo\n\nimport \"strings\"\n",
+		"foo_test.gno":     "package foo\n\nimport \"testing\"\n",
+		"foo_ext_test.gno": "package foo_test\n\nimport (\n\t\"testing\"\n\t\"gno.land/p/demo/foo\"\n)\n",
+		"z_0_filetest.gno": "// PKGPATH: gno.land/r/demo/foo_test\npackage foo_test\n\nfunc main() {}\n\n// Output:\n// ok\n",
+		"z_1_filetest.gno": "package main\n\nfunc main() {}\n",
+		"README.md":        "# foo\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	logger := eventlogger.EventLoggerWrapper()
+	files, err := readPkgFiles(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	name, imports, err := resolveNameAndImports(files, files.GnoFiles, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg := &packages.Package{ID: dir, Name: name, PkgPath: "gno.land/p/demo/foo", GoFiles: files.GnoFiles, Imports: imports}
+
+	pkgs, err := testPackages(pkg, files, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type want struct {
+		id, name, pkgPath, forTest string
+		nfiles                     int
+	}
+	wants := []want{
+		{dir + " [gno.land/p/demo/foo.test]", "foo", "gno.land/p/demo/foo", "gno.land/p/demo/foo", 2},
+		{dir + "_test [gno.land/p/demo/foo.test]", "foo_test", "gno.land/p/demo/foo_test", "gno.land/p/demo/foo", 1},
+		{filepath.Join(dir, "z_0_filetest.gno"), "foo_test", "gno.land/r/demo/foo_test", "", 1},
+		{filepath.Join(dir, "z_1_filetest.gno"), "main", "main", "", 1},
+	}
+	if len(pkgs) != len(wants) {
+		t.Fatalf("got %d test packages, want %d", len(pkgs), len(wants))
+	}
+	for i, w := range wants {
+		got := pkgs[i]
+		if got.ID != w.id || got.Name != w.name || got.PkgPath != w.pkgPath || len(got.CompiledGoFiles) != w.nfiles {
+			t.Errorf("package %d = {%q %q %q %d}, want {%q %q %q %d}",
+				i, got.ID, got.Name, got.PkgPath, len(got.CompiledGoFiles),
+				w.id, w.name, w.pkgPath, w.nfiles)
+		}
+		if forTest := packagesinternal.GetForTest(got); forTest != w.forTest {
+			t.Errorf("package %d ForTest = %q, want %q", i, forTest, w.forTest)
+		}
+	}
+
+	// The external test package must import the in-package test variant.
+	if imp := pkgs[1].Imports["gno.land/p/demo/foo"]; imp != pkgs[0] {
+		t.Errorf("external test imports %v, want the test variant", imp)
+	}
+}